@@ -0,0 +1,229 @@
+// Command migrate manages the schema of the SQL database of the user store
+// and of the Mongo collections of the book store, configured like the rest
+// api app.
+//
+//	migrate up [n]        apply the pending migrations, at most n of them
+//	migrate down [n]      roll back the last n migrations, 1 by default
+//	migrate status        list every migration with its state
+//	migrate create NAME   add empty migration files for every dialect
+//	migrate mongo status  report the drift of the Mongo indexes and validators
+//	migrate mongo apply   re-key the books with legacy ids, create the missing
+//	                      Mongo indexes, set the validators
+package main
+
+import (
+	"alterra-agmc-day-7/config"
+	"alterra-agmc-day-7/internal/app"
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/migrations"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+const usage = `usage: migrate [-dir DIR] COMMAND
+
+commands:
+  up [N]        apply the pending migrations, at most N of them
+  down [N]      roll back the last N applied migrations, 1 by default
+  status        list every migration with its state
+  create NAME   add empty up and down files of a migration for every dialect
+  mongo status  report the drift of the Mongo indexes and validators
+  mongo apply   re-key the books with legacy ids, create the missing Mongo
+                indexes and set the validators
+`
+
+func main() {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := flags.String("dir", "internal/migrations/sql", "directory of the migrations, used by create")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := run(flags.Arg(0), flags.Args()[1:], *dir); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(command string, args []string, dir string) error {
+	if command == "create" {
+		if len(args) != 1 {
+			return errors.New("create needs the name of the migration")
+		}
+		paths, err := migrations.Create(dir, args[0])
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+		return err
+	}
+
+	if command == "mongo" {
+		if len(args) != 1 || (args[0] != "status" && args[0] != "apply") {
+			return errors.New("mongo needs status or apply")
+		}
+		return runMongo(args[0] == "apply")
+	}
+	if command != "up" && command != "down" && command != "status" {
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+	n, err := countArg(command, args)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	log := logger.New(os.Stderr, logger.ParseLevel(cfg.Log.Level))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	db, err := openDB(cfg, log)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	migrator, err := migrations.NewMigrator(db, migrations.Files, log)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, n)
+		for _, migration := range applied {
+			fmt.Println("applied", migration)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		rolledBack, err := migrator.Down(ctx, n)
+		for _, migration := range rolledBack {
+			fmt.Println("rolled back", migration)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+	}
+	return nil
+}
+
+// runMongo applies the declared Mongo schemas, or only reports their drift.
+// Unresolved drift is an error so scripts can detect it.
+func runMongo(apply bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	log := logger.New(os.Stderr, logger.ParseLevel(cfg.Log.Level))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.Mongo.URI))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+	db := client.Database(cfg.Mongo.Database)
+
+	var drifts []datasources.MongoSchemaDrift
+	if apply {
+		if err := datasources.MigrateBookMongoIDs(ctx, db, log); err != nil {
+			return fmt.Errorf("migrate book ids: %v", err)
+		}
+		drifts, err = datasources.ApplyMongoSchemas(ctx, db, datasources.MongoSchemas, nil)
+	} else {
+		drifts, err = datasources.CheckMongoSchemas(ctx, db, datasources.MongoSchemas)
+	}
+	if err != nil {
+		return err
+	}
+	unresolved := 0
+	for _, drift := range drifts {
+		fmt.Println(drift)
+		if !drift.Fixed {
+			unresolved++
+		}
+	}
+	if len(drifts) == 0 {
+		fmt.Println("no drift")
+	}
+	if unresolved > 0 {
+		return fmt.Errorf("%d drifts are left to resolve", unresolved)
+	}
+	return nil
+}
+
+// countArg returns the optional count of up and down: 0, meaning every
+// pending migration, for up and 1 for down. Status takes no argument.
+func countArg(command string, args []string) (int, error) {
+	switch {
+	case command == "status" && len(args) > 0:
+		return 0, errors.New("status takes no argument")
+	case len(args) > 1:
+		return 0, fmt.Errorf("%s takes at most one argument", command)
+	case len(args) == 0 && command == "down":
+		return 1, nil
+	case len(args) == 0:
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s should be given a positive number of migrations, got %q", command, args[0])
+	}
+	return n, nil
+}
+
+func openDB(cfg *config.Config, log *slog.Logger) (*gorm.DB, error) {
+	dialector, err := app.SQLDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(dialector, &gorm.Config{Logger: datasources.NewGormLogger(log)})
+}
+
+func printStatus(statuses []migrations.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	drift := false
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+		drift = drift || status.State == migrations.StateModified || status.State == migrations.StateMissing
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if drift {
+		return errors.New("the applied migrations drifted from the known ones, see the modified and missing ones")
+	}
+	return nil
+}
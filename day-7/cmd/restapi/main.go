@@ -1,12 +1,30 @@
 package main
 
 import (
+	"alterra-agmc-day-7/config"
 	"alterra-agmc-day-7/internal/app"
-	"log"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	if err := app.NewRestApiApp().Run(); err != nil {
-		log.Fatalf("Error Running Application: %v", err)
+	cfg, err := config.Load()
+	if err != nil {
+		logger.New(os.Stderr, slog.LevelInfo).Error("error loading configuration", "error", err)
+		os.Exit(1)
+	}
+	log := logger.New(os.Stdout, logger.ParseLevel(cfg.Log.Level))
+	slog.SetDefault(log)
+	log.Info("configuration loaded", "profile", cfg.Profile)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := app.NewRestApiApp(cfg, log).Run(ctx); err != nil {
+		log.Error("error running application", "error", err)
+		os.Exit(1)
 	}
 }
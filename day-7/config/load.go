@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Error lists every problem found while loading a Config.
+type Error struct {
+	Problems []string
+}
+
+func (e Error) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Load loads the configuration of the profile named by APP_PROFILE, dev by
+// default, from the file named by CONFIG_FILE, if any, and the environment.
+func Load() (*Config, error) {
+	return LoadFrom(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+}
+
+// LoadFrom loads a Config in layers: the defaults of the profile, the YAML or
+// TOML file at path, the file of the profile next to it (config.prod.yaml for
+// config.yaml) and finally the environment read with lookupEnv. The result is
+// validated and every problem is reported at once in an Error.
+func LoadFrom(path string, lookupEnv func(key string) (string, bool)) (*Config, error) {
+	profile := ProfileDev
+	if value, ok := lookupEnv("APP_PROFILE"); ok && value != "" {
+		profile = value
+	}
+	cfg := Default(profile)
+	var problems []string
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+		profilePath := profileFile(path, profile)
+		if _, err := os.Stat(profilePath); err == nil {
+			if err := decodeFile(profilePath, cfg); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	problems = append(problems, applyEnv(reflect.ValueOf(cfg).Elem(), lookupEnv)...)
+	problems = append(problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return nil, Error{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// profileFile returns the path of the file holding the settings of profile
+// overriding the ones of path.
+func profileFile(path string, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// decodeFile sets the fields of cfg found in the file at path, leaving the
+// others untouched. Unknown keys are rejected so typos do not go unnoticed.
+func decodeFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(content), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("%s: unsupported config file extension %q, use .yaml, .yml or .toml", path, ext)
+	}
+	return nil
+}
+
+// applyEnv overrides the fields of v having an env tag with the variables set
+// in the environment, returning the ones that could not be parsed.
+func applyEnv(v reflect.Value, lookupEnv func(key string) (string, bool)) []string {
+	var problems []string
+	for i := 0; i < v.NumField(); i++ {
+		field, fieldType := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			problems = append(problems, applyEnv(field, lookupEnv)...)
+			continue
+		}
+		key := fieldType.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value, ok := lookupEnv(key)
+		if !ok || value == "" {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s should be a number, got %q", key, value))
+				continue
+			}
+			field.SetInt(int64(n))
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s should be true or false, got %q", key, value))
+				continue
+			}
+			field.SetBool(b)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	profiles      = []string{ProfileDev, ProfileTest, ProfileProd}
+	logLevels     = []string{"debug", "info", "warn", "error"}
+	traceExporter = []string{"none", "stdout", "otlp", "memory"}
+	bookStores    = []string{StoreMemory, StoreMongo, StoreSQL}
+	userStores    = []string{StoreMemory, StoreMySQL, StorePostgres, StoreSQLite}
+	sqlUserStores = []string{StoreMySQL, StorePostgres, StoreSQLite}
+	caches        = []string{CacheNone, CacheMemory, CacheRedis}
+)
+
+// minJWTSecretKeyLength is the shortest secret accepted in prod, 256 bits for
+// the HS256 signatures of the tokens.
+const minJWTSecretKeyLength = 32
+
+// Validate reports every problem of cfg at once in an Error.
+func (cfg *Config) Validate() error {
+	if problems := cfg.problems(); len(problems) > 0 {
+		return Error{Problems: problems}
+	}
+	return nil
+}
+
+func (cfg *Config) problems() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(containsString(profiles, cfg.Profile), "APP_PROFILE should be one of %s, got %q", strings.Join(profiles, ", "), cfg.Profile)
+	check(validPort(cfg.App.Port), "app.port should be a port number, got %q", cfg.App.Port)
+	check(validPort(cfg.Metrics.Port), "metrics.port should be a port number, got %q", cfg.Metrics.Port)
+	check(cfg.App.Port != cfg.Metrics.Port, "app.port and metrics.port should differ, both are %q", cfg.App.Port)
+	check(containsString(logLevels, cfg.Log.Level), "log.level should be one of %s, got %q", strings.Join(logLevels, ", "), cfg.Log.Level)
+
+	check(cfg.JWT.SecretKey != "", "jwt.secret_key is required")
+	check(cfg.JWT.ExpirationTimeInMillis > 0, "jwt.expiration_time_in_millis should be positive, got %d", cfg.JWT.ExpirationTimeInMillis)
+
+	check(containsString(bookStores, cfg.Storage.BookStore), "storage.book_store should be one of %s, got %q", strings.Join(bookStores, ", "), cfg.Storage.BookStore)
+	check(containsString(userStores, cfg.Storage.UserStore), "storage.user_store should be one of %s, got %q", strings.Join(userStores, ", "), cfg.Storage.UserStore)
+	if cfg.Storage.BookStore == StoreSQL {
+		check(containsString(sqlUserStores, cfg.Storage.UserStore), "storage.book_store %s needs a SQL user store, one of %s, got %q", StoreSQL, strings.Join(sqlUserStores, ", "), cfg.Storage.UserStore)
+	}
+	// Only the settings of the backends in use matter.
+	var mysqlConfig *mysql.Config
+	if cfg.Storage.UserStore == StoreMySQL {
+		var err error
+		mysqlConfig, err = mysql.ParseDSN(cfg.MySQL.DSN)
+		check(err == nil, "mysql.dsn is invalid: %v", err)
+	}
+	var postgresURI *url.URL
+	if cfg.Storage.UserStore == StorePostgres {
+		uri, err := url.Parse(cfg.Postgres.DSN)
+		if err == nil && (uri.Scheme == "postgres" || uri.Scheme == "postgresql") {
+			postgresURI = uri
+		}
+		check(postgresURI != nil, "postgres.dsn should be a postgres:// URI")
+	}
+	if cfg.Storage.UserStore == StoreSQLite {
+		check(cfg.SQLite.DSN != "", "sqlite.dsn is required")
+	}
+	if cfg.Storage.BookStore == StoreMemory && cfg.Memory.DataDir != "" {
+		check(cfg.Memory.SnapshotIntervalInMinutes > 0, "memory.snapshot_interval_in_minutes should be positive, got %d", cfg.Memory.SnapshotIntervalInMinutes)
+	}
+	var mongoURI *url.URL
+	if cfg.Storage.BookStore == StoreMongo {
+		uri, err := url.Parse(cfg.Mongo.URI)
+		if err == nil && (uri.Scheme == "mongodb" || uri.Scheme == "mongodb+srv") {
+			mongoURI = uri
+		}
+		check(mongoURI != nil, "mongo.uri should be a mongodb:// or mongodb+srv:// URI")
+		check(cfg.Mongo.Database != "", "mongo.database is required")
+	}
+	check(containsString(caches, cfg.Cache.Backend), "cache.backend should be one of %s, got %q", strings.Join(caches, ", "), cfg.Cache.Backend)
+	if cfg.Cache.Backend != CacheNone {
+		check(cfg.Cache.TTLInSeconds > 0, "cache.ttl_in_seconds should be positive, got %d", cfg.Cache.TTLInSeconds)
+	}
+	if cfg.Cache.Backend == CacheMemory {
+		check(cfg.Cache.MaxEntries > 0, "cache.max_entries should be positive, got %d", cfg.Cache.MaxEntries)
+	}
+	if cfg.Cache.Backend == CacheRedis {
+		_, err := redis.ParseURL(cfg.Redis.URL)
+		check(err == nil, "redis.url is invalid: %v", err)
+	}
+
+	check(cfg.Trash.RetentionDays > 0, "trash.retention_days should be positive, got %d", cfg.Trash.RetentionDays)
+	check(cfg.Trash.PurgeIntervalInMinutes > 0, "trash.purge_interval_in_minutes should be positive, got %d", cfg.Trash.PurgeIntervalInMinutes)
+	check(cfg.Import.MaxSizeInMB > 0, "import.max_size_in_mb should be positive, got %d", cfg.Import.MaxSizeInMB)
+	check(cfg.Shutdown.TimeoutInSeconds > 0, "shutdown.timeout_in_seconds should be positive, got %d", cfg.Shutdown.TimeoutInSeconds)
+	check(cfg.Shutdown.DrainDelayInSeconds >= 0, "shutdown.drain_delay_in_seconds should not be negative, got %d", cfg.Shutdown.DrainDelayInSeconds)
+	check(cfg.Health.CheckTimeoutInMillis > 0, "health.check_timeout_in_millis should be positive, got %d", cfg.Health.CheckTimeoutInMillis)
+	check(containsString(traceExporter, cfg.Tracing.Exporter), "tracing.exporter should be one of %s, got %q", strings.Join(traceExporter, ", "), cfg.Tracing.Exporter)
+	check(cfg.Tracing.ServiceName != "", "tracing.service_name is required")
+
+	if cfg.Profile == ProfileProd {
+		check(cfg.JWT.SecretKey != DefaultJWTSecretKey, "jwt.secret_key should not be the built-in default in prod")
+		check(len(cfg.JWT.SecretKey) >= minJWTSecretKeyLength, "jwt.secret_key should be at least %d characters in prod", minJWTSecretKeyLength)
+		check(cfg.Storage.BookStore != StoreMemory || cfg.Memory.DataDir != "", "storage.book_store %s needs memory.data_dir in prod, its data would not survive a restart otherwise", StoreMemory)
+		check(cfg.Storage.UserStore != StoreMemory, "storage.user_store should not be %s in prod, its data would not survive a restart", StoreMemory)
+		if mysqlConfig != nil {
+			check(!defaultPassword(mysqlConfig.Passwd), "mysql.dsn should not use the built-in default credentials or an empty password in prod")
+		}
+		if postgresURI != nil {
+			password, _ := postgresURI.User.Password()
+			check(!defaultPassword(password), "postgres.dsn should not use the built-in default credentials or an empty password in prod")
+		}
+		if mongoURI != nil && mongoURI.User != nil {
+			password, _ := mongoURI.User.Password()
+			check(!defaultPassword(password), "mongo.uri should not use the built-in default credentials or an empty password in prod")
+		}
+	}
+	return problems
+}
+
+// defaultPassword tells whether password is the one of the built-in DSNs and
+// URIs, or no password at all.
+func defaultPassword(password string) bool {
+	return password == "" || password == defaultDBPassword
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"alterra-agmc-day-7/config"
+	"alterra-agmc-day-7/internal/cache"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix starts the keys of the app in Redis, which may be shared
+// with other apps.
+const redisKeyPrefix = "bookstore:"
+
+// cachedBooks caches the books found by id in books in the backend chosen by
+// CACHE_BACKEND, if any.
+func (a *restApiApp) cachedBooks(ctx context.Context, books repositories.BookRepository) (repositories.BookRepository, error) {
+	var backend cache.Backend
+	switch name := a.config.Cache.Backend; name {
+	case config.CacheMemory:
+		backend = cache.NewMemory(a.config.Cache.MaxEntries)
+	case config.CacheRedis:
+		client, err := a.openRedis(ctx)
+		if err != nil {
+			return nil, err
+		}
+		backend = cache.NewRedis(client, redisKeyPrefix)
+	default:
+		return books, nil
+	}
+	cached := cache.NewBookRepository(books, backend, a.config.Cache.TTL(), a.logger)
+	if err := a.metrics.RegisterCacheStats(cached.Stats(), "book"); err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// openRedis connects to the Redis server and disconnects on shutdown. The
+// app still serves books while it is down, so it is not checked for
+// readiness.
+func (a *restApiApp) openRedis(ctx context.Context) (*redis.Client, error) {
+	options, err := redis.ParseURL(a.config.Redis.URL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(options)
+	a.shutdownHooks.Add(config.CacheRedis, func(ctx context.Context) error {
+		return client.Close()
+	})
+	a.logger.InfoContext(ctx, "cache connected", "backend", config.CacheRedis)
+	return client, nil
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"alterra-agmc-day-7/config"
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/migrations"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/internal/storage"
+	"alterra-agmc-day-7/internal/transportlayers/http/handlers"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/glebarez/sqlite"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// bookStore holds the repositories kept in the backend chosen by BOOK_STORE.
+type bookStore struct {
+	datasource string
+	books      repositories.BookRepository
+	categories repositories.CategoryRepository
+	revisions  repositories.BookRevisionRepository
+}
+
+// userStore holds the repository kept in the backend chosen by USER_STORE.
+type userStore struct {
+	datasource string
+	users      repositories.UserRepository
+}
+
+func (a *restApiApp) bookStores() *storage.Registry[bookStore] {
+	registry := storage.NewRegistry[bookStore]("book")
+	registry.Register(config.StoreMemory, func(ctx context.Context) (bookStore, error) {
+		books, err := a.memoryBooks(ctx)
+		if err != nil {
+			return bookStore{}, err
+		}
+		return bookStore{
+			datasource: config.StoreMemory,
+			books:      books,
+			categories: datasources.NewCategoryInMemoryDataSource(),
+			revisions:  datasources.NewBookRevisionInMemoryDataSource(),
+		}, nil
+	})
+	registry.Register(config.StoreMongo, func(ctx context.Context) (bookStore, error) {
+		db, err := a.openMongo(ctx)
+		if err != nil {
+			return bookStore{}, err
+		}
+		if a.config.Migrate.OnBoot {
+			// Ids are migrated first, the validator refuses documents without one.
+			if err := datasources.MigrateBookMongoIDs(ctx, db, a.logger); err != nil {
+				return bookStore{}, fmt.Errorf("migrate book ids: %v", err)
+			}
+			if _, err := datasources.ApplyMongoSchemas(ctx, db, datasources.MongoSchemas, a.logger); err != nil {
+				return bookStore{}, fmt.Errorf("apply mongo schemas: %v", err)
+			}
+		} else {
+			// A missing index only slows queries down, so drift is reported but
+			// does not stop the app.
+			drifts, err := datasources.CheckMongoSchemas(ctx, db, datasources.MongoSchemas)
+			if err != nil {
+				return bookStore{}, fmt.Errorf("check mongo schemas: %v", err)
+			}
+			for _, drift := range drifts {
+				a.logger.WarnContext(ctx, "mongo schema drift, run cmd/migrate mongo apply", "drift", drift.String())
+			}
+			legacy, err := datasources.HasLegacyBookMongoIDs(ctx, db)
+			if err != nil {
+				return bookStore{}, fmt.Errorf("check book ids: %v", err)
+			}
+			if legacy {
+				a.logger.WarnContext(ctx, "books with legacy ids cannot be found, run cmd/migrate mongo apply")
+			}
+		}
+		return bookStore{
+			datasource: config.StoreMongo,
+			books:      datasources.NewBookMongoDataSource(db),
+			categories: datasources.NewCategoryMongoDataSource(db),
+			revisions:  datasources.NewBookRevisionMongoDataSource(db),
+		}, nil
+	})
+	registry.Register(config.StoreSQL, func(ctx context.Context) (bookStore, error) {
+		db, err := a.sqlDatabase(ctx)
+		if err != nil {
+			return bookStore{}, err
+		}
+		return bookStore{
+			datasource: a.config.Storage.UserStore,
+			books:      datasources.NewBookGormDataSource(db),
+			categories: datasources.NewCategoryGormDataSource(db),
+			revisions:  datasources.NewBookRevisionGormDataSource(db),
+		}, nil
+	})
+	return registry
+}
+
+// memoryBooks returns the books of the memory store, persisted in the data
+// directory when one is set: they are snapshotted on every interval, and once
+// more on shutdown.
+func (a *restApiApp) memoryBooks(ctx context.Context) (repositories.BookRepository, error) {
+	dir := a.config.Memory.DataDir
+	if dir == "" {
+		return datasources.NewBookInMemoryDataSource(), nil
+	}
+	books, err := datasources.OpenBookInMemoryDataSource(dir, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	snapshotsCtx, cancelSnapshots := context.WithCancel(context.Background())
+	var snapshots sync.WaitGroup
+	snapshots.Add(1)
+	go func() {
+		defer snapshots.Done()
+		books.RunSnapshots(snapshotsCtx, a.config.Memory.SnapshotInterval())
+	}()
+	a.shutdownHooks.Add(config.StoreMemory, func(ctx context.Context) error {
+		cancelSnapshots()
+		snapshots.Wait()
+		return books.Close()
+	})
+	a.logger.InfoContext(ctx, "books loaded", "datasource", config.StoreMemory, "dir", dir)
+	return books, nil
+}
+
+func (a *restApiApp) userStores() *storage.Registry[userStore] {
+	registry := storage.NewRegistry[userStore]("user")
+	registry.Register(config.StoreMemory, func(ctx context.Context) (userStore, error) {
+		return userStore{datasource: config.StoreMemory, users: datasources.NewUserInMemoryDataSource()}, nil
+	})
+	gormUserStore := func(name string) storage.Factory[userStore] {
+		return func(ctx context.Context) (userStore, error) {
+			db, err := a.sqlDatabase(ctx)
+			if err != nil {
+				return userStore{}, err
+			}
+			return userStore{datasource: name, users: datasources.NewUserGormDataSource(db)}, nil
+		}
+	}
+	registry.Register(config.StoreMySQL, gormUserStore(config.StoreMySQL))
+	registry.Register(config.StorePostgres, gormUserStore(config.StorePostgres))
+	registry.Register(config.StoreSQLite, gormUserStore(config.StoreSQLite))
+	return registry
+}
+
+// SQLDialector returns the dialector of the SQL database of the user store
+// of cfg.
+func SQLDialector(cfg *config.Config) (gorm.Dialector, error) {
+	switch name := cfg.Storage.UserStore; name {
+	case config.StoreMySQL:
+		return mysql.Open(cfg.MySQL.DSN), nil
+	case config.StorePostgres:
+		return postgres.Open(cfg.Postgres.DSN), nil
+	case config.StoreSQLite:
+		return sqlite.Open(cfg.SQLite.DSN), nil
+	default:
+		return nil, fmt.Errorf("user store %s is not a SQL database", name)
+	}
+}
+
+// Migrate brings the schema of db up to date, applying the pending
+// migrations.
+func Migrate(ctx context.Context, db *gorm.DB, logger *slog.Logger) ([]migrations.Migration, error) {
+	migrator, err := migrations.NewMigrator(db, migrations.Files, logger)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Up(ctx, 0)
+}
+
+// sqlDatabase returns the SQL database of the user store, opened and migrated
+// on first use. The sql book store shares it so books can reference their
+// owners.
+func (a *restApiApp) sqlDatabase(ctx context.Context) (*gorm.DB, error) {
+	if a.sqlDB != nil {
+		return a.sqlDB, nil
+	}
+	dialector, err := SQLDialector(a.config)
+	if err != nil {
+		return nil, err
+	}
+	db, err := a.openGormDB(ctx, a.config.Storage.UserStore, dialector)
+	if err != nil {
+		return nil, err
+	}
+	if a.config.Migrate.OnBoot {
+		if _, err := Migrate(ctx, db, a.logger); err != nil {
+			return nil, err
+		}
+	} else {
+		migrator, err := migrations.NewMigrator(db, migrations.Files, a.logger)
+		if err != nil {
+			return nil, err
+		}
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) > 0 {
+			return nil, fmt.Errorf("%d migrations are pending, starting with %s, run cmd/migrate up", len(pending), pending[0])
+		}
+	}
+	a.sqlDB = db
+	return db, nil
+}
+
+// openGormDB connects to the SQL database of dialector, named name in logs,
+// metrics and health checks, and closes it on shutdown.
+func (a *restApiApp) openGormDB(ctx context.Context, name string, dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: datasources.NewGormLogger(a.logger)})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	a.shutdownHooks.Add(name, func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
+	a.logger.InfoContext(ctx, "database connected", "datasource", name)
+	if err := a.metrics.RegisterDBStats(sqlDB, name); err != nil {
+		return nil, err
+	}
+	if err := db.Use(a.tracing.GormPlugin()); err != nil {
+		return nil, err
+	}
+	a.healthChecks = append(a.healthChecks, handlers.HealthCheck{
+		Name:    name,
+		Timeout: a.config.Health.CheckTimeout(),
+		Ping:    sqlDB.PingContext,
+	})
+	return db, nil
+}
+
+// openMongo connects to the Mongo database and disconnects on shutdown.
+func (a *restApiApp) openMongo(ctx context.Context) (*mongo.Database, error) {
+	clientOptions := options.Client()
+	clientOptions.ApplyURI(a.config.Mongo.URI)
+	clientOptions.SetPoolMonitor(a.metrics.MongoPoolMonitor())
+	clientOptions.SetMonitor(a.tracing.MongoCommandMonitor())
+	client, err := mongo.NewClient(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	a.shutdownHooks.Add(config.StoreMongo, client.Disconnect)
+	a.logger.InfoContext(ctx, "database connected", "datasource", config.StoreMongo)
+	a.healthChecks = append(a.healthChecks, handlers.HealthCheck{
+		Name:    config.StoreMongo,
+		Timeout: a.config.Health.CheckTimeout(),
+		Ping: func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		},
+	})
+	return client.Database(a.config.Mongo.Database), nil
+}
@@ -0,0 +1,114 @@
+package cache_test
+
+import (
+	"alterra-agmc-day-7/internal/cache"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// backends are the implementations every backend test runs against, each
+// empty. expire makes the entries set for ttl expire.
+var backends = []struct {
+	name string
+	new  func(t *testing.T) (backend cache.Backend, expire func(ttl time.Duration))
+}{
+	{
+		name: "memory",
+		new: func(t *testing.T) (cache.Backend, func(time.Duration)) {
+			return cache.NewMemory(100), func(ttl time.Duration) { time.Sleep(ttl) }
+		},
+	},
+	{
+		name: "redis",
+		new: func(t *testing.T) (cache.Backend, func(time.Duration)) {
+			server, client := newTestRedis(t)
+			return cache.NewRedis(client, "test:"), server.FastForward
+		},
+	},
+}
+
+// newTestRedis starts a Redis stand-in for the test and connects to it.
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+func eachBackend(t *testing.T, name string, test func(t *testing.T, backend cache.Backend, expire func(ttl time.Duration))) {
+	for _, backend := range backends {
+		newBackend := backend.new
+		t.Run(backend.name+"/"+name, func(t *testing.T) {
+			backend, expire := newBackend(t)
+			test(t, backend, expire)
+		})
+	}
+}
+
+func TestBackendGetSetDelete(t *testing.T) {
+	eachBackend(t, "Test entries should be found until deleted", func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+		// Setup
+		ctx := context.TODO()
+		assert.NoError(t, backend.Set(ctx, "a", []byte("1"), time.Minute))
+		assert.NoError(t, backend.Set(ctx, "b", []byte("2"), time.Minute))
+		assert.NoError(t, backend.Set(ctx, "a", []byte("3"), time.Minute))
+
+		// Act
+		a, aFound, aErr := backend.Get(ctx, "a")
+		deleteErr := backend.Delete(ctx, "b", "missing")
+		_, bFound, bErr := backend.Get(ctx, "b")
+
+		// Assert
+		assert.NoError(t, aErr)
+		assert.True(t, aFound)
+		assert.Equal(t, []byte("3"), a)
+		assert.NoError(t, deleteErr)
+		assert.NoError(t, bErr)
+		assert.False(t, bFound)
+	})
+	eachBackend(t, "Test entries should expire with their ttl", func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+		// Setup
+		ctx := context.TODO()
+		assert.NoError(t, backend.Set(ctx, "short", []byte("1"), 20*time.Millisecond))
+		assert.NoError(t, backend.Set(ctx, "long", []byte("2"), time.Minute))
+
+		// Act
+		expire(30 * time.Millisecond)
+		_, shortFound, shortErr := backend.Get(ctx, "short")
+		_, longFound, longErr := backend.Get(ctx, "long")
+
+		// Assert
+		assert.NoError(t, shortErr)
+		assert.False(t, shortFound)
+		assert.NoError(t, longErr)
+		assert.True(t, longFound)
+	})
+}
+
+func TestMemoryEviction(t *testing.T) {
+	// Setup
+	ctx := context.TODO()
+	memory := cache.NewMemory(3)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, memory.Set(ctx, fmt.Sprint(i), []byte{byte(i)}, time.Minute))
+	}
+
+	// Act
+	// 1 is used, leaving 2 as the least recently used entry.
+	_, _, _ = memory.Get(ctx, "1")
+	assert.NoError(t, memory.Set(ctx, "4", []byte{4}, time.Minute))
+
+	// Assert
+	assert.Equal(t, 3, memory.Len())
+	for key, expected := range map[string]bool{"1": true, "2": false, "3": true, "4": true} {
+		_, found, err := memory.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, found, key)
+	}
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// BookRepository serves FindByID from a Backend, reading through to the
+// next repository on a miss. Concurrent misses of a book share a single read.
+// Books are dropped from the cache when they are changed through it; changes
+// made elsewhere, by another instance with the memory backend for example,
+// show once the entry expires.
+type BookRepository struct {
+	next    repositories.BookRepository
+	backend Backend
+	ttl     time.Duration
+	stats   Stats
+	loads   singleflight.Group
+	// generation is bumped on every invalidation, a read started before it
+	// does not store what it found.
+	generation atomic.Uint64
+	logger     *slog.Logger
+}
+
+// Stats returns the lookup counters of the cache.
+func (r *BookRepository) Stats() *Stats {
+	return &r.stats
+}
+
+// FindByID implements repositories.BookRepository
+func (r *BookRepository) FindByID(ctx context.Context, id uint) (*models.Book, error) {
+	key := bookKey(id)
+	value, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		r.stats.errors.Add(1)
+		r.logger.WarnContext(ctx, "book cache read failed", "key", key, "error", err)
+	}
+	if ok {
+		var book models.Book
+		if err := json.Unmarshal(value, &book); err == nil {
+			r.stats.hits.Add(1)
+			return &book, nil
+		}
+		r.stats.errors.Add(1)
+		r.logger.WarnContext(ctx, "book cache entry is unreadable", "key", key, "error", err)
+	}
+	r.stats.misses.Add(1)
+
+	// The read goes on for the callers still waiting when ctx is done.
+	loads := r.loads.DoChan(key, func() (interface{}, error) {
+		return r.load(context.WithoutCancel(ctx), key, id)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-loads:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		var book models.Book
+		if err := json.Unmarshal(result.Val.([]byte), &book); err != nil {
+			return nil, err
+		}
+		return &book, nil
+	}
+}
+
+// load reads the book of id from the next repository and caches it, unless
+// it was invalidated meanwhile. Every waiting caller decodes its own copy.
+func (r *BookRepository) load(ctx context.Context, key string, id uint) ([]byte, error) {
+	generation := r.generation.Load()
+	book, err := r.next.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(book)
+	if err != nil {
+		return nil, err
+	}
+	if r.generation.Load() != generation {
+		return value, nil
+	}
+	if err := r.backend.Set(ctx, key, value, r.ttl); err != nil {
+		r.stats.errors.Add(1)
+		r.logger.WarnContext(ctx, "book cache write failed", "key", key, "error", err)
+		return value, nil
+	}
+	// Undo the write should an invalidation have slipped in before it.
+	if r.generation.Load() != generation {
+		_ = r.backend.Delete(ctx, key)
+	}
+	return value, nil
+}
+
+// invalidate drops the book of id from the cache. A failure is only logged:
+// the change itself succeeded, the entry expires with its TTL.
+func (r *BookRepository) invalidate(ctx context.Context, id uint) {
+	ctx = context.WithoutCancel(ctx)
+	key := bookKey(id)
+	r.generation.Add(1)
+	r.loads.Forget(key)
+	if err := r.backend.Delete(ctx, key); err != nil {
+		r.stats.errors.Add(1)
+		r.logger.WarnContext(ctx, "book cache invalidation failed", "key", key, "error", err)
+	}
+}
+
+// Create implements repositories.BookRepository
+func (r *BookRepository) Create(ctx context.Context, book *models.Book) (*models.Book, error) {
+	created, err := r.next.Create(ctx, book)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, created.ID)
+	return created, nil
+}
+
+// Update implements repositories.BookRepository
+func (r *BookRepository) Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	// A failed update, a version conflict for one, hints that the cached
+	// book is stale too.
+	defer r.invalidate(ctx, book.ID)
+	return r.next.Update(ctx, book, fields)
+}
+
+// DeleteByID implements repositories.BookRepository
+func (r *BookRepository) DeleteByID(ctx context.Context, id uint, version uint) error {
+	defer r.invalidate(ctx, id)
+	return r.next.DeleteByID(ctx, id, version)
+}
+
+// Restore implements repositories.BookRepository
+func (r *BookRepository) Restore(ctx context.Context, id uint) (*models.Book, error) {
+	defer r.invalidate(ctx, id)
+	return r.next.Restore(ctx, id)
+}
+
+// FindAll implements repositories.BookRepository
+func (r *BookRepository) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	return r.next.FindAll(ctx, filter)
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (r *BookRepository) ExistsByIsbn(ctx context.Context, isbn string) (bool, error) {
+	return r.next.ExistsByIsbn(ctx, isbn)
+}
+
+// Iterate implements repositories.BookRepository
+func (r *BookRepository) Iterate(ctx context.Context, filter models.BookFilter) (repositories.BookIterator, error) {
+	return r.next.Iterate(ctx, filter)
+}
+
+// FindDeleted implements repositories.BookRepository
+func (r *BookRepository) FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error) {
+	return r.next.FindDeleted(ctx, userID)
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (r *BookRepository) FindDeletedByID(ctx context.Context, id uint) (*models.Book, error) {
+	return r.next.FindDeletedByID(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository. Deleted books
+// are not cached, there is nothing to invalidate.
+func (r *BookRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return r.next.PurgeDeletedBefore(ctx, before)
+}
+
+func bookKey(id uint) string {
+	return fmt.Sprintf("book:%d", id)
+}
+
+// NewBookRepository caches the books found by id in next in backend for ttl.
+func NewBookRepository(next repositories.BookRepository, backend Backend, ttl time.Duration, logger *slog.Logger) *BookRepository {
+	return &BookRepository{next: next, backend: backend, ttl: ttl, logger: logger}
+}
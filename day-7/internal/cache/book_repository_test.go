@@ -0,0 +1,182 @@
+package cache_test
+
+import (
+	"alterra-agmc-day-7/internal/cache"
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingBookRepository counts the reads by id reaching the repository, and
+// holds them until release is closed when it is set.
+type countingBookRepository struct {
+	repositories.BookRepository
+	finds   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingBookRepository) FindByID(ctx context.Context, id uint) (*models.Book, error) {
+	r.finds.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.BookRepository.FindByID(ctx, id)
+}
+
+func newTestBookRepository(t *testing.T, backend cache.Backend) (*cache.BookRepository, *countingBookRepository, *models.Book) {
+	next := &countingBookRepository{BookRepository: datasources.NewBookInMemoryDataSource()}
+	repository := cache.NewBookRepository(next, backend, time.Minute, logger.Discard())
+	book, err := repository.Create(context.TODO(), &models.Book{Title: "Test Book", Isbn: "1", UserID: 12, Tags: []string{"go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository, next, book
+}
+
+func TestBookRepositoryFindByID(t *testing.T) {
+	eachBackend(t, "Test books should be read through once then served from the cache", func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+		// Setup
+		repository, next, book := newTestBookRepository(t, backend)
+
+		// Act
+		first, firstErr := repository.FindByID(context.TODO(), book.ID)
+		first.Title = "changed"
+		second, secondErr := repository.FindByID(context.TODO(), book.ID)
+
+		// Assert
+		assert.NoError(t, firstErr)
+		assert.NoError(t, secondErr)
+		assert.Equal(t, "Test Book", second.Title)
+		assert.Equal(t, []string{"go"}, second.Tags)
+		assert.Equal(t, book.CreatedAt.UnixNano(), second.CreatedAt.UnixNano())
+		assert.Equal(t, int32(1), next.finds.Load())
+		assert.Equal(t, uint64(1), repository.Stats().Hits())
+		assert.Equal(t, uint64(1), repository.Stats().Misses())
+	})
+	eachBackend(t, "Test missing books should not be cached", func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+		// Setup
+		repository, next, book := newTestBookRepository(t, backend)
+
+		// Act
+		_, firstErr := repository.FindByID(context.TODO(), book.ID+1)
+		_, secondErr := repository.FindByID(context.TODO(), book.ID+1)
+
+		// Assert
+		assert.ErrorIs(t, firstErr, repositories.ErrRecordNotFound{})
+		assert.ErrorIs(t, secondErr, repositories.ErrRecordNotFound{})
+		assert.Equal(t, int32(2), next.finds.Load())
+	})
+	eachBackend(t, "Test concurrent misses should share a single read", func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+		// Setup
+		repository, next, book := newTestBookRepository(t, backend)
+		next.release = make(chan struct{})
+		const readers = 10
+
+		// Act
+		var wg sync.WaitGroup
+		titles := make([]string, readers)
+		for i := 0; i < readers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if found, err := repository.FindByID(context.TODO(), book.ID); err == nil {
+					titles[i] = found.Title
+				}
+			}(i)
+		}
+		// Every reader has missed before the read is let through.
+		for repository.Stats().Misses() < readers {
+			time.Sleep(time.Millisecond)
+		}
+		close(next.release)
+		wg.Wait()
+
+		// Assert
+		assert.Equal(t, int32(1), next.finds.Load())
+		for _, title := range titles {
+			assert.Equal(t, "Test Book", title)
+		}
+	})
+}
+
+func TestBookRepositoryInvalidation(t *testing.T) {
+	testCases := []struct {
+		name string
+		act  func(t *testing.T, repository repositories.BookRepository, book *models.Book)
+		// expectedTitle is the title found afterwards, none when the book is
+		// no longer found.
+		expectedTitle string
+	}{
+		{
+			name: "Test an updated book should be read again",
+			act: func(t *testing.T, repository repositories.BookRepository, book *models.Book) {
+				_, err := repository.Update(context.TODO(), &models.Book{ID: book.ID, Title: "Updated", Version: book.Version}, []string{models.BookFieldTitle})
+				assert.NoError(t, err)
+			},
+			expectedTitle: "Updated",
+		},
+		{
+			name: "Test a deleted book should not be found",
+			act: func(t *testing.T, repository repositories.BookRepository, book *models.Book) {
+				assert.NoError(t, repository.DeleteByID(context.TODO(), book.ID, book.Version))
+			},
+		},
+		{
+			name: "Test a conflicting update should drop the cached book",
+			act: func(t *testing.T, repository repositories.BookRepository, book *models.Book) {
+				_, err := repository.Update(context.TODO(), &models.Book{ID: book.ID, Title: "Stale", Version: book.Version + 1}, []string{models.BookFieldTitle})
+				assert.ErrorIs(t, err, repositories.ErrVersionConflict{})
+			},
+			expectedTitle: "Test Book",
+		},
+	}
+
+	for _, testCase := range testCases {
+		eachBackend(t, testCase.name, func(t *testing.T, backend cache.Backend, expire func(time.Duration)) {
+			// Setup
+			repository, next, book := newTestBookRepository(t, backend)
+			if _, err := repository.FindByID(context.TODO(), book.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			testCase.act(t, repository, book)
+			found, err := repository.FindByID(context.TODO(), book.ID)
+
+			// Assert
+			assert.Equal(t, int32(2), next.finds.Load())
+			if testCase.expectedTitle == "" {
+				assert.ErrorIs(t, err, repositories.ErrRecordNotFound{})
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedTitle, found.Title)
+		})
+	}
+}
+
+func TestBookRepositoryBackendDown(t *testing.T) {
+	// Setup
+	server, client := newTestRedis(t)
+	repository, next, book := newTestBookRepository(t, cache.NewRedis(client, "test:"))
+	server.Close()
+
+	// Act
+	found, err := repository.FindByID(context.TODO(), book.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Book", found.Title)
+	assert.Equal(t, int32(1), next.finds.Load())
+	assert.Equal(t, uint64(1), repository.Stats().Misses())
+	// Both the read and the write of the entry failed.
+	assert.Equal(t, uint64(2), repository.Stats().Errors())
+}
@@ -0,0 +1,41 @@
+// Package cache keeps the results of hot repository reads in a backend
+// shared by the decorators of the repositories: in the process, or in Redis
+// when several instances of the app should share it.
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Backend stores encoded values by key, each for a limited time. A missing
+// or expired key is not an error. Values handed out must not be modified.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats counts the lookups of a cache. Lookups failing in the backend are
+// counted as errors as well as misses, the repository serves them.
+type Stats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// Hits returns how many lookups were served by the cache.
+func (s *Stats) Hits() uint64 {
+	return s.hits.Load()
+}
+
+// Misses returns how many lookups went to the repository.
+func (s *Stats) Misses() uint64 {
+	return s.misses.Load()
+}
+
+// Errors returns how many reads and writes failed in the backend.
+func (s *Stats) Errors() uint64 {
+	return s.errors.Load()
+}
@@ -0,0 +1,374 @@
+package datasources
+
+import (
+	gormModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// bookGormIterateBatchSize is the number of books Iterate loads at once.
+const bookGormIterateBatchSize = 500
+
+type BookGormDataSource struct {
+	db *gorm.DB
+}
+
+// Create implements repositories.BookRepository
+func (ds *BookGormDataSource) Create(ctx context.Context, book *models.Book) (*models.Book, error) {
+	utcNow := time.Now().UTC()
+	bookData := &gormModels.BookGormModel{
+		Title:      book.Title,
+		Isbn:       book.Isbn,
+		Writer:     book.Writer,
+		CategoryID: book.CategoryID,
+		Tags:       bookTagGormModels(0, book.Tags),
+		UserID:     book.UserID,
+		CreatedAt:  utcNow,
+		UpdatedAt:  utcNow,
+		Version:    1,
+	}
+	if err := ds.db.WithContext(ctx).Omit("User").Create(bookData).Error; err != nil {
+		return nil, err
+	}
+	book.ID = bookData.ID
+	book.CreatedAt = bookData.CreatedAt
+	book.UpdatedAt = bookData.UpdatedAt
+	book.Version = bookData.Version
+	return book, nil
+}
+
+// DeleteByID implements repositories.BookRepository
+func (ds *BookGormDataSource) DeleteByID(ctx context.Context, id uint, version uint) error {
+	res := ds.db.WithContext(ctx).
+		Model(&gormModels.BookGormModel{}).
+		Where("id = ? AND version = ?", id, version).
+		Update("deleted_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if _, err := ds.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return repositories.ErrVersionConflict{}
+	}
+	return nil
+}
+
+// FindAll implements repositories.BookRepository
+func (ds *BookGormDataSource) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	var bookData []gormModels.BookGormModel
+	if err := ds.filtered(ctx, filter).Order("id").Find(&bookData).Error; err != nil {
+		return make([]*models.Book, 0), err
+	}
+	return booksFromGormModels(bookData), nil
+}
+
+// Iterate implements repositories.BookRepository
+func (ds *BookGormDataSource) Iterate(ctx context.Context, filter models.BookFilter) (repositories.BookIterator, error) {
+	return &bookGormIterator{ds: ds, filter: filter, index: -1}, nil
+}
+
+// FindByID implements repositories.BookRepository
+func (ds *BookGormDataSource) FindByID(ctx context.Context, id uint) (*models.Book, error) {
+	return ds.findOne(ds.withTags(ctx).Where("id = ?", id))
+}
+
+// FindDeleted implements repositories.BookRepository
+func (ds *BookGormDataSource) FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error) {
+	var bookData []gormModels.BookGormModel
+	err := ds.withTags(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("id").
+		Find(&bookData).Error
+	if err != nil {
+		return make([]*models.Book, 0), err
+	}
+	return booksFromGormModels(bookData), nil
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (ds *BookGormDataSource) FindDeletedByID(ctx context.Context, id uint) (*models.Book, error) {
+	return ds.findOne(ds.withTags(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id))
+}
+
+// Restore implements repositories.BookRepository
+func (ds *BookGormDataSource) Restore(ctx context.Context, id uint) (*models.Book, error) {
+	res := ds.db.WithContext(ctx).Unscoped().
+		Model(&gormModels.BookGormModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	return ds.FindByID(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository
+func (ds *BookGormDataSource) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := ds.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().
+			Model(&gormModels.BookGormModel{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		// Not every database enforces the cascade of book_tags, e.g. SQLite
+		// without its foreign_keys pragma, so the tags are removed first.
+		if err := tx.Where("book_id IN (?)", expired).Delete(&gormModels.BookTagGormModel{}).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Delete(&gormModels.BookGormModel{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (ds *BookGormDataSource) ExistsByIsbn(ctx context.Context, isbn string) (bool, error) {
+	var count int64
+	err := ds.db.WithContext(ctx).
+		Model(&gormModels.BookGormModel{}).
+		Where("isbn = ?", isbn).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update implements repositories.BookRepository
+func (ds *BookGormDataSource) Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	values := map[string]interface{}{
+		"updated_at": time.Now().UTC(),
+		"version":    gorm.Expr("version + 1"),
+	}
+	updateTags := false
+	for _, field := range fields {
+		switch field {
+		case models.BookFieldTitle:
+			values["title"] = book.Title
+		case models.BookFieldIsbn:
+			values["isbn"] = book.Isbn
+		case models.BookFieldWriter:
+			values["writer"] = book.Writer
+		case models.BookFieldCategoryID:
+			values["category_id"] = book.CategoryID
+		case models.BookFieldTags:
+			updateTags = true
+		default:
+			return nil, fmt.Errorf("unknown book field %q", field)
+		}
+	}
+	err := ds.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&gormModels.BookGormModel{}).
+			Where("id = ? AND version = ?", book.ID, book.Version).
+			Updates(values)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&gormModels.BookGormModel{}).Where("id = ?", book.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return repositories.ErrRecordNotFound{}
+			}
+			return repositories.ErrVersionConflict{}
+		}
+		if !updateTags {
+			return nil
+		}
+		if err := tx.Where("book_id = ?", book.ID).Delete(&gormModels.BookTagGormModel{}).Error; err != nil {
+			return err
+		}
+		if tags := bookTagGormModels(book.ID, book.Tags); len(tags) > 0 {
+			return tx.Create(&tags).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds.FindByID(ctx, book.ID)
+}
+
+// withTags starts a query on books loading their tags in order. Soft deleted
+// books are left out unless the query is made Unscoped.
+func (ds *BookGormDataSource) withTags(ctx context.Context) *gorm.DB {
+	return ds.db.WithContext(ctx).Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	})
+}
+
+// filtered returns the books matching filter, like bookMongoFilter does for
+// the Mongo implementation.
+func (ds *BookGormDataSource) filtered(ctx context.Context, filter models.BookFilter) *gorm.DB {
+	query := ds.withTags(ctx).Model(&gormModels.BookGormModel{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Isbn != "" {
+		query = query.Where("isbn = ?", filter.Isbn)
+	}
+	if filter.Title != "" {
+		query = query.Where("LOWER(title) LIKE ? ESCAPE '!'", containsPattern(filter.Title))
+	}
+	if filter.Writer != "" {
+		query = query.Where("LOWER(writer) LIKE ? ESCAPE '!'", containsPattern(filter.Writer))
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	if tags := distinctStrings(filter.Tags); len(tags) > 0 {
+		tagged := ds.db.Model(&gormModels.BookTagGormModel{}).
+			Select("book_id").
+			Where("tag IN ?", tags).
+			Group("book_id").
+			Having("COUNT(*) = ?", len(tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+	return query
+}
+
+func (ds *BookGormDataSource) findOne(query *gorm.DB) (*models.Book, error) {
+	bookData := &gormModels.BookGormModel{}
+	if err := query.Take(bookData).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrRecordNotFound{}
+		}
+		return nil, err
+	}
+	return bookFromGormModel(bookData), nil
+}
+
+// bookGormIterator loads the books in batches ordered by id, so a long export
+// neither holds a cursor open nor loads the whole table.
+type bookGormIterator struct {
+	ds     *BookGormDataSource
+	filter models.BookFilter
+	batch  []*models.Book
+	index  int
+	lastID uint
+	done   bool
+	err    error
+}
+
+// Next implements repositories.BookIterator
+func (it *bookGormIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.batch) {
+		it.index++
+		return true
+	}
+	if it.done {
+		return false
+	}
+	var bookData []gormModels.BookGormModel
+	it.err = it.ds.filtered(ctx, it.filter).
+		Where("id > ?", it.lastID).
+		Order("id").
+		Limit(bookGormIterateBatchSize).
+		Find(&bookData).Error
+	if it.err != nil {
+		return false
+	}
+	it.done = len(bookData) < bookGormIterateBatchSize
+	it.batch, it.index = booksFromGormModels(bookData), 0
+	if len(it.batch) == 0 {
+		return false
+	}
+	it.lastID = it.batch[len(it.batch)-1].ID
+	return true
+}
+
+// Book implements repositories.BookIterator
+func (it *bookGormIterator) Book() *models.Book {
+	return it.batch[it.index]
+}
+
+// Err implements repositories.BookIterator
+func (it *bookGormIterator) Err() error {
+	return it.err
+}
+
+// Close implements repositories.BookIterator
+func (it *bookGormIterator) Close(ctx context.Context) error {
+	it.batch, it.done = nil, true
+	return nil
+}
+
+// containsPattern returns the LIKE pattern of the values containing s, case
+// insensitively, with '!' as escape character.
+func containsPattern(s string) string {
+	escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(strings.ToLower(s))
+	return "%" + escaped + "%"
+}
+
+func distinctStrings(values []string) []string {
+	distinct := make([]string, 0, len(values))
+	for _, value := range values {
+		if !containsString(distinct, value) {
+			distinct = append(distinct, value)
+		}
+	}
+	return distinct
+}
+
+func bookTagGormModels(bookID uint, tags []string) []gormModels.BookTagGormModel {
+	if len(tags) == 0 {
+		return nil
+	}
+	tagData := make([]gormModels.BookTagGormModel, 0, len(tags))
+	for i, tag := range tags {
+		tagData = append(tagData, gormModels.BookTagGormModel{BookID: bookID, Tag: tag, Position: i})
+	}
+	return tagData
+}
+
+func booksFromGormModels(bookData []gormModels.BookGormModel) []*models.Book {
+	books := make([]*models.Book, 0, len(bookData))
+	for i := range bookData {
+		books = append(books, bookFromGormModel(&bookData[i]))
+	}
+	return books
+}
+
+func bookFromGormModel(bd *gormModels.BookGormModel) *models.Book {
+	book := &models.Book{
+		ID:         bd.ID,
+		Title:      bd.Title,
+		Isbn:       bd.Isbn,
+		Writer:     bd.Writer,
+		CategoryID: bd.CategoryID,
+		CreatedAt:  bd.CreatedAt,
+		UpdatedAt:  bd.UpdatedAt,
+		Version:    bd.Version,
+		UserID:     bd.UserID,
+	}
+	for _, tag := range bd.Tags {
+		book.Tags = append(book.Tags, tag.Tag)
+	}
+	if bd.DeletedAt.Valid {
+		deletedAt := bd.DeletedAt.Time
+		book.DeletedAt = &deletedAt
+	}
+	return book
+}
+
+func NewBookGormDataSource(db *gorm.DB) repositories.BookRepository {
+	return &BookGormDataSource{db: db}
+}
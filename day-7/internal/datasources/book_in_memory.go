@@ -4,59 +4,318 @@ import (
 	"alterra-agmc-day-7/internal/models"
 	"alterra-agmc-day-7/internal/repositories"
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 )
 
+// BookInMemoryDataSource keeps books in memory. It is safe for concurrent use
+// and hands out copies, so callers never share a book with the store. Opened
+// with OpenBookInMemoryDataSource it also persists them, see bookJournal.
 type BookInMemoryDataSource struct {
-	books []*models.Book
+	mu      sync.RWMutex
+	books   []*models.Book
+	lastID  uint
+	journal *bookJournal
 }
 
 // Create implements repositories.BookRepository
 func (ds *BookInMemoryDataSource) Create(ctx context.Context, book *models.Book) (*models.Book, error) {
-	book.CreatedAt = time.Now().UTC()
-	book.UpdatedAt = time.Now().UTC()
-	book.ID = uint(len(ds.books) + 1)
-	ds.books = append(ds.books, book)
-	return book, nil
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	stored := copyBook(book)
+	stored.ID = ds.lastID + 1
+	stored.CreatedAt = time.Now().UTC()
+	stored.UpdatedAt = stored.CreatedAt
+	stored.DeletedAt = nil
+	stored.Version = 1
+	if err := ds.journal.put(stored); err != nil {
+		return nil, err
+	}
+	ds.lastID = stored.ID
+	ds.books = append(ds.books, stored)
+	book.ID = stored.ID
+	book.CreatedAt = stored.CreatedAt
+	book.UpdatedAt = stored.UpdatedAt
+	book.DeletedAt = nil
+	book.Version = stored.Version
+	return copyBook(stored), nil
 }
 
 // DeleteByID implements repositories.BookRepository
-func (ds *BookInMemoryDataSource) DeleteByID(ctx context.Context, id uint) error {
-	for i, book := range ds.books {
-		if (book.ID) == id {
-			ds.books = append(ds.books[:i], ds.books[i+1:]...)
-			return nil
-		}
+func (ds *BookInMemoryDataSource) DeleteByID(ctx context.Context, id uint, version uint) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	i := ds.index(id, false)
+	if i < 0 {
+		return repositories.ErrRecordNotFound{}
+	}
+	if ds.books[i].Version != version {
+		return repositories.ErrVersionConflict{}
 	}
-	return new(ErrRecordNotFound)
+	deleted := copyBook(ds.books[i])
+	deletedAt := time.Now().UTC()
+	deleted.DeletedAt = &deletedAt
+	return ds.replace(i, deleted)
 }
 
 // FindAll implements repositories.BookRepository
-func (ds *BookInMemoryDataSource) FindAll(ctx context.Context) ([]*models.Book, error) {
-	return ds.books, nil
+func (ds *BookInMemoryDataSource) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	results := make([]*models.Book, 0)
+	for _, book := range ds.books {
+		if book.DeletedAt == nil && matchBookFilter(book, filter) {
+			results = append(results, copyBook(book))
+		}
+	}
+	return results, nil
+}
+
+// Iterate implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) Iterate(ctx context.Context, filter models.BookFilter) (repositories.BookIterator, error) {
+	books, err := ds.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &bookSliceIterator{books: books, index: -1}, nil
 }
 
 // FindByID implements repositories.BookRepository
 func (ds *BookInMemoryDataSource) FindByID(ctx context.Context, id uint) (*models.Book, error) {
+	return ds.findOne(id, false)
+}
+
+// FindDeleted implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	results := make([]*models.Book, 0)
 	for _, book := range ds.books {
-		if (book.ID) == id {
-			return book, nil
+		if book.DeletedAt != nil && book.UserID == userID {
+			results = append(results, copyBook(book))
 		}
 	}
-	return nil, new(ErrRecordNotFound)
+	return results, nil
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) FindDeletedByID(ctx context.Context, id uint) (*models.Book, error) {
+	return ds.findOne(id, true)
+}
+
+// Restore implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) Restore(ctx context.Context, id uint) (*models.Book, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	i := ds.index(id, true)
+	if i < 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	restored := copyBook(ds.books[i])
+	restored.DeletedAt = nil
+	restored.UpdatedAt = time.Now().UTC()
+	if err := ds.replace(i, restored); err != nil {
+		return nil, err
+	}
+	return copyBook(restored), nil
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	var purged []uint
+	books := make([]*models.Book, 0, len(ds.books))
+	for _, book := range ds.books {
+		if book.DeletedAt != nil && book.DeletedAt.Before(before) {
+			purged = append(purged, book.ID)
+			continue
+		}
+		books = append(books, book)
+	}
+	if len(purged) == 0 {
+		return 0, nil
+	}
+	if err := ds.journal.remove(purged); err != nil {
+		return 0, err
+	}
+	ds.books = books
+	return int64(len(purged)), nil
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (ds *BookInMemoryDataSource) ExistsByIsbn(ctx context.Context, isbn string) (bool, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	for _, book := range ds.books {
+		if book.Isbn == isbn && book.DeletedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // Update implements repositories.BookRepository
-func (ds *BookInMemoryDataSource) Update(ctx context.Context, book *models.Book) (*models.Book, error) {
-	for i, b := range ds.books {
-		if b.ID == book.ID {
-			book.UpdatedAt = time.Now().UTC()
-			book.UserID = b.UserID
-			ds.books[i] = book
-			return book, nil
+func (ds *BookInMemoryDataSource) Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	i := ds.index(book.ID, false)
+	if i < 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	if ds.books[i].Version != book.Version {
+		return nil, repositories.ErrVersionConflict{}
+	}
+	updated := copyBook(ds.books[i])
+	for _, field := range fields {
+		switch field {
+		case models.BookFieldTitle:
+			updated.Title = book.Title
+		case models.BookFieldIsbn:
+			updated.Isbn = book.Isbn
+		case models.BookFieldWriter:
+			updated.Writer = book.Writer
+		case models.BookFieldCategoryID:
+			updated.CategoryID = book.CategoryID
+		case models.BookFieldTags:
+			updated.Tags = copyStrings(book.Tags)
+		default:
+			return nil, fmt.Errorf("unknown book field %q", field)
 		}
 	}
-	return nil, new(ErrRecordNotFound)
+	updated.Version++
+	updated.UpdatedAt = time.Now().UTC()
+	if err := ds.replace(i, updated); err != nil {
+		return nil, err
+	}
+	return copyBook(updated), nil
+}
+
+func (ds *BookInMemoryDataSource) findOne(id uint, deleted bool) (*models.Book, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if i := ds.index(id, deleted); i >= 0 {
+		return copyBook(ds.books[i]), nil
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+// index returns the position of the book with id, among the deleted books
+// when deleted is true and among the others otherwise, or -1. The caller must
+// hold ds.mu.
+func (ds *BookInMemoryDataSource) index(id uint, deleted bool) int {
+	for i, book := range ds.books {
+		if book.ID == id && (book.DeletedAt != nil) == deleted {
+			return i
+		}
+	}
+	return -1
+}
+
+// replace journals book and stores it at position i. Stored books are never
+// changed in place, so copies handed out earlier stay consistent. The caller
+// must hold ds.mu for writing.
+func (ds *BookInMemoryDataSource) replace(i int, book *models.Book) error {
+	if err := ds.journal.put(book); err != nil {
+		return err
+	}
+	ds.books[i] = book
+	return nil
+}
+
+func copyBook(book *models.Book) *models.Book {
+	copied := *book
+	copied.Tags = copyStrings(book.Tags)
+	if book.DeletedAt != nil {
+		deletedAt := *book.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	return &copied
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(values)), values...)
+}
+
+func matchBookFilter(book *models.Book, filter models.BookFilter) bool {
+	if filter.UserID != 0 && book.UserID != filter.UserID {
+		return false
+	}
+	if filter.Isbn != "" && book.Isbn != filter.Isbn {
+		return false
+	}
+	if filter.Title != "" && !strings.Contains(strings.ToLower(book.Title), strings.ToLower(filter.Title)) {
+		return false
+	}
+	if filter.Writer != "" && !strings.Contains(strings.ToLower(book.Writer), strings.ToLower(filter.Writer)) {
+		return false
+	}
+	if len(filter.CategoryIDs) > 0 && !containsUint(filter.CategoryIDs, book.CategoryID) {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(book.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type bookSliceIterator struct {
+	books []*models.Book
+	index int
+	err   error
+}
+
+// Next implements repositories.BookIterator
+func (it *bookSliceIterator) Next(ctx context.Context) bool {
+	if it.err = ctx.Err(); it.err != nil {
+		return false
+	}
+	if it.index+1 >= len(it.books) {
+		return false
+	}
+	it.index++
+	return true
+}
+
+// Book implements repositories.BookIterator
+func (it *bookSliceIterator) Book() *models.Book {
+	return it.books[it.index]
+}
+
+// Err implements repositories.BookIterator
+func (it *bookSliceIterator) Err() error {
+	return it.err
+}
+
+// Close implements repositories.BookIterator
+func (it *bookSliceIterator) Close(ctx context.Context) error {
+	it.books = nil
+	return nil
 }
 
 func NewBookInMemoryDataSource() repositories.BookRepository {
@@ -0,0 +1,310 @@
+package datasources
+
+import (
+	dsModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Files of a persistent in-memory book store, in its data directory.
+const (
+	BookSnapshotFile = "books.snapshot.json"
+	BookLogFile      = "books.log"
+)
+
+// Operations of the entries of the book log.
+const (
+	bookLogPut   = "put"
+	bookLogPurge = "purge"
+)
+
+// bookJournal persists the books of a BookInMemoryDataSource in a directory:
+// a snapshot of every book, and a log of the changes made since. Each change
+// is appended to the log and synced before the store applies it, so a change
+// that was acknowledged survives a crash. A nil bookJournal persists nothing.
+type bookJournal struct {
+	dir    string
+	log    *os.File
+	size   int64
+	logger *slog.Logger
+}
+
+func (j *bookJournal) put(book *models.Book) error {
+	if j == nil {
+		return nil
+	}
+	model := bookToFileModel(book)
+	return j.append(dsModels.BookLogEntryFileModel{Op: bookLogPut, Book: &model})
+}
+
+func (j *bookJournal) remove(ids []uint) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(dsModels.BookLogEntryFileModel{Op: bookLogPurge, IDs: ids})
+}
+
+func (j *bookJournal) append(entry dsModels.BookLogEntryFileModel) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := j.log.Write(line); err != nil {
+		// Drop whatever part of the entry was written, the next one would
+		// follow a torn line otherwise.
+		_ = j.log.Truncate(j.size)
+		return fmt.Errorf("append to book log: %v", err)
+	}
+	if err := j.log.Sync(); err != nil {
+		_ = j.log.Truncate(j.size)
+		return fmt.Errorf("sync book log: %v", err)
+	}
+	j.size += int64(len(line))
+	return nil
+}
+
+// Snapshot writes every book to the snapshot file of the store and empties
+// its log. It does nothing when the store is not persistent.
+func (ds *BookInMemoryDataSource) Snapshot() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	j := ds.journal
+	if j == nil {
+		return nil
+	}
+	snapshot := dsModels.BookSnapshotFileModel{LastID: ds.lastID, Books: make([]dsModels.BookFileModel, 0, len(ds.books))}
+	for _, book := range ds.books {
+		snapshot.Books = append(snapshot.Books, bookToFileModel(book))
+	}
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(filepath.Join(j.dir, BookSnapshotFile), content); err != nil {
+		return fmt.Errorf("write book snapshot: %v", err)
+	}
+	// Should the log not be emptied, replaying it over the new snapshot
+	// rewrites the same books: entries hold whole books, not changes.
+	if err := j.log.Truncate(0); err != nil {
+		return fmt.Errorf("truncate book log: %v", err)
+	}
+	if err := j.log.Sync(); err != nil {
+		return fmt.Errorf("sync book log: %v", err)
+	}
+	j.size = 0
+	return nil
+}
+
+// RunSnapshots snapshots the store on every interval until ctx is cancelled.
+func (ds *BookInMemoryDataSource) RunSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := ds.Snapshot(); err != nil && ds.journal != nil {
+			ds.journal.logger.ErrorContext(ctx, "book snapshot failed", "error", err)
+		}
+	}
+}
+
+// Close snapshots the store and closes its log. The store cannot be changed
+// afterwards.
+func (ds *BookInMemoryDataSource) Close() error {
+	if ds.journal == nil {
+		return nil
+	}
+	err := ds.Snapshot()
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if closeErr := ds.journal.log.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// load restores the books of the snapshot and log of the journal, then opens
+// the log for appending. An entry torn by a crash while it was written ends
+// the log; it is dropped with a warning since it was never acknowledged. The
+// log is opened in append mode, so writes follow any truncation.
+func (ds *BookInMemoryDataSource) load() error {
+	j := ds.journal
+	content, err := os.ReadFile(filepath.Join(j.dir, BookSnapshotFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		var snapshot dsModels.BookSnapshotFileModel
+		if err := json.Unmarshal(content, &snapshot); err != nil {
+			return fmt.Errorf("%s: %v", BookSnapshotFile, err)
+		}
+		ds.lastID = snapshot.LastID
+		for i := range snapshot.Books {
+			ds.books = append(ds.books, bookFromFileModel(&snapshot.Books[i]))
+		}
+	}
+
+	logPath := filepath.Join(j.dir, BookLogFile)
+	log, err := os.OpenFile(logPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(log)
+	var offset int64
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				j.logger.Warn("dropping torn entry at the end of the book log", "file", logPath, "line", n)
+				if err := log.Truncate(offset); err != nil {
+					_ = log.Close()
+					return err
+				}
+			}
+			break
+		}
+		if err != nil {
+			_ = log.Close()
+			return err
+		}
+		var entry dsModels.BookLogEntryFileModel
+		if err := json.Unmarshal(line, &entry); err != nil {
+			_ = log.Close()
+			return fmt.Errorf("%s line %d: %v", BookLogFile, n, err)
+		}
+		if err := ds.replay(entry); err != nil {
+			_ = log.Close()
+			return fmt.Errorf("%s line %d: %v", BookLogFile, n, err)
+		}
+		offset += int64(len(line))
+	}
+	j.log = log
+	j.size = offset
+	return nil
+}
+
+func (ds *BookInMemoryDataSource) replay(entry dsModels.BookLogEntryFileModel) error {
+	switch entry.Op {
+	case bookLogPut:
+		if entry.Book == nil {
+			return fmt.Errorf("%s entry without a book", bookLogPut)
+		}
+		book := bookFromFileModel(entry.Book)
+		if book.ID > ds.lastID {
+			ds.lastID = book.ID
+		}
+		for i := range ds.books {
+			if ds.books[i].ID == book.ID {
+				ds.books[i] = book
+				return nil
+			}
+		}
+		ds.books = append(ds.books, book)
+	case bookLogPurge:
+		purged := make(map[uint]bool, len(entry.IDs))
+		for _, id := range entry.IDs {
+			purged[id] = true
+		}
+		books := ds.books[:0]
+		for _, book := range ds.books {
+			if !purged[book.ID] {
+				books = append(books, book)
+			}
+		}
+		ds.books = books
+	default:
+		return fmt.Errorf("unknown operation %q", entry.Op)
+	}
+	return nil
+}
+
+// writeFileAtomically replaces the file at path with content, so that a
+// crash leaves either the old or the new file.
+func writeFileAtomically(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func bookToFileModel(book *models.Book) dsModels.BookFileModel {
+	return dsModels.BookFileModel{
+		ID:         book.ID,
+		Title:      book.Title,
+		Isbn:       book.Isbn,
+		Writer:     book.Writer,
+		CategoryID: book.CategoryID,
+		Tags:       book.Tags,
+		CreatedAt:  book.CreatedAt,
+		UpdatedAt:  book.UpdatedAt,
+		DeletedAt:  book.DeletedAt,
+		Version:    book.Version,
+		UserID:     book.UserID,
+	}
+}
+
+func bookFromFileModel(fileModel *dsModels.BookFileModel) *models.Book {
+	return &models.Book{
+		ID:         fileModel.ID,
+		Title:      fileModel.Title,
+		Isbn:       fileModel.Isbn,
+		Writer:     fileModel.Writer,
+		CategoryID: fileModel.CategoryID,
+		Tags:       fileModel.Tags,
+		CreatedAt:  fileModel.CreatedAt,
+		UpdatedAt:  fileModel.UpdatedAt,
+		DeletedAt:  fileModel.DeletedAt,
+		Version:    fileModel.Version,
+		UserID:     fileModel.UserID,
+	}
+}
+
+// OpenBookInMemoryDataSource returns an in-memory book store persisted in
+// dir, created if needed, with the books it held when last used. Changes are
+// logged as they are made; call Snapshot, or RunSnapshots, to keep the log
+// short, and Close when done.
+func OpenBookInMemoryDataSource(dir string, logger *slog.Logger) (*BookInMemoryDataSource, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	ds := &BookInMemoryDataSource{journal: &bookJournal{dir: dir, logger: logger}}
+	if err := ds.load(); err != nil {
+		return nil, fmt.Errorf("load books from %s: %v", dir, err)
+	}
+	return ds, nil
+}
@@ -3,289 +3,204 @@ package datasources_test
 import (
 	"alterra-agmc-day-7/internal/datasources"
 	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/pkg/logger"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestCreateBook(t *testing.T) {
-	testCases := []struct {
-		name          string
-		book          *models.Book
-		expectedCount int
-	}{
-		{
-			name: "Test Create book should add book to memory",
-			book: &models.Book{
-				Title:  "title",
-				Writer: "writer",
-				UserID: 12,
-				Isbn:   "isbn",
-			},
-			expectedCount: 1,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Act
-			ds := datasources.NewBookInMemoryDataSource()
-
-			// Assert
-			_, err := ds.Create(context.TODO(), tc.book)
-			all, err := ds.FindAll(context.TODO())
-
-			assert.NoError(t, err)
-			assert.Equal(t, tc.expectedCount, len(all))
-		})
+func openTestBookStore(t *testing.T, dir string) *datasources.BookInMemoryDataSource {
+	ds, err := datasources.OpenBookInMemoryDataSource(dir, logger.Discard())
+	if err != nil {
+		t.Fatal(err)
 	}
+	return ds
 }
 
-func TestFindAllBook(t *testing.T) {
+func TestBookInMemoryDataSourcePersistence(t *testing.T) {
 	testCases := []struct {
-		name     string
-		books    []*models.Book
-		expected []*models.Book
+		name string
+		// restart releases ds the way the app would, or not at all for a crash.
+		restart func(t *testing.T, ds *datasources.BookInMemoryDataSource)
 	}{
 		{
-			name:     "Test FindAll with empty books",
-			books:    []*models.Book{},
-			expected: []*models.Book{},
+			name:    "Test books should be replayed from the log after a crash",
+			restart: func(t *testing.T, ds *datasources.BookInMemoryDataSource) {},
 		},
 		{
-			name: "Test FindAll with empty books",
-			books: []*models.Book{
-				{
-					Title:  "title",
-					Writer: "writer",
-					UserID: 12,
-					Isbn:   "isbn",
-				},
-			},
-			expected: []*models.Book{
-				{
-					Title:  "title",
-					Writer: "writer",
-					UserID: 12,
-					Isbn:   "isbn",
-				},
+			name: "Test books should be loaded from the snapshot after a snapshot",
+			restart: func(t *testing.T, ds *datasources.BookInMemoryDataSource) {
+				assert.NoError(t, ds.Snapshot())
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Setup
-			ds := datasources.NewBookInMemoryDataSource()
-			for _, b := range tc.books {
-				_, _ = ds.Create(context.TODO(), b)
-			}
-
-			// Act
-			result, _ := ds.FindAll(context.TODO())
-			assert.Equal(t, len(tc.expected), len(result))
-			for i, r := range result {
-				expectedBook := tc.books[i]
-				assert.Equal(t, expectedBook.Title, r.Title)
-				assert.Equal(t, expectedBook.Writer, r.Writer)
-				assert.Equal(t, expectedBook.Isbn, r.Isbn)
-				assert.Equal(t, expectedBook.UserID, r.UserID)
-			}
-		})
-	}
-}
-
-func TestDeleteByID(t *testing.T) {
-	testCases := []struct {
-		name          string
-		books         []*models.Book
-		id            uint
-		expected      error
-		expectedCount int
-	}{
-		{
-			name:          "Test delete when id not found should return err record not found",
-			books:         []*models.Book{},
-			id:            9,
-			expected:      datasources.ErrRecordNotFound{},
-			expectedCount: 0,
-		},
 		{
-			name: "Test delete when id found should return nil",
-			books: []*models.Book{
-				{
-					Title:  "title",
-					Writer: "writer",
-					UserID: 12,
-					Isbn:   "isbn",
-				},
-				{
-					Title:  "title2",
-					Writer: "writer2",
-					UserID: 12,
-					Isbn:   "isbn2",
-				},
+			name: "Test books should be loaded from the snapshot after a close",
+			restart: func(t *testing.T, ds *datasources.BookInMemoryDataSource) {
+				assert.NoError(t, ds.Close())
 			},
-			id:            1,
-			expected:      nil,
-			expectedCount: 1,
 		},
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
 			// Setup
-			ds := datasources.NewBookInMemoryDataSource()
-			for _, b := range tc.books {
-				_, _ = ds.Create(context.TODO(), b)
+			dir := t.TempDir()
+			ds := openTestBookStore(t, dir)
+			ctx := context.TODO()
+			var ids []uint
+			for i := 1; i <= 3; i++ {
+				book, err := ds.Create(ctx, &models.Book{Title: fmt.Sprintf("Book %d", i), Isbn: fmt.Sprint(i), UserID: 12, Tags: []string{"go"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, book.ID)
+			}
+			if _, err := ds.Update(ctx, &models.Book{ID: ids[0], Title: "Updated", Version: 1}, []string{models.BookFieldTitle}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ds.DeleteByID(ctx, ids[1], 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := ds.DeleteByID(ctx, ids[2], 1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ds.PurgeDeletedBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
 			}
+			testCase.restart(t, ds)
 
 			// Act
-			result := ds.DeleteByID(context.TODO(), tc.id)
-			all, _ := ds.FindAll(context.TODO())
+			reopened := openTestBookStore(t, dir)
+			t.Cleanup(func() { _ = reopened.Close() })
+			books, err := reopened.FindAll(ctx, models.BookFilter{})
+			created, createErr := reopened.Create(ctx, &models.Book{Title: "Book 4", Isbn: "4", UserID: 12})
 
 			// Assert
-			assert.Equal(t, tc.expected.Error(), result.Error())
-			assert.Equal(t, tc.expectedCount, len(all))
+			assert.NoError(t, err)
+			if assert.Len(t, books, 1) {
+				assert.Equal(t, ids[0], books[0].ID)
+				assert.Equal(t, "Updated", books[0].Title)
+				assert.Equal(t, uint(2), books[0].Version)
+				assert.Equal(t, []string{"go"}, books[0].Tags)
+			}
+			assert.NoError(t, createErr)
+			// Ids of purged books are not handed out again.
+			assert.Equal(t, ids[2]+1, created.ID)
 		})
 	}
 }
 
-func TestFindBookByID(t *testing.T) {
-	testCases := []struct {
-		name         string
-		books        []*models.Book
-		id           uint
-		expectedErr  error
-		expectedBook *models.Book
-	}{
-		{
-			name:         "Test findby id when id not found should return err record not found",
-			books:        []*models.Book{},
-			id:           9,
-			expectedErr:  datasources.ErrRecordNotFound{},
-			expectedBook: nil,
-		},
-		{
-			name: "Test find bt id when id found should return expected book",
-			books: []*models.Book{
-				{
-					Title:  "title",
-					Writer: "writer",
-					UserID: 12,
-					Isbn:   "isbn",
-				},
-			},
-			id:          1,
-			expectedErr: nil,
-			expectedBook: &models.Book{
-				Title:  "title",
-				Writer: "writer",
-				UserID: 12,
-				Isbn:   "isbn",
-			},
-		},
+func TestBookInMemoryDataSourceTornLog(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	ds := openTestBookStore(t, dir)
+	if _, err := ds.Create(context.TODO(), &models.Book{Title: "Book 1", Isbn: "1", UserID: 12}); err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.OpenFile(filepath.Join(dir, datasources.BookLogFile), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
 	}
+	if _, err := log.WriteString(`{"op":"put","book":{"id":2,"ti`); err != nil {
+		t.Fatal(err)
+	}
+	_ = log.Close()
+
+	// Act
+	reopened := openTestBookStore(t, dir)
+	t.Cleanup(func() { _ = reopened.Close() })
+	created, createErr := reopened.Create(context.TODO(), &models.Book{Title: "Book 2", Isbn: "2", UserID: 12})
+	again := openTestBookStore(t, dir)
+	t.Cleanup(func() { _ = again.Close() })
+	books, err := again.FindAll(context.TODO(), models.BookFilter{})
+
+	// Assert
+	assert.NoError(t, createErr)
+	assert.Equal(t, uint(2), created.ID)
+	assert.NoError(t, err)
+	assert.Len(t, books, 2)
+}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Setup
-			ds := datasources.NewBookInMemoryDataSource()
-			for _, b := range tc.books {
-				_, _ = ds.Create(context.TODO(), b)
-			}
+func TestBookInMemoryDataSourceCorruptLog(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	content := "{\"op\":\"put\",\"book\":{\"id\":1}}\nnot json\n"
+	if err := os.WriteFile(filepath.Join(dir, datasources.BookLogFile), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-			// Act
-			result, err := ds.FindByID(context.TODO(), tc.id)
+	// Act
+	_, err := datasources.OpenBookInMemoryDataSource(dir, logger.Discard())
 
-			// Assert
-			if tc.expectedErr != nil {
-				assert.Equal(t, tc.expectedErr.Error(), err.Error())
-			}
-			if tc.expectedBook != nil {
-				assert.Equal(t, tc.expectedBook.Title, result.Title)
-				assert.Equal(t, tc.expectedBook.Writer, result.Writer)
-				assert.Equal(t, tc.expectedBook.Isbn, result.Isbn)
-				assert.Equal(t, tc.expectedBook.UserID, result.UserID)
-			}
-		})
-	}
+	// Assert
+	assert.ErrorContains(t, err, "books.log line 2")
 }
 
-func TestUpdateeBook(t *testing.T) {
-	testCases := []struct {
-		name         string
-		books        []*models.Book
-		updateBook   *models.Book
-		expectedErr  error
-		expectedBook *models.Book
-	}{
-		{
-			name:  "Test update when id not found should return err record not found",
-			books: []*models.Book{},
-			updateBook: &models.Book{
-				Title:  "title",
-				Writer: "writer",
-				UserID: 12,
-				Isbn:   "isbn",
-			},
-			expectedErr:  datasources.ErrRecordNotFound{},
-			expectedBook: nil,
-		},
-		{
-			name: "Test delete when id found should return nil",
-			books: []*models.Book{
-				{
-					Title:  "title",
-					Writer: "writer",
-					UserID: 12,
-					Isbn:   "isbn",
-				},
-				{
-					Title:  "title2",
-					Writer: "writer2",
-					UserID: 15,
-					Isbn:   "isbn2",
-				},
-			},
-			updateBook: &models.Book{
-				Title:  "title_new",
-				Writer: "writer_new",
-				ID:     2,
-				Isbn:   "isbn_new",
-			},
-			expectedBook: &models.Book{
-				Title:  "title_new",
-				Writer: "writer_new",
-				UserID: 15,
-				Isbn:   "isbn_new",
-			},
-		},
+func TestBookInMemoryDataSourceCopies(t *testing.T) {
+	// Setup
+	ds := datasources.NewBookInMemoryDataSource()
+	ctx := context.TODO()
+	book := &models.Book{Title: "Book 1", Isbn: "1", UserID: 12, Tags: []string{"go"}}
+	created, err := ds.Create(ctx, book)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Setup
-			ds := datasources.NewBookInMemoryDataSource()
-			for _, b := range tc.books {
-				_, _ = ds.Create(context.TODO(), b)
-			}
-
-			// Act
-			result, err := ds.Update(context.TODO(), tc.updateBook)
+	// Act
+	book.Title = "changed"
+	book.Tags[0] = "changed"
+	created.Title = "changed"
+	all, _ := ds.FindAll(ctx, models.BookFilter{})
+	all[0].Tags[0] = "changed"
+	all[0] = nil
+	found, err := ds.FindByID(ctx, created.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Book 1", found.Title)
+	assert.Equal(t, []string{"go"}, found.Tags)
+}
 
-			// Assert
-			if tc.expectedErr != nil {
-				assert.Equal(t, tc.expectedErr.Error(), err.Error())
+func TestBookInMemoryDataSourceConcurrency(t *testing.T) {
+	// Setup
+	ds := openTestBookStore(t, t.TempDir())
+	t.Cleanup(func() { _ = ds.Close() })
+	const writers = 20
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			book, err := ds.Create(context.TODO(), &models.Book{Title: fmt.Sprintf("Book %d", i), Isbn: fmt.Sprint(i), UserID: 12})
+			if err != nil {
+				return
 			}
-			if tc.expectedBook != nil {
-				assert.Equal(t, tc.expectedBook.Title, result.Title)
-				assert.Equal(t, tc.expectedBook.Writer, result.Writer)
-				assert.Equal(t, tc.expectedBook.Isbn, result.Isbn)
-				assert.Equal(t, fmt.Sprintf("%d", tc.expectedBook.UserID), fmt.Sprintf("%d", result.UserID))
+			_, _ = ds.FindAll(context.TODO(), models.BookFilter{})
+			_, _ = ds.Update(context.TODO(), &models.Book{ID: book.ID, Title: "updated", Version: 1}, []string{models.BookFieldTitle})
+			if i%5 == 0 {
+				_ = ds.Snapshot()
 			}
-		})
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	all, err := ds.FindAll(context.TODO(), models.BookFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, writers, len(all))
+	ids := make(map[uint]bool, writers)
+	for _, book := range all {
+		ids[book.ID] = true
+		assert.Equal(t, "updated", book.Title)
+		assert.Equal(t, uint(2), book.Version)
 	}
+	assert.Equal(t, writers, len(ids))
 }
@@ -5,53 +5,126 @@ import (
 	"alterra-agmc-day-7/internal/models"
 	"alterra-agmc-day-7/internal/repositories"
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const bookMongoSequence = "books"
+
 type bookMongoDataSource struct {
 	db *mongo.Database
 }
 
 // Create implements repositories.BookRepository
 func (ds *bookMongoDataSource) Create(ctx context.Context, book *models.Book) (*models.Book, error) {
-	all, err := ds.FindAll(ctx)
+	id, err := nextMongoSequence(ctx, ds.db, bookMongoSequence)
 	if err != nil {
 		return nil, err
 	}
 	utcNow := time.Now().UTC()
-	book.ID = uint((len(all) + 1))
+	book.ID = id
 	book.CreatedAt = utcNow
 	book.UpdatedAt = utcNow
+	book.Version = 1
 	mongoModel := &dsModels.BookMongoModel{
-		ID:        book.ID,
-		Title:     book.Title,
-		Isbn:      book.Isbn,
-		Writer:    book.Writer,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
-		UserID:    book.UserID,
-	}
-	res, err := ds.collections().InsertOne(ctx, mongoModel)
-	if err != nil {
+		ID:         book.ID,
+		Title:      book.Title,
+		Isbn:       book.Isbn,
+		Writer:     book.Writer,
+		CategoryID: book.CategoryID,
+		Tags:       book.Tags,
+		CreatedAt:  book.CreatedAt,
+		UpdatedAt:  book.UpdatedAt,
+		Version:    book.Version,
+		UserID:     book.UserID,
+	}
+	if _, err := ds.collections().InsertOne(ctx, mongoModel); err != nil {
 		return nil, err
 	}
-	book.ID = uint(res.InsertedID.(int64))
 	return book, nil
 }
 
 // DeleteByID implements repositories.BookRepository
-func (ds *bookMongoDataSource) DeleteByID(ctx context.Context, id uint) error {
-	_, err := ds.collections().DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (ds *bookMongoDataSource) DeleteByID(ctx context.Context, id uint, version uint) error {
+	res, err := ds.collections().UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deleted_at": nil, "version": mongoVersionFilter(version)},
+		bson.M{"$set": bson.M{"deleted_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if _, err := ds.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return repositories.ErrVersionConflict{}
+	}
+	return nil
+}
+
+// FindDeleted implements repositories.BookRepository
+func (ds *bookMongoDataSource) FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error) {
+	results := make([]*models.Book, 0)
+	cur, err := ds.collections().Find(ctx, bson.M{"user_id": userID, "deleted_at": bson.M{"$ne": nil}})
+	if err != nil {
+		return results, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		mongoModel := &dsModels.BookMongoModel{}
+		if err := cur.Decode(mongoModel); err != nil {
+			return results, err
+		}
+		results = append(results, bookFromMongoModel(mongoModel))
+	}
+	return results, cur.Err()
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (ds *bookMongoDataSource) FindDeletedByID(ctx context.Context, id uint) (*models.Book, error) {
+	return ds.findOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}})
+}
+
+// Restore implements repositories.BookRepository
+func (ds *bookMongoDataSource) Restore(ctx context.Context, id uint) (*models.Book, error) {
+	res, err := ds.collections().UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{
+			"$unset": bson.M{"deleted_at": ""},
+			"$set":   bson.M{"updated_at": time.Now().UTC()},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	return ds.FindByID(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository
+func (ds *bookMongoDataSource) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := ds.collections().DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
 }
 
 // FindAll implements repositories.BookRepository
-func (ds *bookMongoDataSource) FindAll(ctx context.Context) ([]*models.Book, error) {
+func (ds *bookMongoDataSource) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
 	results := make([]*models.Book, 0)
-	cur, err := ds.collections().Find(ctx, bson.M{})
+	cur, err := ds.collections().Find(ctx, bookMongoFilter(filter))
 	if err != nil {
 		return results, err
 	}
@@ -62,23 +135,33 @@ func (ds *bookMongoDataSource) FindAll(ctx context.Context) ([]*models.Book, err
 		if err != nil {
 			return results, err
 		}
-		book := &models.Book{
-			ID:        mongoModel.ID,
-			Title:     mongoModel.Title,
-			Isbn:      mongoModel.Isbn,
-			Writer:    mongoModel.Writer,
-			CreatedAt: mongoModel.CreatedAt,
-			UpdatedAt: mongoModel.UpdatedAt,
-			UserID:    mongoModel.UserID,
-		}
-		results = append(results, book)
+		results = append(results, bookFromMongoModel(mongoModel))
 	}
 	return results, nil
 }
 
+// Iterate implements repositories.BookRepository
+func (ds *bookMongoDataSource) Iterate(ctx context.Context, filter models.BookFilter) (repositories.BookIterator, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(bookMongoIterateBatchSize)
+	cur, err := ds.collections().Find(ctx, bookMongoFilter(filter), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &bookMongoIterator{cur: cur}, nil
+}
+
 // FindByID implements repositories.BookRepository
 func (ds *bookMongoDataSource) FindByID(ctx context.Context, id uint) (*models.Book, error) {
-	res := ds.collections().FindOne(ctx, bson.M{"_id": id})
+	return ds.findOne(ctx, bson.M{"_id": id, "deleted_at": nil})
+}
+
+func (ds *bookMongoDataSource) findOne(ctx context.Context, filter bson.M) (*models.Book, error) {
+	res := ds.collections().FindOne(ctx, filter)
+	if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+		return nil, repositories.ErrRecordNotFound{}
+	}
 	if res.Err() != nil {
 		return nil, res.Err()
 	}
@@ -87,40 +170,158 @@ func (ds *bookMongoDataSource) FindByID(ctx context.Context, id uint) (*models.B
 	if err != nil {
 		return nil, err
 	}
-	book := &models.Book{
-		ID:        mongoModel.ID,
-		Title:     mongoModel.Title,
-		Isbn:      mongoModel.Isbn,
-		Writer:    mongoModel.Writer,
-		CreatedAt: mongoModel.CreatedAt,
-		UpdatedAt: mongoModel.UpdatedAt,
-		UserID:    mongoModel.UserID,
+	return bookFromMongoModel(mongoModel), nil
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (ds *bookMongoDataSource) ExistsByIsbn(ctx context.Context, isbn string) (bool, error) {
+	count, err := ds.collections().CountDocuments(ctx, bson.M{"isbn": isbn, "deleted_at": nil}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
 	}
-	return book, nil
+	return count > 0, nil
 }
 
 // Update implements repositories.BookRepository
-func (ds *bookMongoDataSource) Update(ctx context.Context, book *models.Book) (*models.Book, error) {
-	book.UpdatedAt = time.Now().UTC()
-	mongoModel := &dsModels.BookMongoModel{
-		Title:     book.Title,
-		Isbn:      book.Isbn,
-		Writer:    book.Writer,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
-		UserID:    book.UserID,
-	}
-	_, err := ds.collections().UpdateByID(ctx, book.ID, mongoModel)
+func (ds *bookMongoDataSource) Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	set := bson.M{"updated_at": time.Now().UTC()}
+	unset := bson.M{}
+	for _, field := range fields {
+		switch field {
+		case models.BookFieldTitle:
+			set["title"] = book.Title
+		case models.BookFieldIsbn:
+			set["isbn"] = book.Isbn
+		case models.BookFieldWriter:
+			set["writer"] = book.Writer
+		case models.BookFieldCategoryID:
+			if book.CategoryID == 0 {
+				unset["category_id"] = ""
+			} else {
+				set["category_id"] = book.CategoryID
+			}
+		case models.BookFieldTags:
+			if len(book.Tags) == 0 {
+				unset["tags"] = ""
+			} else {
+				set["tags"] = book.Tags
+			}
+		default:
+			return nil, fmt.Errorf("unknown book field %q", field)
+		}
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	res, err := ds.collections().UpdateOne(
+		ctx,
+		bson.M{"_id": book.ID, "deleted_at": nil, "version": mongoVersionFilter(book.Version)},
+		update,
+	)
 	if err != nil {
 		return nil, err
 	}
-	return book, nil
+	if res.MatchedCount == 0 {
+		if _, err := ds.FindByID(ctx, book.ID); err != nil {
+			return nil, err
+		}
+		return nil, repositories.ErrVersionConflict{}
+	}
+	return ds.FindByID(ctx, book.ID)
+}
+
+// mongoVersionFilter matches documents at version. Documents written before
+// versioning was introduced have no version field and count as version 0.
+func mongoVersionFilter(version uint) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
 }
 
 func (ds *bookMongoDataSource) collections() *mongo.Collection {
 	return ds.db.Collection("books")
 }
 
+const bookMongoIterateBatchSize = 500
+
+type bookMongoIterator struct {
+	cur  *mongo.Cursor
+	book *models.Book
+	err  error
+}
+
+// Next implements repositories.BookIterator
+func (it *bookMongoIterator) Next(ctx context.Context) bool {
+	if !it.cur.Next(ctx) {
+		return false
+	}
+	mongoModel := &dsModels.BookMongoModel{}
+	if it.err = it.cur.Decode(mongoModel); it.err != nil {
+		return false
+	}
+	it.book = bookFromMongoModel(mongoModel)
+	return true
+}
+
+// Book implements repositories.BookIterator
+func (it *bookMongoIterator) Book() *models.Book {
+	return it.book
+}
+
+// Err implements repositories.BookIterator
+func (it *bookMongoIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.cur.Err()
+}
+
+// Close implements repositories.BookIterator
+func (it *bookMongoIterator) Close(ctx context.Context) error {
+	return it.cur.Close(ctx)
+}
+
+func bookMongoFilter(filter models.BookFilter) bson.M {
+	query := bson.M{"deleted_at": nil}
+	if filter.UserID != 0 {
+		query["user_id"] = filter.UserID
+	}
+	if filter.Isbn != "" {
+		query["isbn"] = filter.Isbn
+	}
+	if filter.Title != "" {
+		query["title"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Title), Options: "i"}
+	}
+	if filter.Writer != "" {
+		query["writer"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Writer), Options: "i"}
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query["category_id"] = bson.M{"$in": filter.CategoryIDs}
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$all": filter.Tags}
+	}
+	return query
+}
+
+func bookFromMongoModel(mongoModel *dsModels.BookMongoModel) *models.Book {
+	return &models.Book{
+		ID:         mongoModel.ID,
+		Title:      mongoModel.Title,
+		Isbn:       mongoModel.Isbn,
+		Writer:     mongoModel.Writer,
+		CategoryID: mongoModel.CategoryID,
+		Tags:       mongoModel.Tags,
+		CreatedAt:  mongoModel.CreatedAt,
+		UpdatedAt:  mongoModel.UpdatedAt,
+		DeletedAt:  mongoModel.DeletedAt,
+		Version:    mongoModel.Version,
+		UserID:     mongoModel.UserID,
+	}
+}
+
 func NewBookMongoDataSource(db *mongo.Database) repositories.BookRepository {
 	return &bookMongoDataSource{db: db}
 }
@@ -0,0 +1,104 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bookMongoIDsLock is the lock MigrateBookMongoIDs holds while it runs.
+const bookMongoIDsLock = "book_ids"
+
+// bookMongoLegacyIDFilter matches the books still keyed by a legacy id.
+var bookMongoLegacyIDFilter = bson.M{"_id": bson.M{"$not": bson.M{"$type": "number"}}}
+
+// MigrateBookMongoIDs moves the books collection to counter based ids. It
+// seeds the counter with the highest numeric id in use and re-keys every
+// document whose id is not numeric, keeping the old id in legacy_id. It holds
+// a lock while it runs, and the unique index on legacy_id refuses a second
+// copy of a book, so running it again, concurrently or after it was
+// interrupted, is safe.
+func MigrateBookMongoIDs(ctx context.Context, db *mongo.Database, logger *slog.Logger) error {
+	return withMongoLock(ctx, db, bookMongoIDsLock, logger, func() error {
+		books := db.Collection("books")
+		if err := createMongoIndex(ctx, books, bookMongoLegacyIDIndex); err != nil {
+			return fmt.Errorf("create index %s: %v", bookMongoLegacyIDIndex.Name, err)
+		}
+		maxID, err := maxNumericMongoID(ctx, books)
+		if err != nil {
+			return err
+		}
+		if err := seedMongoSequence(ctx, db, bookMongoSequence, maxID); err != nil {
+			return err
+		}
+
+		cur, err := books.Find(ctx, bookMongoLegacyIDFilter)
+		if err != nil {
+			return err
+		}
+		defer cur.Close(ctx)
+		for cur.Next(ctx) {
+			var doc bson.M
+			if err := cur.Decode(&doc); err != nil {
+				return err
+			}
+			legacyID := doc["_id"]
+			id, err := nextMongoSequence(ctx, db, bookMongoSequence)
+			if err != nil {
+				return err
+			}
+			doc["_id"] = id
+			doc["legacy_id"] = legacyID
+			_, err = books.InsertOne(ctx, doc)
+			switch {
+			case err == nil:
+				logger.InfoContext(ctx, "re-keyed book", "legacy_id", fmt.Sprint(legacyID), "book_id", id)
+			case mongo.IsDuplicateKeyError(err):
+				// An interrupted run inserted the re-keyed copy already, the
+				// number just taken is left unused.
+			default:
+				return err
+			}
+			if _, err := books.DeleteOne(ctx, bson.M{"_id": legacyID}); err != nil {
+				return err
+			}
+		}
+		return cur.Err()
+	})
+}
+
+// HasLegacyBookMongoIDs tells whether books are still keyed by a legacy id,
+// waiting for MigrateBookMongoIDs.
+func HasLegacyBookMongoIDs(ctx context.Context, db *mongo.Database) (bool, error) {
+	count, err := db.Collection("books").CountDocuments(ctx, bookMongoLegacyIDFilter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func maxNumericMongoID(ctx context.Context, collection *mongo.Collection) (uint, error) {
+	res := collection.FindOne(
+		ctx,
+		bson.M{"_id": bson.M{"$type": "number"}},
+		options.FindOne().SetSort(bson.M{"_id": -1}).SetProjection(bson.M{"_id": 1}),
+	)
+	if res.Err() == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	var doc bson.M
+	if err := res.Decode(&doc); err != nil {
+		return 0, err
+	}
+	switch id := doc["_id"].(type) {
+	case int32:
+		return uint(id), nil
+	case int64:
+		return uint(id), nil
+	case float64:
+		return uint(id), nil
+	default:
+		return 0, fmt.Errorf("unexpected book id type %T", id)
+	}
+}
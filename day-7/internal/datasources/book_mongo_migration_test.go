@@ -0,0 +1,164 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// The Mongo tests run against the mock deployment of mtest: every command
+// the code sends is answered by the next scripted response, in order.
+
+var (
+	mongoOK           = mtest.CreateSuccessResponse()
+	mongoDuplicateKey = mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"})
+)
+
+func mongoCursor(docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "test.books", mtest.FirstBatch, docs...)
+}
+
+func mongoCounterValue(seq int64) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: "books"}, {Key: "seq", Value: seq}}})
+}
+
+func TestMigrateBookMongoIDs(t *testing.T) {
+	testCases := []struct {
+		name            string
+		responses       []bson.D
+		expectedSeed    int64
+		expectedInserts []bson.M
+		expectedDeletes []interface{}
+	}{
+		{
+			name: "Test migrate should seed the counter with the highest numeric id and re-key legacy books",
+			responses: []bson.D{
+				mongoOK, // lock
+				mongoOK, // legacy_id index
+				mongoCursor(bson.D{{Key: "_id", Value: int64(7)}}),
+				mongoOK, // seed
+				mongoCursor(
+					bson.D{{Key: "_id", Value: "abc"}, {Key: "title", Value: "title"}},
+					bson.D{{Key: "_id", Value: "def"}, {Key: "title", Value: "title2"}},
+				),
+				mongoCounterValue(8), mongoOK, mongoOK,
+				mongoCounterValue(9), mongoOK, mongoOK,
+				mongoOK, // unlock
+			},
+			expectedSeed: 7,
+			expectedInserts: []bson.M{
+				{"_id": int64(8), "legacy_id": "abc", "title": "title"},
+				{"_id": int64(9), "legacy_id": "def", "title": "title2"},
+			},
+			expectedDeletes: []interface{}{"abc", "def", "book_ids"},
+		},
+		{
+			name: "Test migrate resuming an interrupted run should drop the legacy book already re-keyed",
+			responses: []bson.D{
+				mongoOK,
+				mongoOK,
+				mongoCursor(bson.D{{Key: "_id", Value: int64(8)}}),
+				mongoOK,
+				mongoCursor(bson.D{{Key: "_id", Value: "abc"}, {Key: "title", Value: "title"}}),
+				mongoCounterValue(9), mongoDuplicateKey, mongoOK,
+				mongoOK,
+			},
+			expectedSeed:    8,
+			expectedInserts: []bson.M{{"_id": int64(9), "legacy_id": "abc", "title": "title"}},
+			expectedDeletes: []interface{}{"abc", "book_ids"},
+		},
+		{
+			name: "Test migrate of an empty collection should seed the counter with zero",
+			responses: []bson.D{
+				mongoOK,
+				mongoOK,
+				mongoCursor(),
+				mongoOK,
+				mongoCursor(),
+				mongoOK,
+			},
+			expectedSeed:    0,
+			expectedDeletes: []interface{}{"book_ids"},
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	for _, tc := range testCases {
+		mt.Run(tc.name, func(mt *mtest.T) {
+			// Setup
+			mt.AddMockResponses(tc.responses...)
+
+			// Act
+			err := datasources.MigrateBookMongoIDs(context.TODO(), mt.DB, logger.Discard())
+
+			// Assert
+			assert.NoError(mt, err)
+			var seeds []int64
+			var inserts []bson.M
+			var deletes []interface{}
+			for _, event := range mt.GetAllStartedEvents() {
+				switch event.CommandName {
+				case "update":
+					var command struct {
+						Update  string
+						Updates []struct{ U bson.M }
+					}
+					assert.NoError(mt, bson.Unmarshal(event.Command, &command))
+					if command.Update == "counters" {
+						seeds = append(seeds, command.Updates[0].U["$max"].(bson.M)["seq"].(int64))
+					}
+				case "insert":
+					var command struct{ Documents []bson.M }
+					assert.NoError(mt, bson.Unmarshal(event.Command, &command))
+					inserts = append(inserts, command.Documents...)
+				case "delete":
+					var command struct{ Deletes []struct{ Q bson.M } }
+					assert.NoError(mt, bson.Unmarshal(event.Command, &command))
+					deletes = append(deletes, command.Deletes[0].Q["_id"])
+				case "createIndexes":
+					var command struct {
+						Indexes []struct {
+							Name   string
+							Unique bool
+							Sparse bool
+						}
+					}
+					assert.NoError(mt, bson.Unmarshal(event.Command, &command))
+					assert.Equal(mt, "legacy_id", command.Indexes[0].Name)
+					assert.True(mt, command.Indexes[0].Unique)
+					assert.True(mt, command.Indexes[0].Sparse)
+				}
+			}
+			assert.Equal(mt, []int64{tc.expectedSeed}, seeds)
+			assert.Equal(mt, tc.expectedInserts, inserts)
+			assert.Equal(mt, tc.expectedDeletes, deletes)
+		})
+	}
+}
+
+func TestMigrateBookMongoIDsLocked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	mt.Run("Test migrate while another run holds the lock should wait for it", func(mt *mtest.T) {
+		// Setup
+		mt.AddMockResponses(mongoDuplicateKey)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Act
+		err := datasources.MigrateBookMongoIDs(ctx, mt.DB, logger.Discard())
+
+		// Assert
+		assert.ErrorIs(mt, err, context.DeadlineExceeded)
+		for _, event := range mt.GetAllStartedEvents() {
+			assert.Equal(mt, "update", event.CommandName)
+		}
+	})
+}
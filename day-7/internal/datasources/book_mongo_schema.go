@@ -0,0 +1,55 @@
+package datasources
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// mongoIntegerTypes are the BSON types a Go uint is stored as.
+var mongoIntegerTypes = bson.A{"int", "long"}
+
+// bookMongoLegacyIDIndex keeps MigrateBookMongoIDs from re-keying a book
+// twice. Only re-keyed books have a legacy_id, hence sparse.
+var bookMongoLegacyIDIndex = MongoIndex{
+	Name:   "legacy_id",
+	Keys:   bson.D{{Key: "legacy_id", Value: 1}},
+	Unique: true,
+	Sparse: true,
+}
+
+// bookMongoSchema backs the queries of bookMongoDataSource with indexes and
+// holds BookMongoModel to its shape. Soft deleted books are filtered out by
+// almost every query, hence deleted_at closing most indexes.
+var bookMongoSchema = MongoCollectionSchema{
+	Collection: "books",
+	Indexes: []MongoIndex{
+		{Name: "user_id_deleted_at", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		{Name: "isbn_deleted_at", Keys: bson.D{{Key: "isbn", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		{Name: "category_id_deleted_at", Keys: bson.D{{Key: "category_id", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		{Name: "tags_deleted_at", Keys: bson.D{{Key: "tags", Value: 1}, {Key: "deleted_at", Value: 1}}},
+		{Name: "deleted_at", Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		bookMongoLegacyIDIndex,
+	},
+	Validator: bson.D{{Key: "$jsonSchema", Value: bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: bson.A{"_id", "title", "isbn", "writer", "created_at", "updated_at", "user_id"}},
+		{Key: "properties", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "bsonType", Value: mongoIntegerTypes}, {Key: "minimum", Value: 1}}},
+			{Key: "title", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "isbn", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "writer", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			{Key: "category_id", Value: bson.D{{Key: "bsonType", Value: mongoIntegerTypes}, {Key: "minimum", Value: 0}}},
+			{Key: "tags", Value: bson.D{
+				{Key: "bsonType", Value: "array"},
+				{Key: "items", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+			}},
+			{Key: "created_at", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+			{Key: "updated_at", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+			{Key: "deleted_at", Value: bson.D{{Key: "bsonType", Value: bson.A{"date", "null"}}}},
+			// Books written before versioning have none, see mongoVersionFilter.
+			{Key: "version", Value: bson.D{{Key: "bsonType", Value: mongoIntegerTypes}, {Key: "minimum", Value: 0}}},
+			{Key: "user_id", Value: bson.D{{Key: "bsonType", Value: mongoIntegerTypes}}},
+		}},
+	}}},
+}
+
+// MongoSchemas declares the collections of the Mongo book store kept in shape
+// by ApplyMongoSchemas.
+var MongoSchemas = []MongoCollectionSchema{bookMongoSchema}
@@ -0,0 +1,61 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestDeleteBookMongoWithoutVersion(t *testing.T) {
+	// A book stored before versioning, without a version field.
+	legacyBook := bson.D{{Key: "_id", Value: int64(1)}, {Key: "title", Value: "title"}, {Key: "user_id", Value: int64(12)}}
+	testCases := []struct {
+		name        string
+		version     uint
+		responses   []bson.D
+		expectedErr error
+	}{
+		{
+			name:      "Test delete of a book without version at version 0 should delete it",
+			version:   0,
+			responses: []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})},
+		},
+		{
+			name:    "Test delete of a book without version at another version should return err version conflict",
+			version: 3,
+			responses: []bson.D{
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+				mongoCursor(legacyBook),
+			},
+			expectedErr: repositories.ErrVersionConflict{},
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	for _, tc := range testCases {
+		mt.Run(tc.name, func(mt *mtest.T) {
+			// Setup
+			mt.AddMockResponses(tc.responses...)
+			ds := datasources.NewBookMongoDataSource(mt.DB)
+
+			// Act
+			err := ds.DeleteByID(context.TODO(), 1, tc.version)
+
+			// Assert
+			assert.Equal(mt, tc.expectedErr, err)
+			var command struct{ Updates []struct{ Q bson.M } }
+			assert.NoError(mt, bson.Unmarshal(mt.GetStartedEvent().Command, &command))
+			if tc.version == 0 {
+				assert.Equal(mt, bson.M{"$in": bson.A{int32(0), nil}}, command.Updates[0].Q["version"])
+			} else {
+				assert.Equal(mt, int64(tc.version), command.Updates[0].Q["version"])
+			}
+		})
+	}
+}
@@ -0,0 +1,642 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	gormModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/migrations"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// bookRepositories are the implementations every test of this file runs
+// against, each with a fresh, empty store.
+var bookRepositories = []struct {
+	name string
+	new  func(t *testing.T) repositories.BookRepository
+}{
+	{
+		name: "memory",
+		new: func(t *testing.T) repositories.BookRepository {
+			return datasources.NewBookInMemoryDataSource()
+		},
+	},
+	{
+		name: "memory-persistent",
+		new: func(t *testing.T) repositories.BookRepository {
+			ds, err := datasources.OpenBookInMemoryDataSource(t.TempDir(), logger.Discard())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = ds.Close() })
+			return ds
+		},
+	},
+	{
+		name: "gorm",
+		new: func(t *testing.T) repositories.BookRepository {
+			db := openTestGormDB(t)
+			// The owners of the books of the tests.
+			for _, id := range []uint{12, 15} {
+				user := &gormModels.UserGormModel{Email: fmt.Sprintf("user%d@mail.com", id), Version: 1}
+				user.ID = id
+				if err := db.Create(user).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			return datasources.NewBookGormDataSource(db)
+		},
+	},
+}
+
+// eachBookRepository runs test as a subtest named name for every
+// implementation in bookRepositories.
+func eachBookRepository(t *testing.T, name string, test func(t *testing.T, ds repositories.BookRepository)) {
+	for _, repository := range bookRepositories {
+		newRepository := repository.new
+		t.Run(repository.name+"/"+name, func(t *testing.T) {
+			test(t, newRepository(t))
+		})
+	}
+}
+
+// openTestGormDB returns an empty SQLite database migrated like the app's.
+func openTestGormDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	migrator, err := migrations.NewMigrator(db, migrations.Files, logger.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.TODO(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCreateBook(t *testing.T) {
+	testCases := []struct {
+		name          string
+		book          *models.Book
+		expectedCount int
+	}{
+		{
+			name: "Test Create book should add book to memory",
+			book: &models.Book{
+				Title:  "title",
+				Writer: "writer",
+				UserID: 12,
+				Isbn:   "isbn",
+			},
+			expectedCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Act
+			_, err := ds.Create(context.TODO(), tc.book)
+			all, err := ds.FindAll(context.TODO(), models.BookFilter{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCount, len(all))
+		})
+	}
+}
+
+func TestFindAllBook(t *testing.T) {
+	testCases := []struct {
+		name     string
+		books    []*models.Book
+		expected []*models.Book
+	}{
+		{
+			name:     "Test FindAll with empty books",
+			books:    []*models.Book{},
+			expected: []*models.Book{},
+		},
+		{
+			name: "Test FindAll with empty books",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+			expected: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range tc.books {
+				_, _ = ds.Create(context.TODO(), b)
+			}
+
+			// Act
+			result, _ := ds.FindAll(context.TODO(), models.BookFilter{})
+			assert.Equal(t, len(tc.expected), len(result))
+			for i, r := range result {
+				expectedBook := tc.books[i]
+				assert.Equal(t, expectedBook.Title, r.Title)
+				assert.Equal(t, expectedBook.Writer, r.Writer)
+				assert.Equal(t, expectedBook.Isbn, r.Isbn)
+				assert.Equal(t, expectedBook.UserID, r.UserID)
+			}
+		})
+	}
+}
+
+func TestDeleteByID(t *testing.T) {
+	testCases := []struct {
+		name          string
+		books         []*models.Book
+		id            uint
+		version       uint
+		expected      error
+		expectedCount int
+	}{
+		{
+			name:          "Test delete when id not found should return err record not found",
+			books:         []*models.Book{},
+			id:            9,
+			version:       1,
+			expected:      repositories.ErrRecordNotFound{},
+			expectedCount: 0,
+		},
+		{
+			name: "Test delete when id found should return nil",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+				{
+					Title:  "title2",
+					Writer: "writer2",
+					UserID: 12,
+					Isbn:   "isbn2",
+				},
+			},
+			id:            1,
+			version:       1,
+			expected:      nil,
+			expectedCount: 1,
+		},
+		{
+			name: "Test delete when version is stale should return err version conflict",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+			id:            1,
+			version:       2,
+			expected:      repositories.ErrVersionConflict{},
+			expectedCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range tc.books {
+				_, _ = ds.Create(context.TODO(), b)
+			}
+
+			// Act
+			result := ds.DeleteByID(context.TODO(), tc.id, tc.version)
+			all, _ := ds.FindAll(context.TODO(), models.BookFilter{})
+
+			// Assert
+			if tc.expected != nil {
+				assert.EqualError(t, result, tc.expected.Error())
+			} else {
+				assert.NoError(t, result)
+			}
+			assert.Equal(t, tc.expectedCount, len(all))
+		})
+	}
+}
+
+func TestFindBookByID(t *testing.T) {
+	testCases := []struct {
+		name         string
+		books        []*models.Book
+		id           uint
+		expectedErr  error
+		expectedBook *models.Book
+	}{
+		{
+			name:         "Test findby id when id not found should return err record not found",
+			books:        []*models.Book{},
+			id:           9,
+			expectedErr:  repositories.ErrRecordNotFound{},
+			expectedBook: nil,
+		},
+		{
+			name: "Test find bt id when id found should return expected book",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+			id:          1,
+			expectedErr: nil,
+			expectedBook: &models.Book{
+				Title:  "title",
+				Writer: "writer",
+				UserID: 12,
+				Isbn:   "isbn",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range tc.books {
+				_, _ = ds.Create(context.TODO(), b)
+			}
+
+			// Act
+			result, err := ds.FindByID(context.TODO(), tc.id)
+
+			// Assert
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr.Error(), err.Error())
+			}
+			if tc.expectedBook != nil {
+				assert.Equal(t, tc.expectedBook.Title, result.Title)
+				assert.Equal(t, tc.expectedBook.Writer, result.Writer)
+				assert.Equal(t, tc.expectedBook.Isbn, result.Isbn)
+				assert.Equal(t, tc.expectedBook.UserID, result.UserID)
+			}
+		})
+	}
+}
+
+func TestUpdateeBook(t *testing.T) {
+	testCases := []struct {
+		name         string
+		books        []*models.Book
+		updateBook   *models.Book
+		expectedErr  error
+		expectedBook *models.Book
+	}{
+		{
+			name:  "Test update when id not found should return err record not found",
+			books: []*models.Book{},
+			updateBook: &models.Book{
+				Title:  "title",
+				Writer: "writer",
+				UserID: 12,
+				Isbn:   "isbn",
+			},
+			expectedErr:  repositories.ErrRecordNotFound{},
+			expectedBook: nil,
+		},
+		{
+			name: "Test delete when id found should return nil",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+				{
+					Title:  "title2",
+					Writer: "writer2",
+					UserID: 15,
+					Isbn:   "isbn2",
+				},
+			},
+			updateBook: &models.Book{
+				Title:   "title_new",
+				Writer:  "writer_new",
+				ID:      2,
+				Isbn:    "isbn_new",
+				Version: 1,
+			},
+			expectedBook: &models.Book{
+				Title:   "title_new",
+				Writer:  "writer_new",
+				UserID:  15,
+				Isbn:    "isbn_new",
+				Version: 2,
+			},
+		},
+		{
+			name: "Test update when version is stale should return err version conflict",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+			updateBook: &models.Book{
+				Title:   "title_new",
+				Writer:  "writer_new",
+				ID:      1,
+				Isbn:    "isbn_new",
+				Version: 3,
+			},
+			expectedErr: repositories.ErrVersionConflict{},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range tc.books {
+				_, _ = ds.Create(context.TODO(), b)
+			}
+
+			// Act
+			result, err := ds.Update(context.TODO(), tc.updateBook, models.BookFields)
+
+			// Assert
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr.Error(), err.Error())
+			}
+			if tc.expectedBook != nil {
+				assert.Equal(t, tc.expectedBook.Title, result.Title)
+				assert.Equal(t, tc.expectedBook.Writer, result.Writer)
+				assert.Equal(t, tc.expectedBook.Isbn, result.Isbn)
+				assert.Equal(t, fmt.Sprintf("%d", tc.expectedBook.UserID), fmt.Sprintf("%d", result.UserID))
+				assert.Equal(t, tc.expectedBook.Version, result.Version)
+			}
+		})
+	}
+}
+
+func TestExistsBookByIsbn(t *testing.T) {
+	testCases := []struct {
+		name     string
+		books    []*models.Book
+		isbn     string
+		expected bool
+	}{
+		{
+			name:     "Test exists by isbn when isbn not found should return false",
+			books:    []*models.Book{},
+			isbn:     "isbn",
+			expected: false,
+		},
+		{
+			name: "Test exists by isbn when isbn found should return true",
+			books: []*models.Book{
+				{
+					Title:  "title",
+					Writer: "writer",
+					UserID: 12,
+					Isbn:   "isbn",
+				},
+			},
+			isbn:     "isbn",
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range tc.books {
+				_, _ = ds.Create(context.TODO(), b)
+			}
+
+			// Act
+			result, err := ds.ExistsByIsbn(context.TODO(), tc.isbn)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestFindAllBookWithFilter(t *testing.T) {
+	books := []*models.Book{
+		{Title: "Laskar Pelangi", Writer: "Andrea Hirata", UserID: 12, Isbn: "isbn1"},
+		{Title: "Sang Pemimpi", Writer: "Andrea Hirata", UserID: 15, Isbn: "isbn2"},
+		{Title: "Bumi Manusia", Writer: "Pramoedya Ananta Toer", UserID: 12, Isbn: "isbn3"},
+	}
+	testCases := []struct {
+		name           string
+		filter         models.BookFilter
+		expectedTitles []string
+	}{
+		{
+			name:           "Test FindAll with empty filter should return all books",
+			filter:         models.BookFilter{},
+			expectedTitles: []string{"Laskar Pelangi", "Sang Pemimpi", "Bumi Manusia"},
+		},
+		{
+			name:           "Test FindAll with writer and user filter should return matching books",
+			filter:         models.BookFilter{Writer: "hirata", UserID: 12},
+			expectedTitles: []string{"Laskar Pelangi"},
+		},
+		{
+			name:           "Test FindAll with isbn filter should return exact match",
+			filter:         models.BookFilter{Isbn: "isbn3"},
+			expectedTitles: []string{"Bumi Manusia"},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range books {
+				book := *b
+				_, _ = ds.Create(context.TODO(), &book)
+			}
+
+			// Act
+			result, err := ds.FindAll(context.TODO(), tc.filter)
+			it, itErr := ds.Iterate(context.TODO(), tc.filter)
+			iterated := make([]string, 0)
+			for it.Next(context.TODO()) {
+				iterated = append(iterated, it.Book().Title)
+			}
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, itErr)
+			assert.NoError(t, it.Err())
+			assert.NoError(t, it.Close(context.TODO()))
+			titles := make([]string, 0)
+			for _, r := range result {
+				titles = append(titles, r.Title)
+			}
+			assert.Equal(t, tc.expectedTitles, titles)
+			assert.Equal(t, tc.expectedTitles, iterated)
+		})
+	}
+}
+
+func TestFindAllBookWithTagsAndCategories(t *testing.T) {
+	books := []*models.Book{
+		{Title: "Laskar Pelangi", CategoryID: 1, Tags: []string{"novel", "belitung"}, UserID: 12},
+		{Title: "Sang Pemimpi", CategoryID: 2, Tags: []string{"novel"}, UserID: 12},
+		{Title: "Bumi Manusia", CategoryID: 3, UserID: 12},
+	}
+	testCases := []struct {
+		name           string
+		filter         models.BookFilter
+		expectedTitles []string
+	}{
+		{
+			name:           "Test FindAll with a tag should return books having the tag",
+			filter:         models.BookFilter{Tags: []string{"novel"}},
+			expectedTitles: []string{"Laskar Pelangi", "Sang Pemimpi"},
+		},
+		{
+			name:           "Test FindAll with many tags should return books having every tag",
+			filter:         models.BookFilter{Tags: []string{"novel", "belitung"}},
+			expectedTitles: []string{"Laskar Pelangi"},
+		},
+		{
+			name:           "Test FindAll with categories should return books in any of the categories",
+			filter:         models.BookFilter{CategoryIDs: []uint{2, 3}},
+			expectedTitles: []string{"Sang Pemimpi", "Bumi Manusia"},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachBookRepository(t, tc.name, func(t *testing.T, ds repositories.BookRepository) {
+			// Setup
+			for _, b := range books {
+				book := *b
+				_, _ = ds.Create(context.TODO(), &book)
+			}
+
+			// Act
+			result, err := ds.FindAll(context.TODO(), tc.filter)
+
+			// Assert
+			assert.NoError(t, err)
+			titles := make([]string, 0)
+			for _, r := range result {
+				titles = append(titles, r.Title)
+			}
+			assert.Equal(t, tc.expectedTitles, titles)
+		})
+	}
+}
+
+func TestSoftDeleteRestoreAndPurgeBook(t *testing.T) {
+	eachBookRepository(t, "Test soft deleted books should be restorable until purged", func(t *testing.T, ds repositories.BookRepository) {
+		// Setup
+		book, _ := ds.Create(context.TODO(), &models.Book{Title: "title", Isbn: "isbn", UserID: 12})
+		other, _ := ds.Create(context.TODO(), &models.Book{Title: "title2", Isbn: "isbn2", UserID: 12})
+
+		// Act & Assert soft delete
+		assert.NoError(t, ds.DeleteByID(context.TODO(), book.ID, book.Version))
+		_, err := ds.FindByID(context.TODO(), book.ID)
+		assert.EqualError(t, err, repositories.ErrRecordNotFound{}.Error())
+		exists, _ := ds.ExistsByIsbn(context.TODO(), "isbn")
+		assert.False(t, exists)
+		deleted, _ := ds.FindDeleted(context.TODO(), 12)
+		assert.Equal(t, 1, len(deleted))
+		assert.NotNil(t, deleted[0].DeletedAt)
+		assert.EqualError(t, ds.DeleteByID(context.TODO(), book.ID, book.Version), repositories.ErrRecordNotFound{}.Error())
+
+		// Act & Assert restore
+		restored, err := ds.Restore(context.TODO(), book.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		_, err = ds.Restore(context.TODO(), book.ID)
+		assert.EqualError(t, err, repositories.ErrRecordNotFound{}.Error())
+
+		// Act & Assert purge
+		assert.NoError(t, ds.DeleteByID(context.TODO(), other.ID, other.Version))
+		purged, err := ds.PurgeDeletedBefore(context.TODO(), time.Now().UTC().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+		purged, err = ds.PurgeDeletedBefore(context.TODO(), time.Now().UTC().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		_, err = ds.FindDeletedByID(context.TODO(), other.ID)
+		assert.EqualError(t, err, repositories.ErrRecordNotFound{}.Error())
+		all, _ := ds.FindAll(context.TODO(), models.BookFilter{})
+		assert.Equal(t, 1, len(all))
+	})
+}
+
+func TestUpdateBookWithFieldMask(t *testing.T) {
+	eachBookRepository(t, "Test update should only change the fields of the mask", func(t *testing.T, ds repositories.BookRepository) {
+		// Setup
+		book, _ := ds.Create(context.TODO(), &models.Book{
+			Title:      "title",
+			Writer:     "writer",
+			Isbn:       "isbn",
+			CategoryID: 3,
+			Tags:       []string{"go"},
+			UserID:     12,
+		})
+		createdAt := book.CreatedAt
+
+		// Act
+		result, err := ds.Update(context.TODO(), &models.Book{
+			ID:      book.ID,
+			Title:   "title_new",
+			Writer:  "ignored",
+			Version: 1,
+		}, []string{models.BookFieldTitle, models.BookFieldTags})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "title_new", result.Title)
+		assert.Equal(t, "writer", result.Writer)
+		assert.Equal(t, "isbn", result.Isbn)
+		assert.Equal(t, uint(3), result.CategoryID)
+		assert.Nil(t, result.Tags)
+		assert.Equal(t, createdAt, result.CreatedAt)
+		assert.Equal(t, uint(2), result.Version)
+
+		// Act
+		_, err = ds.Update(context.TODO(), &models.Book{ID: book.ID, Version: 2}, []string{"user_id"})
+
+		// Assert
+		assert.EqualError(t, err, `unknown book field "user_id"`)
+	})
+}
@@ -0,0 +1,103 @@
+package datasources
+
+import (
+	gormModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookRevisionGormDataSource struct {
+	db *gorm.DB
+}
+
+// Create implements repositories.BookRevisionRepository
+func (ds *BookRevisionGormDataSource) Create(ctx context.Context, revision *models.BookRevision) (*models.BookRevision, error) {
+	snapshot, err := json.Marshal(revision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	revisionData := &gormModels.BookRevisionGormModel{
+		BookID:    revision.BookID,
+		Action:    revision.Action,
+		ActorID:   revision.ActorID,
+		Snapshot:  string(snapshot),
+		CreatedAt: time.Now().UTC(),
+	}
+	// The unique index on (book_id, revision) makes a concurrent writer of the
+	// same revision number fail instead of silently forking the history.
+	err = ds.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last uint
+		err := tx.Model(&gormModels.BookRevisionGormModel{}).
+			Where("book_id = ?", revision.BookID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		revisionData.Revision = last + 1
+		return tx.Create(revisionData).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	revision.ID = revisionData.ID
+	revision.Revision = revisionData.Revision
+	revision.CreatedAt = revisionData.CreatedAt
+	return revision, nil
+}
+
+// FindByBookID implements repositories.BookRevisionRepository
+func (ds *BookRevisionGormDataSource) FindByBookID(ctx context.Context, bookID uint) ([]*models.BookRevision, error) {
+	var revisionData []gormModels.BookRevisionGormModel
+	results := make([]*models.BookRevision, 0)
+	err := ds.db.WithContext(ctx).Where("book_id = ?", bookID).Order("revision").Find(&revisionData).Error
+	if err != nil {
+		return results, err
+	}
+	for i := range revisionData {
+		revision, err := bookRevisionFromGormModel(&revisionData[i])
+		if err != nil {
+			return results, err
+		}
+		results = append(results, revision)
+	}
+	return results, nil
+}
+
+// FindByRevision implements repositories.BookRevisionRepository
+func (ds *BookRevisionGormDataSource) FindByRevision(ctx context.Context, bookID uint, revision uint) (*models.BookRevision, error) {
+	revisionData := &gormModels.BookRevisionGormModel{}
+	err := ds.db.WithContext(ctx).Where("book_id = ? AND revision = ?", bookID, revision).Take(revisionData).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrRecordNotFound{}
+		}
+		return nil, err
+	}
+	return bookRevisionFromGormModel(revisionData)
+}
+
+func bookRevisionFromGormModel(rd *gormModels.BookRevisionGormModel) (*models.BookRevision, error) {
+	revision := &models.BookRevision{
+		ID:        rd.ID,
+		BookID:    rd.BookID,
+		Revision:  rd.Revision,
+		Action:    rd.Action,
+		ActorID:   rd.ActorID,
+		CreatedAt: rd.CreatedAt,
+	}
+	if err := json.Unmarshal([]byte(rd.Snapshot), &revision.Snapshot); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func NewBookRevisionGormDataSource(db *gorm.DB) repositories.BookRevisionRepository {
+	return &BookRevisionGormDataSource{db: db}
+}
@@ -0,0 +1,55 @@
+package datasources
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type BookRevisionInMemoryDataSource struct {
+	revisions []*models.BookRevision
+}
+
+// Create implements repositories.BookRevisionRepository
+func (ds *BookRevisionInMemoryDataSource) Create(ctx context.Context, revision *models.BookRevision) (*models.BookRevision, error) {
+	revision.ID = uint(len(ds.revisions) + 1)
+	revision.Revision = 1
+	for _, r := range ds.revisions {
+		if r.BookID == revision.BookID && r.Revision >= revision.Revision {
+			revision.Revision = r.Revision + 1
+		}
+	}
+	revision.CreatedAt = time.Now().UTC()
+	revision.Snapshot.Tags = append([]string(nil), revision.Snapshot.Tags...)
+	stored := *revision
+	ds.revisions = append(ds.revisions, &stored)
+	return revision, nil
+}
+
+// FindByBookID implements repositories.BookRevisionRepository
+func (ds *BookRevisionInMemoryDataSource) FindByBookID(ctx context.Context, bookID uint) ([]*models.BookRevision, error) {
+	results := make([]*models.BookRevision, 0)
+	for _, r := range ds.revisions {
+		if r.BookID == bookID {
+			revision := *r
+			results = append(results, &revision)
+		}
+	}
+	return results, nil
+}
+
+// FindByRevision implements repositories.BookRevisionRepository
+func (ds *BookRevisionInMemoryDataSource) FindByRevision(ctx context.Context, bookID uint, revision uint) (*models.BookRevision, error) {
+	for _, r := range ds.revisions {
+		if r.BookID == bookID && r.Revision == revision {
+			found := *r
+			return &found, nil
+		}
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+func NewBookRevisionInMemoryDataSource() repositories.BookRevisionRepository {
+	return &BookRevisionInMemoryDataSource{}
+}
@@ -0,0 +1,114 @@
+package datasources
+
+import (
+	dsModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type bookRevisionMongoDataSource struct {
+	db *mongo.Database
+}
+
+// Create implements repositories.BookRevisionRepository
+func (ds *bookRevisionMongoDataSource) Create(ctx context.Context, revision *models.BookRevision) (*models.BookRevision, error) {
+	id, err := nextMongoSequence(ctx, ds.db, "book_revisions")
+	if err != nil {
+		return nil, err
+	}
+	number, err := nextMongoSequence(ctx, ds.db, fmt.Sprintf("book_revisions.%d", revision.BookID))
+	if err != nil {
+		return nil, err
+	}
+	revision.ID = id
+	revision.Revision = number
+	revision.CreatedAt = time.Now().UTC()
+	snapshot := revision.Snapshot
+	mongoModel := &dsModels.BookRevisionMongoModel{
+		ID:        revision.ID,
+		BookID:    revision.BookID,
+		Revision:  revision.Revision,
+		Action:    revision.Action,
+		ActorID:   revision.ActorID,
+		CreatedAt: revision.CreatedAt,
+		Snapshot: dsModels.BookMongoModel{
+			ID:         snapshot.ID,
+			Title:      snapshot.Title,
+			Isbn:       snapshot.Isbn,
+			Writer:     snapshot.Writer,
+			CategoryID: snapshot.CategoryID,
+			Tags:       snapshot.Tags,
+			CreatedAt:  snapshot.CreatedAt,
+			UpdatedAt:  snapshot.UpdatedAt,
+			DeletedAt:  snapshot.DeletedAt,
+			UserID:     snapshot.UserID,
+		},
+	}
+	if _, err := ds.collections().InsertOne(ctx, mongoModel); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// FindByBookID implements repositories.BookRevisionRepository
+func (ds *bookRevisionMongoDataSource) FindByBookID(ctx context.Context, bookID uint) ([]*models.BookRevision, error) {
+	results := make([]*models.BookRevision, 0)
+	findOptions := options.Find().SetSort(bson.D{{Key: "revision", Value: 1}})
+	cur, err := ds.collections().Find(ctx, bson.M{"book_id": bookID}, findOptions)
+	if err != nil {
+		return results, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		mongoModel := &dsModels.BookRevisionMongoModel{}
+		if err := cur.Decode(mongoModel); err != nil {
+			return results, err
+		}
+		results = append(results, bookRevisionFromMongoModel(mongoModel))
+	}
+	return results, cur.Err()
+}
+
+// FindByRevision implements repositories.BookRevisionRepository
+func (ds *bookRevisionMongoDataSource) FindByRevision(ctx context.Context, bookID uint, revision uint) (*models.BookRevision, error) {
+	res := ds.collections().FindOne(ctx, bson.M{"book_id": bookID, "revision": revision})
+	if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	if res.Err() != nil {
+		return nil, res.Err()
+	}
+	mongoModel := &dsModels.BookRevisionMongoModel{}
+	if err := res.Decode(mongoModel); err != nil {
+		return nil, err
+	}
+	return bookRevisionFromMongoModel(mongoModel), nil
+}
+
+func (ds *bookRevisionMongoDataSource) collections() *mongo.Collection {
+	return ds.db.Collection("book_revisions")
+}
+
+func bookRevisionFromMongoModel(mongoModel *dsModels.BookRevisionMongoModel) *models.BookRevision {
+	return &models.BookRevision{
+		ID:        mongoModel.ID,
+		BookID:    mongoModel.BookID,
+		Revision:  mongoModel.Revision,
+		Action:    mongoModel.Action,
+		ActorID:   mongoModel.ActorID,
+		CreatedAt: mongoModel.CreatedAt,
+		Snapshot:  *bookFromMongoModel(&mongoModel.Snapshot),
+	}
+}
+
+func NewBookRevisionMongoDataSource(db *mongo.Database) repositories.BookRevisionRepository {
+	return &bookRevisionMongoDataSource{db: db}
+}
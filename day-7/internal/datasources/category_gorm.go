@@ -0,0 +1,104 @@
+package datasources
+
+import (
+	gormModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CategoryGormDataSource struct {
+	db *gorm.DB
+}
+
+// Create implements repositories.CategoryRepository
+func (ds *CategoryGormDataSource) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	utcNow := time.Now().UTC()
+	categoryData := &gormModels.CategoryGormModel{
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		CreatedAt: utcNow,
+		UpdatedAt: utcNow,
+	}
+	if err := ds.db.WithContext(ctx).Create(categoryData).Error; err != nil {
+		return nil, err
+	}
+	category.ID = categoryData.ID
+	category.CreatedAt = categoryData.CreatedAt
+	category.UpdatedAt = categoryData.UpdatedAt
+	return category, nil
+}
+
+// DeleteByID implements repositories.CategoryRepository
+func (ds *CategoryGormDataSource) DeleteByID(ctx context.Context, id uint) error {
+	res := ds.db.WithContext(ctx).Delete(&gormModels.CategoryGormModel{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return repositories.ErrRecordNotFound{}
+	}
+	return nil
+}
+
+// FindAll implements repositories.CategoryRepository
+func (ds *CategoryGormDataSource) FindAll(ctx context.Context) ([]*models.Category, error) {
+	var categoryData []gormModels.CategoryGormModel
+	results := make([]*models.Category, 0)
+	if err := ds.db.WithContext(ctx).Order("id").Find(&categoryData).Error; err != nil {
+		return results, err
+	}
+	for i := range categoryData {
+		results = append(results, categoryFromGormModel(&categoryData[i]))
+	}
+	return results, nil
+}
+
+// FindByID implements repositories.CategoryRepository
+func (ds *CategoryGormDataSource) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	categoryData := &gormModels.CategoryGormModel{}
+	if err := ds.db.WithContext(ctx).Take(categoryData, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrRecordNotFound{}
+		}
+		return nil, err
+	}
+	return categoryFromGormModel(categoryData), nil
+}
+
+// Update implements repositories.CategoryRepository
+func (ds *CategoryGormDataSource) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	res := ds.db.WithContext(ctx).
+		Model(&gormModels.CategoryGormModel{}).
+		Where("id = ?", category.ID).
+		Updates(map[string]interface{}{
+			"name":       category.Name,
+			"parent_id":  category.ParentID,
+			"updated_at": time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	return ds.FindByID(ctx, category.ID)
+}
+
+func categoryFromGormModel(cd *gormModels.CategoryGormModel) *models.Category {
+	return &models.Category{
+		ID:        cd.ID,
+		Name:      cd.Name,
+		ParentID:  cd.ParentID,
+		CreatedAt: cd.CreatedAt,
+		UpdatedAt: cd.UpdatedAt,
+	}
+}
+
+func NewCategoryGormDataSource(db *gorm.DB) repositories.CategoryRepository {
+	return &CategoryGormDataSource{db: db}
+}
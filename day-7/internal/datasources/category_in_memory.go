@@ -0,0 +1,68 @@
+package datasources
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type CategoryInMemoryDataSource struct {
+	categories []*models.Category
+	lastID     uint
+}
+
+// Create implements repositories.CategoryRepository
+func (ds *CategoryInMemoryDataSource) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	ds.lastID++
+	category.ID = ds.lastID
+	category.CreatedAt = time.Now().UTC()
+	category.UpdatedAt = time.Now().UTC()
+	ds.categories = append(ds.categories, category)
+	return category, nil
+}
+
+// DeleteByID implements repositories.CategoryRepository
+func (ds *CategoryInMemoryDataSource) DeleteByID(ctx context.Context, id uint) error {
+	for i, category := range ds.categories {
+		if category.ID == id {
+			ds.categories = append(ds.categories[:i], ds.categories[i+1:]...)
+			return nil
+		}
+	}
+	return repositories.ErrRecordNotFound{}
+}
+
+// FindAll implements repositories.CategoryRepository
+func (ds *CategoryInMemoryDataSource) FindAll(ctx context.Context) ([]*models.Category, error) {
+	results := make([]*models.Category, len(ds.categories))
+	copy(results, ds.categories)
+	return results, nil
+}
+
+// FindByID implements repositories.CategoryRepository
+func (ds *CategoryInMemoryDataSource) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	for _, category := range ds.categories {
+		if category.ID == id {
+			return category, nil
+		}
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+// Update implements repositories.CategoryRepository
+func (ds *CategoryInMemoryDataSource) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	for i, c := range ds.categories {
+		if c.ID == category.ID {
+			category.CreatedAt = c.CreatedAt
+			category.UpdatedAt = time.Now().UTC()
+			ds.categories[i] = category
+			return category, nil
+		}
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+func NewCategoryInMemoryDataSource() repositories.CategoryRepository {
+	return &CategoryInMemoryDataSource{}
+}
@@ -0,0 +1,123 @@
+package datasources
+
+import (
+	dsModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type categoryMongoDataSource struct {
+	db *mongo.Database
+}
+
+// Create implements repositories.CategoryRepository
+func (ds *categoryMongoDataSource) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	id, err := nextMongoSequence(ctx, ds.db, "categories")
+	if err != nil {
+		return nil, err
+	}
+	utcNow := time.Now().UTC()
+	category.ID = id
+	category.CreatedAt = utcNow
+	category.UpdatedAt = utcNow
+	mongoModel := &dsModels.CategoryMongoModel{
+		ID:        category.ID,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+	if _, err := ds.collections().InsertOne(ctx, mongoModel); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteByID implements repositories.CategoryRepository
+func (ds *categoryMongoDataSource) DeleteByID(ctx context.Context, id uint) error {
+	res, err := ds.collections().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repositories.ErrRecordNotFound{}
+	}
+	return nil
+}
+
+// FindAll implements repositories.CategoryRepository
+func (ds *categoryMongoDataSource) FindAll(ctx context.Context) ([]*models.Category, error) {
+	results := make([]*models.Category, 0)
+	cur, err := ds.collections().Find(ctx, bson.M{})
+	if err != nil {
+		return results, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		mongoModel := &dsModels.CategoryMongoModel{}
+		if err := cur.Decode(mongoModel); err != nil {
+			return results, err
+		}
+		results = append(results, categoryFromMongoModel(mongoModel))
+	}
+	return results, cur.Err()
+}
+
+// FindByID implements repositories.CategoryRepository
+func (ds *categoryMongoDataSource) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	res := ds.collections().FindOne(ctx, bson.M{"_id": id})
+	if errors.Is(res.Err(), mongo.ErrNoDocuments) {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	if res.Err() != nil {
+		return nil, res.Err()
+	}
+	mongoModel := &dsModels.CategoryMongoModel{}
+	if err := res.Decode(mongoModel); err != nil {
+		return nil, err
+	}
+	return categoryFromMongoModel(mongoModel), nil
+}
+
+// Update implements repositories.CategoryRepository
+func (ds *categoryMongoDataSource) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	category.UpdatedAt = time.Now().UTC()
+	res, err := ds.collections().UpdateByID(ctx, category.ID, bson.M{
+		"$set": bson.M{
+			"name":       category.Name,
+			"parent_id":  category.ParentID,
+			"updated_at": category.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	return ds.FindByID(ctx, category.ID)
+}
+
+func (ds *categoryMongoDataSource) collections() *mongo.Collection {
+	return ds.db.Collection("categories")
+}
+
+func categoryFromMongoModel(mongoModel *dsModels.CategoryMongoModel) *models.Category {
+	return &models.Category{
+		ID:        mongoModel.ID,
+		Name:      mongoModel.Name,
+		ParentID:  mongoModel.ParentID,
+		CreatedAt: mongoModel.CreatedAt,
+		UpdatedAt: mongoModel.UpdatedAt,
+	}
+}
+
+func NewCategoryMongoDataSource(db *mongo.Database) repositories.CategoryRepository {
+	return &categoryMongoDataSource{db: db}
+}
@@ -1,8 +0,0 @@
-package datasources
-
-type ErrRecordNotFound struct {
-}
-
-func (e ErrRecordNotFound) Error() string {
-	return "record not found"
-}
@@ -0,0 +1,86 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// gormSlowQueryThreshold is the duration above which queries are logged as
+// warnings.
+const gormSlowQueryThreshold = 200 * time.Millisecond
+
+// sqlStringLiteral matches the quoted values GORM interpolates into the
+// queries it logs.
+var sqlStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+
+// gormLoggerAdapter sends the logs of GORM to a slog.Logger. Queries are
+// logged at debug level, slow ones as warnings and failed ones as errors.
+type gormLoggerAdapter struct {
+	logger *slog.Logger
+	level  gormLogger.LogLevel
+}
+
+// NewGormLogger creates a GORM logger writing to logger.
+func NewGormLogger(logger *slog.Logger) gormLogger.Interface {
+	return &gormLoggerAdapter{logger: logger, level: gormLogger.Info}
+}
+
+// LogMode implements logger.Interface
+func (l *gormLoggerAdapter) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	return &gormLoggerAdapter{logger: l.logger, level: level}
+}
+
+// Info implements logger.Interface
+func (l *gormLoggerAdapter) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn implements logger.Interface
+func (l *gormLoggerAdapter) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error implements logger.Interface
+func (l *gormLoggerAdapter) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace implements logger.Interface
+func (l *gormLoggerAdapter) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormLogger.Error:
+		level = slog.LevelError
+	case elapsed > gormSlowQueryThreshold && l.level >= gormLogger.Warn:
+		level = slog.LevelWarn
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	// String values may hold passwords, which no key based redaction could
+	// spot inside a query, so none of them is logged.
+	sql = sqlStringLiteral.ReplaceAllString(sql, "'?'")
+	attrs := []interface{}{"sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds()}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	l.logger.Log(ctx, level, "gorm query", attrs...)
+}
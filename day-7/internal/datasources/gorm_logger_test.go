@@ -0,0 +1,68 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         slog.Level
+		err           error
+		expectedLevel string
+		expectLogged  bool
+	}{
+		{
+			name:          "Test failed query should be logged as an error",
+			level:         slog.LevelInfo,
+			err:           errors.New("connection refused"),
+			expectedLevel: "ERROR",
+			expectLogged:  true,
+		},
+		{
+			name:         "Test successful query should not be logged above debug level",
+			level:        slog.LevelInfo,
+			expectLogged: false,
+		},
+		{
+			name:          "Test successful query should be logged at debug level",
+			level:         slog.LevelDebug,
+			expectedLevel: "DEBUG",
+			expectLogged:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			gormLogger := datasources.NewGormLogger(logger.New(&buf, testCase.level))
+			query := func() (string, int64) {
+				return "INSERT INTO `users` (`email`,`password`) VALUES ('user@mail.com','it''s secret')", 1
+			}
+
+			// Act
+			gormLogger.Trace(context.Background(), time.Now(), query, testCase.err)
+
+			// Assert
+			if !testCase.expectLogged {
+				assert.Empty(t, buf.String())
+				return
+			}
+			var record map[string]interface{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, testCase.expectedLevel, record["level"])
+			assert.Equal(t, "INSERT INTO `users` (`email`,`password`) VALUES ('?','?')", record["sql"])
+		})
+	}
+}
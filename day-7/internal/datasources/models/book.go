@@ -3,11 +3,15 @@ package models
 import "time"
 
 type BookMongoModel struct {
-	ID        uint      `bson:"_id"`
-	Title     string    `bson:"title"`
-	Isbn      string    `bson:"isbn"`
-	Writer    string    `bson:"writer"`
-	CreatedAt time.Time `bson:"created_at"`
-	UpdatedAt time.Time `bson:"updated_at"`
-	UserID    uint      `bson:"user_id"`
+	ID         uint       `bson:"_id"`
+	Title      string     `bson:"title"`
+	Isbn       string     `bson:"isbn"`
+	Writer     string     `bson:"writer"`
+	CategoryID uint       `bson:"category_id,omitempty"`
+	Tags       []string   `bson:"tags,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at"`
+	DeletedAt  *time.Time `bson:"deleted_at,omitempty"`
+	Version    uint       `bson:"version"`
+	UserID     uint       `bson:"user_id"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type BookFileModel struct {
+	ID         uint       `json:"id"`
+	Title      string     `json:"title"`
+	Isbn       string     `json:"isbn"`
+	Writer     string     `json:"writer"`
+	CategoryID uint       `json:"category_id,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	Version    uint       `json:"version"`
+	UserID     uint       `json:"user_id"`
+}
+
+// BookSnapshotFileModel holds every book of the in-memory store at once.
+type BookSnapshotFileModel struct {
+	LastID uint            `json:"last_id"`
+	Books  []BookFileModel `json:"books"`
+}
+
+// BookLogEntryFileModel is a line of the append log of the in-memory store:
+// either a book written as a whole, or the ids of purged books.
+type BookLogEntryFileModel struct {
+	Op   string         `json:"op"`
+	Book *BookFileModel `json:"book,omitempty"`
+	IDs  []uint         `json:"ids,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookGormModel struct {
+	ID         uint   `gorm:"primaryKey"`
+	Title      string `gorm:"size:255;not null"`
+	Isbn       string `gorm:"size:64;not null;index"`
+	Writer     string `gorm:"size:255;not null"`
+	CategoryID uint   `gorm:"not null;default:0;index"`
+	// Tags are kept in their own table, in the order they were given.
+	Tags      []BookTagGormModel `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE"`
+	UserID    uint               `gorm:"not null;index"`
+	User      UserGormModel      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Version   uint           `gorm:"not null;default:1"`
+}
+
+func (BookGormModel) TableName() string {
+	return "books"
+}
+
+type BookTagGormModel struct {
+	BookID   uint   `gorm:"primaryKey"`
+	Tag      string `gorm:"primaryKey;size:64;index"`
+	Position int    `gorm:"not null"`
+}
+
+func (BookTagGormModel) TableName() string {
+	return "book_tags"
+}
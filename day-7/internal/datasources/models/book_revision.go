@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type BookRevisionMongoModel struct {
+	ID        uint           `bson:"_id"`
+	BookID    uint           `bson:"book_id"`
+	Revision  uint           `bson:"revision"`
+	Action    string         `bson:"action"`
+	ActorID   uint           `bson:"actor_id"`
+	CreatedAt time.Time      `bson:"created_at"`
+	Snapshot  BookMongoModel `bson:"snapshot"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type BookRevisionGormModel struct {
+	ID       uint   `gorm:"primaryKey"`
+	BookID   uint   `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Revision uint   `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Action   string `gorm:"size:16;not null"`
+	ActorID  uint   `gorm:"not null"`
+	// Snapshot is the JSON encoding of the book after the change.
+	Snapshot  string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+func (BookRevisionGormModel) TableName() string {
+	return "book_revisions"
+}
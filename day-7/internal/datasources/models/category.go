@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type CategoryMongoModel struct {
+	ID        uint      `bson:"_id"`
+	Name      string    `bson:"name"`
+	ParentID  uint      `bson:"parent_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type CategoryGormModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:255;not null"`
+	ParentID  uint   `gorm:"not null;default:0;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (CategoryGormModel) TableName() string {
+	return "categories"
+}
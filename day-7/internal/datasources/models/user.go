@@ -5,8 +5,10 @@ import "gorm.io/gorm"
 type UserGormModel struct {
 	gorm.Model
 	Name     string `json:"name"`
-	Email    string `json:"email"`
+	Email    string `json:"email" gorm:"size:191;uniqueIndex:idx_users_email"`
 	Password string `json:"-"`
+	IsAdmin  bool   `json:"is_admin" gorm:"not null;default:false"`
+	Version  uint   `json:"version" gorm:"not null;default:0"`
 }
 
 func (UserGormModel) TableName() string {
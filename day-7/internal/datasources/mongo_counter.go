@@ -0,0 +1,48 @@
+package datasources
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const mongoCountersCollection = "counters"
+
+type mongoCounter struct {
+	ID  string `bson:"_id"`
+	Seq uint   `bson:"seq"`
+}
+
+// nextMongoSequence atomically increments and returns the counter called name,
+// creating it on first use.
+func nextMongoSequence(ctx context.Context, db *mongo.Database, name string) (uint, error) {
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	res := db.Collection(mongoCountersCollection).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		opts,
+	)
+	counter := &mongoCounter{}
+	if err := res.Decode(counter); err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+// seedMongoSequence raises the counter called name to at least value, so that
+// nextMongoSequence never hands out a number that is already in use. Lower
+// values leave the counter untouched.
+func seedMongoSequence(ctx context.Context, db *mongo.Database, name string, value uint) error {
+	_, err := db.Collection(mongoCountersCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": name},
+		bson.M{"$max": bson.M{"seq": value}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package datasources
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mongoLocksCollection = "locks"
+	// mongoLockExpiry is how long a lock is honoured. A holder keeping it
+	// longer is assumed to have died, so the lock can be taken over.
+	mongoLockExpiry = 15 * time.Minute
+	// mongoLockPollInterval is how often a waiting caller tries the lock again.
+	mongoLockPollInterval = time.Second
+)
+
+// withMongoLock runs fn holding the lock called name, waiting for it while
+// another process holds it. The lock is a document of the locks collection:
+// taking it upserts the document unless a live one exists, which the unique
+// _id turns into a duplicate key error.
+func withMongoLock(ctx context.Context, db *mongo.Database, name string, logger *slog.Logger, fn func() error) error {
+	locks := db.Collection(mongoLocksCollection)
+	owner := mongoLockOwner()
+	for {
+		now := time.Now().UTC()
+		_, err := locks.UpdateOne(
+			ctx,
+			bson.M{"_id": name, "expires_at": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(mongoLockExpiry)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("acquire lock %s: %v", name, err)
+		}
+		logger.InfoContext(ctx, "waiting for the mongo lock", "lock", name)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("acquire lock %s: %w", name, ctx.Err())
+		case <-time.After(mongoLockPollInterval):
+		}
+	}
+	defer func() {
+		// Release even when ctx is done, or others wait for the lock to expire.
+		_, err := locks.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": name, "owner": owner})
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to release the mongo lock", "lock", name, "error", err)
+		}
+	}()
+	return fn()
+}
+
+func mongoLockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
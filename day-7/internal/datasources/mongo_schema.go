@@ -0,0 +1,238 @@
+package datasources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Validation settings of every declared validator. Moderate validation lets
+// documents written before a validator existed be updated until they are
+// made valid, while new documents must match it.
+const (
+	mongoValidationLevel  = "moderate"
+	mongoValidationAction = "error"
+)
+
+// Kinds of MongoSchemaDrift.
+const (
+	MongoDriftMissingCollection = "missing_collection"
+	MongoDriftMissingIndex      = "missing_index"
+	MongoDriftChangedIndex      = "changed_index"
+	MongoDriftUnexpectedIndex   = "unexpected_index"
+	MongoDriftValidator         = "validator"
+)
+
+// MongoIndex is an index the app expects on a collection. A sparse index
+// leaves out the documents without its keys.
+type MongoIndex struct {
+	Name   string
+	Keys   bson.D
+	Unique bool
+	Sparse bool
+}
+
+// MongoCollectionSchema declares the indexes and the validator of a
+// collection.
+type MongoCollectionSchema struct {
+	Collection string
+	Indexes    []MongoIndex
+	Validator  bson.D
+}
+
+// MongoSchemaDrift is a difference between a MongoCollectionSchema and the
+// database. Fixed tells whether ApplyMongoSchemas resolved it.
+type MongoSchemaDrift struct {
+	Collection string
+	Kind       string
+	Name       string
+	Fixed      bool
+}
+
+func (d MongoSchemaDrift) String() string {
+	s := d.Collection + ": " + strings.ReplaceAll(d.Kind, "_", " ")
+	if d.Name != "" {
+		s += " " + d.Name
+	}
+	if d.Fixed {
+		s += " (fixed)"
+	}
+	return s
+}
+
+// CheckMongoSchemas reports how db differs from schemas without changing it.
+func CheckMongoSchemas(ctx context.Context, db *mongo.Database, schemas []MongoCollectionSchema) ([]MongoSchemaDrift, error) {
+	return syncMongoSchemas(ctx, db, schemas, false, nil)
+}
+
+// ApplyMongoSchemas creates the missing collections and indexes of schemas
+// and sets their validators, and reports every drift found. Changed and
+// unexpected indexes are only reported: rebuilding an index of a large
+// collection is left to an operator. Running it again is safe.
+func ApplyMongoSchemas(ctx context.Context, db *mongo.Database, schemas []MongoCollectionSchema, logger *slog.Logger) ([]MongoSchemaDrift, error) {
+	return syncMongoSchemas(ctx, db, schemas, true, logger)
+}
+
+func syncMongoSchemas(ctx context.Context, db *mongo.Database, schemas []MongoCollectionSchema, apply bool, logger *slog.Logger) ([]MongoSchemaDrift, error) {
+	var drifts []MongoSchemaDrift
+	for _, schema := range schemas {
+		validator, err := bson.Marshal(schema.Validator)
+		if err != nil {
+			return drifts, fmt.Errorf("%s: %v", schema.Collection, err)
+		}
+		specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": schema.Collection})
+		if err != nil {
+			return drifts, err
+		}
+
+		if len(specs) == 0 {
+			drift := MongoSchemaDrift{Collection: schema.Collection, Kind: MongoDriftMissingCollection}
+			if apply {
+				opts := options.CreateCollection().
+					SetValidator(schema.Validator).
+					SetValidationLevel(mongoValidationLevel).
+					SetValidationAction(mongoValidationAction)
+				if err := db.CreateCollection(ctx, schema.Collection, opts); err != nil {
+					return drifts, fmt.Errorf("create collection %s: %v", schema.Collection, err)
+				}
+				drift.Fixed = true
+			}
+			drifts = append(drifts, drift)
+		} else if !mongoValidatorMatches(specs[0].Options, validator) {
+			drift := MongoSchemaDrift{Collection: schema.Collection, Kind: MongoDriftValidator}
+			if apply {
+				err := db.RunCommand(ctx, bson.D{
+					{Key: "collMod", Value: schema.Collection},
+					{Key: "validator", Value: schema.Validator},
+					{Key: "validationLevel", Value: mongoValidationLevel},
+					{Key: "validationAction", Value: mongoValidationAction},
+				}).Err()
+				if err != nil {
+					return drifts, fmt.Errorf("set validator of %s: %v", schema.Collection, err)
+				}
+				drift.Fixed = true
+			}
+			drifts = append(drifts, drift)
+		}
+
+		var existing []MongoIndex
+		if len(specs) > 0 {
+			existing, err = listMongoIndexes(ctx, db.Collection(schema.Collection))
+			if err != nil {
+				return drifts, err
+			}
+		}
+		for _, drift := range DiffMongoIndexes(schema.Collection, schema.Indexes, existing) {
+			if apply && drift.Kind == MongoDriftMissingIndex {
+				index := findMongoIndex(schema.Indexes, drift.Name)
+				if err := createMongoIndex(ctx, db.Collection(schema.Collection), *index); err != nil {
+					return drifts, fmt.Errorf("create index %s of %s: %v", index.Name, schema.Collection, err)
+				}
+				drift.Fixed = true
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+	if logger != nil {
+		for _, drift := range drifts {
+			if drift.Fixed {
+				logger.InfoContext(ctx, "mongo schema updated", "drift", drift.String())
+			} else {
+				logger.WarnContext(ctx, "mongo schema drift", "drift", drift.String())
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// DiffMongoIndexes compares the declared indexes of collection with the
+// existing ones, matched by name. The _id index is never reported.
+func DiffMongoIndexes(collection string, declared []MongoIndex, existing []MongoIndex) []MongoSchemaDrift {
+	var drifts []MongoSchemaDrift
+	for _, index := range declared {
+		current := findMongoIndex(existing, index.Name)
+		switch {
+		case current == nil:
+			drifts = append(drifts, MongoSchemaDrift{Collection: collection, Kind: MongoDriftMissingIndex, Name: index.Name})
+		case current.Unique != index.Unique || current.Sparse != index.Sparse || mongoIndexKeys(current.Keys) != mongoIndexKeys(index.Keys):
+			drifts = append(drifts, MongoSchemaDrift{Collection: collection, Kind: MongoDriftChangedIndex, Name: index.Name})
+		}
+	}
+	for _, index := range existing {
+		if index.Name != "_id_" && findMongoIndex(declared, index.Name) == nil {
+			drifts = append(drifts, MongoSchemaDrift{Collection: collection, Kind: MongoDriftUnexpectedIndex, Name: index.Name})
+		}
+	}
+	return drifts
+}
+
+func listMongoIndexes(ctx context.Context, collection *mongo.Collection) ([]MongoIndex, error) {
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]MongoIndex, 0, len(specs))
+	for _, spec := range specs {
+		index := MongoIndex{
+			Name:   spec.Name,
+			Unique: spec.Unique != nil && *spec.Unique,
+			Sparse: spec.Sparse != nil && *spec.Sparse,
+		}
+		if err := bson.Unmarshal(spec.KeysDocument, &index.Keys); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// createMongoIndex creates index on collection. Creating an index that
+// already exists with the same options does nothing.
+func createMongoIndex(ctx context.Context, collection *mongo.Collection, index MongoIndex) error {
+	opts := options.Index().SetName(index.Name)
+	if index.Unique {
+		opts.SetUnique(true)
+	}
+	if index.Sparse {
+		opts.SetSparse(true)
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: index.Keys, Options: opts})
+	return err
+}
+
+func findMongoIndex(indexes []MongoIndex, name string) *MongoIndex {
+	for i := range indexes {
+		if indexes[i].Name == name {
+			return &indexes[i]
+		}
+	}
+	return nil
+}
+
+// mongoIndexKeys formats keys so that the same keys compare equal whatever
+// number type the server returns their directions in.
+func mongoIndexKeys(keys bson.D) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s:%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// mongoValidatorMatches tells whether the options of a collection hold
+// validator with the declared validation level and action.
+func mongoValidatorMatches(collectionOptions bson.Raw, validator []byte) bool {
+	current, ok := collectionOptions.Lookup("validator").DocumentOK()
+	if !ok || !bytes.Equal(current, validator) {
+		return false
+	}
+	level, _ := collectionOptions.Lookup("validationLevel").StringValueOK()
+	action, _ := collectionOptions.Lookup("validationAction").StringValueOK()
+	return level == mongoValidationLevel && action == mongoValidationAction
+}
@@ -0,0 +1,90 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	dsModels "alterra-agmc-day-7/internal/datasources/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDiffMongoIndexes(t *testing.T) {
+	declared := []datasources.MongoIndex{
+		{Name: "user_id", Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Name: "isbn", Keys: bson.D{{Key: "isbn", Value: 1}}, Unique: true},
+	}
+	testCases := []struct {
+		name     string
+		existing []datasources.MongoIndex
+		expected []string
+	}{
+		{
+			name: "Test matching indexes should report no drift whatever the number type of the keys",
+			existing: []datasources.MongoIndex{
+				{Name: "_id_", Keys: bson.D{{Key: "_id", Value: int32(1)}}},
+				{Name: "user_id", Keys: bson.D{{Key: "user_id", Value: int32(1)}}},
+				{Name: "isbn", Keys: bson.D{{Key: "isbn", Value: float64(1)}}, Unique: true},
+			},
+			expected: []string{},
+		},
+		{
+			name:     "Test missing indexes should be reported",
+			existing: []datasources.MongoIndex{{Name: "_id_", Keys: bson.D{{Key: "_id", Value: 1}}}},
+			expected: []string{"books: missing index user_id", "books: missing index isbn"},
+		},
+		{
+			name: "Test changed and unexpected indexes should be reported",
+			existing: []datasources.MongoIndex{
+				{Name: "user_id", Keys: bson.D{{Key: "user_id", Value: -1}}},
+				{Name: "isbn", Keys: bson.D{{Key: "isbn", Value: 1}}},
+				{Name: "title", Keys: bson.D{{Key: "title", Value: 1}}},
+			},
+			expected: []string{"books: changed index user_id", "books: changed index isbn", "books: unexpected index title"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			drifts := datasources.DiffMongoIndexes("books", declared, tc.existing)
+
+			// Assert
+			result := make([]string, 0, len(drifts))
+			for _, drift := range drifts {
+				result = append(result, drift.String())
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestBookMongoValidatorCoversModel(t *testing.T) {
+	// Setup
+	var schema *datasources.MongoCollectionSchema
+	for i := range datasources.MongoSchemas {
+		if datasources.MongoSchemas[i].Collection == "books" {
+			schema = &datasources.MongoSchemas[i]
+		}
+	}
+	assert.NotNil(t, schema)
+	jsonSchema := schema.Validator.Map()["$jsonSchema"].(bson.D).Map()
+
+	// Act
+	properties := make(map[string]bool)
+	for _, property := range jsonSchema["properties"].(bson.D) {
+		properties[property.Key] = true
+	}
+
+	// Assert
+	model := reflect.TypeOf(dsModels.BookMongoModel{})
+	for i := 0; i < model.NumField(); i++ {
+		field := strings.Split(model.Field(i).Tag.Get("bson"), ",")[0]
+		assert.True(t, properties[field], "the validator of books should describe %s", field)
+	}
+	for _, required := range jsonSchema["required"].(bson.A) {
+		assert.True(t, properties[required.(string)], "required field %s should be described", required)
+	}
+}
@@ -5,10 +5,23 @@ import (
 	"alterra-agmc-day-7/internal/models"
 	"alterra-agmc-day-7/internal/repositories"
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
+// Error codes of unique index violations.
+const (
+	mysqlDuplicateEntry     = 1062
+	postgresUniqueViolation = "23505"
+	sqliteConstraintUnique  = 2067
+)
+
 type UserGormDataSource struct {
 	db *gorm.DB
 }
@@ -19,46 +32,43 @@ func (ds *UserGormDataSource) Create(ctx context.Context, user *models.User) (*m
 		Name:     user.Name,
 		Email:    user.Email,
 		Password: user.Password,
+		Version:  1,
 	}
 	userData.CreatedAt = user.CreatedAt
 	userData.UpdatedAt = user.UpdatedAt
-	if err := ds.db.Create(&userData).Error; err != nil {
-		return nil, err
-	}
-	return &models.User{
-		ID:        userData.ID,
-		Password:  userData.Password,
-		Email:     userData.Email,
-		Name:      userData.Name,
-		CreatedAt: userData.CreatedAt,
-		UpdatedAt: userData.UpdatedAt,
-	}, nil
+	if err := ds.db.WithContext(ctx).Create(&userData).Error; err != nil {
+		return nil, userGormError(err)
+	}
+	return userFromGormModel(&userData), nil
 }
 
 // DeleteByID implements repositories.UserRepository
-func (ds *UserGormDataSource) DeleteByID(ctx context.Context, id uint) error {
-	return ds.db.Delete(&gormModels.UserGormModel{}, id).Error
+func (ds *UserGormDataSource) DeleteByID(ctx context.Context, id uint, version uint) error {
+	res := ds.db.WithContext(ctx).
+		Model(&gormModels.UserGormModel{}).
+		Where("id = ? AND version = ?", id, version).
+		Update("deleted_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if _, err := ds.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return repositories.ErrVersionConflict{}
+	}
+	return nil
 }
 
 // FindAll implements repositories.UserRepository
 func (ds *UserGormDataSource) FindAll(ctx context.Context) ([]*models.User, error) {
 	var userData []gormModels.UserGormModel
 	var users []*models.User
-	if err := ds.db.Find(&userData).Error; err != nil {
+	if err := ds.db.WithContext(ctx).Find(&userData).Error; err != nil {
 		return users, err
 	}
-	for _, ud := range userData {
-		users = append(
-			users,
-			&models.User{
-				ID:        ud.ID,
-				Password:  ud.Password,
-				Email:     ud.Email,
-				Name:      ud.Name,
-				CreatedAt: ud.CreatedAt,
-				UpdatedAt: ud.UpdatedAt,
-			},
-		)
+	for i := range userData {
+		users = append(users, userFromGormModel(&userData[i]))
 	}
 	return users, nil
 }
@@ -66,55 +76,119 @@ func (ds *UserGormDataSource) FindAll(ctx context.Context) ([]*models.User, erro
 // FindByEmail implements repositories.UserRepository
 func (ds *UserGormDataSource) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	ud := &gormModels.UserGormModel{}
-	if err := ds.db.Where("email = ?", email).First(ud).Error; err != nil {
-		return nil, err
+	if err := ds.db.WithContext(ctx).Where("email = ?", email).First(ud).Error; err != nil {
+		return nil, userGormError(err)
 	}
-	return &models.User{
-		ID:        ud.ID,
-		Password:  ud.Password,
-		Email:     ud.Email,
-		Name:      ud.Name,
-		CreatedAt: ud.CreatedAt,
-		UpdatedAt: ud.UpdatedAt,
-	}, nil
+	return userFromGormModel(ud), nil
 }
 
 // FindByID implements repositories.UserRepository
 func (ds *UserGormDataSource) FindByID(ctx context.Context, id uint) (*models.User, error) {
 	ud := &gormModels.UserGormModel{}
-	if err := ds.db.First(ud, id).Error; err != nil {
-		return nil, err
+	if err := ds.db.WithContext(ctx).First(ud, id).Error; err != nil {
+		return nil, userGormError(err)
 	}
-	return &models.User{
-		ID:        ud.ID,
-		Password:  ud.Password,
-		Email:     ud.Email,
-		Name:      ud.Name,
-		CreatedAt: ud.CreatedAt,
-		UpdatedAt: ud.UpdatedAt,
-	}, nil
+	return userFromGormModel(ud), nil
 }
 
 // Update implements repositories.UserRepository
-func (ds *UserGormDataSource) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	ud := &gormModels.UserGormModel{
-		Name:     user.Name,
-		Email:    user.Email,
-		Password: user.Password,
+func (ds *UserGormDataSource) Update(ctx context.Context, user *models.User, fields []string) (*models.User, error) {
+	values := map[string]interface{}{
+		"updated_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
 	}
-	ud.ID = user.ID
-	ud.UpdatedAt = user.UpdatedAt
-	if err := ds.db.Model(ud).Updates(ud).Error; err != nil {
-		return nil, err
+	for _, field := range fields {
+		switch field {
+		case models.UserFieldName:
+			values["name"] = user.Name
+		case models.UserFieldEmail:
+			values["email"] = user.Email
+		case models.UserFieldPassword:
+			values["password"] = user.Password
+		default:
+			return nil, fmt.Errorf("unknown user field %q", field)
+		}
 	}
-	return &models.User{
+	res := ds.db.WithContext(ctx).Model(&gormModels.UserGormModel{}).
+		Where("id = ? AND version = ?", user.ID, user.Version).
+		Updates(values)
+	if res.Error != nil {
+		return nil, userGormError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		if _, err := ds.FindByID(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		return nil, repositories.ErrVersionConflict{}
+	}
+	return ds.FindByID(ctx, user.ID)
+}
+
+// FindDeletedByID implements repositories.UserRepository
+func (ds *UserGormDataSource) FindDeletedByID(ctx context.Context, id uint) (*models.User, error) {
+	ud := &gormModels.UserGormModel{}
+	if err := ds.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(ud, id).Error; err != nil {
+		return nil, userGormError(err)
+	}
+	return userFromGormModel(ud), nil
+}
+
+// Restore implements repositories.UserRepository
+func (ds *UserGormDataSource) Restore(ctx context.Context, id uint) (*models.User, error) {
+	res := ds.db.WithContext(ctx).Unscoped().
+		Model(&gormModels.UserGormModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	return ds.FindByID(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.UserRepository
+func (ds *UserGormDataSource) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := ds.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&gormModels.UserGormModel{})
+	return res.RowsAffected, res.Error
+}
+
+// userGormError reports unique index violations, which can only come from
+// the email index, as repositories.ErrDuplicateKey.
+func userGormError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repositories.ErrRecordNotFound{}
+	}
+	var mysqlErr *mysql.MySQLError
+	var postgresErr *pgconn.PgError
+	var sqliteErr *sqlite.Error
+	if (errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry) ||
+		(errors.As(err, &postgresErr) && postgresErr.Code == postgresUniqueViolation) ||
+		(errors.As(err, &sqliteErr) && sqliteErr.Code() == sqliteConstraintUnique) {
+		return repositories.ErrDuplicateKey{Field: models.UserFieldEmail}
+	}
+	return err
+}
+
+func userFromGormModel(ud *gormModels.UserGormModel) *models.User {
+	user := &models.User{
 		ID:        ud.ID,
 		Password:  ud.Password,
 		Email:     ud.Email,
 		Name:      ud.Name,
+		IsAdmin:   ud.IsAdmin,
+		Version:   ud.Version,
 		CreatedAt: ud.CreatedAt,
 		UpdatedAt: ud.UpdatedAt,
-	}, nil
+	}
+	if ud.DeletedAt.Valid {
+		deletedAt := ud.DeletedAt.Time
+		user.DeletedAt = &deletedAt
+	}
+	return user
 }
 
 func NewUserGormDataSource(db *gorm.DB) repositories.UserRepository {
@@ -0,0 +1,212 @@
+package datasources
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UserInMemoryDataSource keeps users in memory with the semantics of
+// UserGormDataSource. It is safe for concurrent use and hands out copies, so
+// callers never share a user with the store.
+type UserInMemoryDataSource struct {
+	mu     sync.RWMutex
+	users  []*models.User
+	lastID uint
+}
+
+// Create implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	// Like the unique index of the users table, deleted users keep their email.
+	if ds.emailTaken(user.Email, 0) {
+		return nil, repositories.ErrDuplicateKey{Field: models.UserFieldEmail}
+	}
+	now := time.Now()
+	stored := &models.User{
+		Name:      user.Name,
+		Email:     user.Email,
+		Password:  user.Password,
+		Version:   1,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = now
+	}
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = now
+	}
+	ds.lastID++
+	stored.ID = ds.lastID
+	ds.users = append(ds.users, stored)
+	return copyUser(stored), nil
+}
+
+// DeleteByID implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) DeleteByID(ctx context.Context, id uint, version uint) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	user := ds.find(id, false)
+	if user == nil {
+		return repositories.ErrRecordNotFound{}
+	}
+	if user.Version != version {
+		return repositories.ErrVersionConflict{}
+	}
+	deletedAt := time.Now()
+	user.DeletedAt = &deletedAt
+	return nil
+}
+
+// FindAll implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) FindAll(ctx context.Context) ([]*models.User, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	results := make([]*models.User, 0, len(ds.users))
+	for _, user := range ds.users {
+		if user.DeletedAt == nil {
+			results = append(results, copyUser(user))
+		}
+	}
+	return results, nil
+}
+
+// FindByEmail implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	for _, user := range ds.users {
+		if user.Email == email && user.DeletedAt == nil {
+			return copyUser(user), nil
+		}
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+// FindByID implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if user := ds.find(id, false); user != nil {
+		return copyUser(user), nil
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+// Update implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) Update(ctx context.Context, user *models.User, fields []string) (*models.User, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	stored := ds.find(user.ID, false)
+	if stored == nil {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	if stored.Version != user.Version {
+		return nil, repositories.ErrVersionConflict{}
+	}
+	// Apply the mask to a copy so an unknown field or a taken email leaves the
+	// stored user untouched.
+	updated := copyUser(stored)
+	for _, field := range fields {
+		switch field {
+		case models.UserFieldName:
+			updated.Name = user.Name
+		case models.UserFieldEmail:
+			updated.Email = user.Email
+		case models.UserFieldPassword:
+			updated.Password = user.Password
+		default:
+			return nil, fmt.Errorf("unknown user field %q", field)
+		}
+	}
+	if updated.Email != stored.Email && ds.emailTaken(updated.Email, stored.ID) {
+		return nil, repositories.ErrDuplicateKey{Field: models.UserFieldEmail}
+	}
+	updated.UpdatedAt = time.Now()
+	updated.Version++
+	*stored = *updated
+	return copyUser(stored), nil
+}
+
+// FindDeletedByID implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) FindDeletedByID(ctx context.Context, id uint) (*models.User, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if user := ds.find(id, true); user != nil {
+		return copyUser(user), nil
+	}
+	return nil, repositories.ErrRecordNotFound{}
+}
+
+// Restore implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) Restore(ctx context.Context, id uint) (*models.User, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	user := ds.find(id, true)
+	if user == nil {
+		return nil, repositories.ErrRecordNotFound{}
+	}
+	user.DeletedAt = nil
+	return copyUser(user), nil
+}
+
+// PurgeDeletedBefore implements repositories.UserRepository
+func (ds *UserInMemoryDataSource) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	kept := ds.users[:0]
+	var purged int64
+	for _, user := range ds.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			purged++
+			continue
+		}
+		kept = append(kept, user)
+	}
+	// Clear the tail so purged users can be garbage collected.
+	for i := len(kept); i < len(ds.users); i++ {
+		ds.users[i] = nil
+	}
+	ds.users = kept
+	return purged, nil
+}
+
+// find returns the stored user with id, among the deleted users when deleted
+// is true and among the others otherwise. The caller must hold ds.mu.
+func (ds *UserInMemoryDataSource) find(id uint, deleted bool) *models.User {
+	for _, user := range ds.users {
+		if user.ID == id && (user.DeletedAt != nil) == deleted {
+			return user
+		}
+	}
+	return nil
+}
+
+// emailTaken tells whether a user other than the one with exceptID has email.
+// The caller must hold ds.mu.
+func (ds *UserInMemoryDataSource) emailTaken(email string, exceptID uint) bool {
+	for _, user := range ds.users {
+		if user.Email == email && user.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func copyUser(user *models.User) *models.User {
+	copied := *user
+	if user.DeletedAt != nil {
+		deletedAt := *user.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	return &copied
+}
+
+func NewUserInMemoryDataSource() repositories.UserRepository {
+	return &UserInMemoryDataSource{}
+}
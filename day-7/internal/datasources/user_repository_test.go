@@ -0,0 +1,300 @@
+package datasources_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userRepositories are the implementations every test of this file runs
+// against, each with a fresh, empty store.
+var userRepositories = []struct {
+	name string
+	new  func(t *testing.T) repositories.UserRepository
+}{
+	{
+		name: "memory",
+		new: func(t *testing.T) repositories.UserRepository {
+			return datasources.NewUserInMemoryDataSource()
+		},
+	},
+	{
+		name: "gorm",
+		new: func(t *testing.T) repositories.UserRepository {
+			return datasources.NewUserGormDataSource(openTestGormDB(t))
+		},
+	},
+}
+
+// eachUserRepository runs test as a subtest named name for every
+// implementation in userRepositories.
+func eachUserRepository(t *testing.T, name string, test func(t *testing.T, ds repositories.UserRepository)) {
+	for _, repository := range userRepositories {
+		newRepository := repository.new
+		t.Run(repository.name+"/"+name, func(t *testing.T) {
+			test(t, newRepository(t))
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	testCases := []struct {
+		name        string
+		users       []*models.User
+		user        *models.User
+		expectedErr error
+	}{
+		{
+			name: "Test create user should assign an id and the first version",
+			user: &models.User{Name: "name", Email: "user@mail.com", Password: "hash"},
+		},
+		{
+			name:        "Test create user when email is taken should return err duplicate key",
+			users:       []*models.User{{Name: "name", Email: "user@mail.com", Password: "hash"}},
+			user:        &models.User{Name: "other", Email: "user@mail.com", Password: "hash"},
+			expectedErr: repositories.ErrDuplicateKey{Field: models.UserFieldEmail},
+		},
+	}
+
+	for _, tc := range testCases {
+		eachUserRepository(t, tc.name, func(t *testing.T, ds repositories.UserRepository) {
+			// Setup
+			for _, u := range tc.users {
+				user := *u
+				_, _ = ds.Create(context.TODO(), &user)
+			}
+
+			// Act
+			user := *tc.user
+			result, err := ds.Create(context.TODO(), &user)
+
+			// Assert
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, uint(1), result.ID)
+			assert.Equal(t, uint(1), result.Version)
+			assert.False(t, result.CreatedAt.IsZero())
+			found, err := ds.FindByEmail(context.TODO(), tc.user.Email)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.user.Name, found.Name)
+			assert.Equal(t, tc.user.Password, found.Password)
+		})
+	}
+}
+
+func TestFindUser(t *testing.T) {
+	eachUserRepository(t, "Test find should only return users that are not deleted", func(t *testing.T, ds repositories.UserRepository) {
+		// Setup
+		kept, _ := ds.Create(context.TODO(), &models.User{Name: "kept", Email: "kept@mail.com"})
+		deleted, _ := ds.Create(context.TODO(), &models.User{Name: "deleted", Email: "deleted@mail.com"})
+		_ = ds.DeleteByID(context.TODO(), deleted.ID, deleted.Version)
+
+		// Act
+		all, allErr := ds.FindAll(context.TODO())
+		byID, byIDErr := ds.FindByID(context.TODO(), kept.ID)
+		_, deletedByIDErr := ds.FindByID(context.TODO(), deleted.ID)
+		_, deletedByEmailErr := ds.FindByEmail(context.TODO(), "deleted@mail.com")
+		_, missingErr := ds.FindByID(context.TODO(), 9)
+
+		// Assert
+		assert.NoError(t, allErr)
+		assert.Equal(t, 1, len(all))
+		assert.Equal(t, "kept", all[0].Name)
+		assert.NoError(t, byIDErr)
+		assert.Equal(t, "kept@mail.com", byID.Email)
+		for _, err := range []error{deletedByIDErr, deletedByEmailErr, missingErr} {
+			assert.ErrorIs(t, err, repositories.ErrRecordNotFound{})
+		}
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	testCases := []struct {
+		name         string
+		update       *models.User
+		fields       []string
+		expectedErr  error
+		expectedUser *models.User
+	}{
+		{
+			name:         "Test update should only change the fields of the mask",
+			update:       &models.User{ID: 1, Name: "name_new", Email: "ignored@mail.com", Version: 1},
+			fields:       []string{models.UserFieldName},
+			expectedUser: &models.User{ID: 1, Name: "name_new", Email: "user1@mail.com", Version: 2},
+		},
+		{
+			name:        "Test update when version is stale should return err version conflict",
+			update:      &models.User{ID: 1, Name: "name_new", Version: 3},
+			fields:      []string{models.UserFieldName},
+			expectedErr: repositories.ErrVersionConflict{},
+		},
+		{
+			name:        "Test update when email is taken should return err duplicate key",
+			update:      &models.User{ID: 1, Email: "user2@mail.com", Version: 1},
+			fields:      []string{models.UserFieldEmail},
+			expectedErr: repositories.ErrDuplicateKey{Field: models.UserFieldEmail},
+		},
+		{
+			name:        "Test update with an unknown field should return an error",
+			update:      &models.User{ID: 1, Version: 1},
+			fields:      []string{"is_admin"},
+			expectedErr: fmt.Errorf(`unknown user field "is_admin"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		eachUserRepository(t, tc.name, func(t *testing.T, ds repositories.UserRepository) {
+			// Setup
+			_, _ = ds.Create(context.TODO(), &models.User{Name: "name", Email: "user1@mail.com"})
+			_, _ = ds.Create(context.TODO(), &models.User{Name: "name", Email: "user2@mail.com"})
+
+			// Act
+			result, err := ds.Update(context.TODO(), tc.update, tc.fields)
+
+			// Assert
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				stored, _ := ds.FindByID(context.TODO(), tc.update.ID)
+				assert.Equal(t, uint(1), stored.Version)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUser.Name, result.Name)
+			assert.Equal(t, tc.expectedUser.Email, result.Email)
+			assert.Equal(t, tc.expectedUser.Version, result.Version)
+		})
+	}
+}
+
+func TestDeleteUserByID(t *testing.T) {
+	testCases := []struct {
+		name          string
+		id            uint
+		version       uint
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:          "Test delete when id not found should return err record not found",
+			id:            9,
+			version:       1,
+			expectedErr:   repositories.ErrRecordNotFound{},
+			expectedCount: 2,
+		},
+		{
+			name:          "Test delete when id found should return nil",
+			id:            1,
+			version:       1,
+			expectedCount: 1,
+		},
+		{
+			name:          "Test delete when version is stale should return err version conflict",
+			id:            1,
+			version:       2,
+			expectedErr:   repositories.ErrVersionConflict{},
+			expectedCount: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		eachUserRepository(t, tc.name, func(t *testing.T, ds repositories.UserRepository) {
+			// Setup
+			_, _ = ds.Create(context.TODO(), &models.User{Name: "name", Email: "user1@mail.com"})
+			_, _ = ds.Create(context.TODO(), &models.User{Name: "name", Email: "user2@mail.com"})
+
+			// Act
+			err := ds.DeleteByID(context.TODO(), tc.id, tc.version)
+			all, _ := ds.FindAll(context.TODO())
+
+			// Assert
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCount, len(all))
+		})
+	}
+}
+
+func TestSoftDeleteRestoreAndPurgeUser(t *testing.T) {
+	eachUserRepository(t, "Test soft deleted users should be restorable until purged", func(t *testing.T, ds repositories.UserRepository) {
+		// Setup
+		user, _ := ds.Create(context.TODO(), &models.User{Name: "name", Email: "user@mail.com"})
+		other, _ := ds.Create(context.TODO(), &models.User{Name: "other", Email: "other@mail.com"})
+
+		// Act & Assert soft delete
+		assert.NoError(t, ds.DeleteByID(context.TODO(), user.ID, user.Version))
+		deleted, err := ds.FindDeletedByID(context.TODO(), user.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, deleted.DeletedAt)
+		_, err = ds.FindDeletedByID(context.TODO(), other.ID)
+		assert.ErrorIs(t, err, repositories.ErrRecordNotFound{})
+
+		// Act & Assert restore
+		restored, err := ds.Restore(context.TODO(), user.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		_, err = ds.Restore(context.TODO(), user.ID)
+		assert.ErrorIs(t, err, repositories.ErrRecordNotFound{})
+
+		// Act & Assert purge
+		assert.NoError(t, ds.DeleteByID(context.TODO(), other.ID, other.Version))
+		purged, err := ds.PurgeDeletedBefore(context.TODO(), time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+		purged, err = ds.PurgeDeletedBefore(context.TODO(), time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		_, err = ds.FindDeletedByID(context.TODO(), other.ID)
+		assert.Error(t, err)
+		all, _ := ds.FindAll(context.TODO())
+		assert.Equal(t, 1, len(all))
+	})
+}
+
+func TestUserInMemoryDataSourceConcurrency(t *testing.T) {
+	// Setup
+	ds := datasources.NewUserInMemoryDataSource()
+	const writers = 20
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user, err := ds.Create(context.TODO(), &models.User{Email: fmt.Sprintf("user%d@mail.com", i)})
+			if err != nil {
+				return
+			}
+			// Changing the copy handed out must not change the stored user.
+			user.Name = "changed"
+			_, _ = ds.FindAll(context.TODO())
+			_, _ = ds.Update(context.TODO(), &models.User{ID: user.ID, Name: "updated", Version: 1}, []string{models.UserFieldName})
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	all, err := ds.FindAll(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, writers, len(all))
+	ids := make(map[uint]bool, writers)
+	for _, user := range all {
+		ids[user.ID] = true
+		assert.Equal(t, "updated", user.Name)
+		assert.Equal(t, uint(2), user.Version)
+	}
+	assert.Equal(t, writers, len(ids))
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TrashRetentionJob permanently removes items that have been sitting in the
+// trash for longer than the retention period.
+type TrashRetentionJob struct {
+	service   services.TrashService
+	retention time.Duration
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+// Run purges the trash once immediately and then on every interval until ctx
+// is cancelled.
+func (j *TrashRetentionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		j.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *TrashRetentionJob) purge(ctx context.Context) {
+	before := time.Now().UTC().Add(-j.retention)
+	result, err := j.service.Purge(ctx, before)
+	if err != nil {
+		j.logger.ErrorContext(ctx, "trash retention failed", "error", err)
+		return
+	}
+	if result.Books > 0 || result.Users > 0 {
+		j.logger.InfoContext(ctx, "trash retention purged",
+			"books", result.Books, "users", result.Users, "deleted_before", before.Format(time.RFC3339))
+	}
+}
+
+func NewTrashRetentionJob(service services.TrashService, retention time.Duration, interval time.Duration, logger *slog.Logger) *TrashRetentionJob {
+	return &TrashRetentionJob{
+		service:   service,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type bookRepository struct {
+	next repositories.BookRepository
+	observer
+}
+
+// FindAll implements repositories.BookRepository
+func (r *bookRepository) FindAll(ctx context.Context, filter models.BookFilter) (result []*models.Book, err error) {
+	defer r.observe("FindAll", time.Now(), &err)
+	return r.next.FindAll(ctx, filter)
+}
+
+// FindByID implements repositories.BookRepository
+func (r *bookRepository) FindByID(ctx context.Context, id uint) (result *models.Book, err error) {
+	defer r.observe("FindByID", time.Now(), &err)
+	return r.next.FindByID(ctx, id)
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (r *bookRepository) ExistsByIsbn(ctx context.Context, isbn string) (result bool, err error) {
+	defer r.observe("ExistsByIsbn", time.Now(), &err)
+	return r.next.ExistsByIsbn(ctx, isbn)
+}
+
+// Iterate implements repositories.BookRepository
+func (r *bookRepository) Iterate(ctx context.Context, filter models.BookFilter) (result repositories.BookIterator, err error) {
+	defer r.observe("Iterate", time.Now(), &err)
+	return r.next.Iterate(ctx, filter)
+}
+
+// Create implements repositories.BookRepository
+func (r *bookRepository) Create(ctx context.Context, book *models.Book) (result *models.Book, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, book)
+}
+
+// DeleteByID implements repositories.BookRepository
+func (r *bookRepository) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	defer r.observe("DeleteByID", time.Now(), &err)
+	return r.next.DeleteByID(ctx, id, version)
+}
+
+// Update implements repositories.BookRepository
+func (r *bookRepository) Update(ctx context.Context, book *models.Book, fields []string) (result *models.Book, err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, book, fields)
+}
+
+// FindDeleted implements repositories.BookRepository
+func (r *bookRepository) FindDeleted(ctx context.Context, userID uint) (result []*models.Book, err error) {
+	defer r.observe("FindDeleted", time.Now(), &err)
+	return r.next.FindDeleted(ctx, userID)
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (r *bookRepository) FindDeletedByID(ctx context.Context, id uint) (result *models.Book, err error) {
+	defer r.observe("FindDeletedByID", time.Now(), &err)
+	return r.next.FindDeletedByID(ctx, id)
+}
+
+// Restore implements repositories.BookRepository
+func (r *bookRepository) Restore(ctx context.Context, id uint) (result *models.Book, err error) {
+	defer r.observe("Restore", time.Now(), &err)
+	return r.next.Restore(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository
+func (r *bookRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (result int64, err error) {
+	defer r.observe("PurgeDeletedBefore", time.Now(), &err)
+	return r.next.PurgeDeletedBefore(ctx, before)
+}
+
+// BookRepository records the latency of every operation of next, a
+// repository backed by datasource.
+func (m *Metrics) BookRepository(next repositories.BookRepository, datasource string) repositories.BookRepository {
+	return &bookRepository{next: next, observer: m.observer("book", datasource)}
+}
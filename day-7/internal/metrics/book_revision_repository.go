@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type bookRevisionRepository struct {
+	next repositories.BookRevisionRepository
+	observer
+}
+
+// Create implements repositories.BookRevisionRepository
+func (r *bookRevisionRepository) Create(ctx context.Context, revision *models.BookRevision) (result *models.BookRevision, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, revision)
+}
+
+// FindByBookID implements repositories.BookRevisionRepository
+func (r *bookRevisionRepository) FindByBookID(ctx context.Context, bookID uint) (result []*models.BookRevision, err error) {
+	defer r.observe("FindByBookID", time.Now(), &err)
+	return r.next.FindByBookID(ctx, bookID)
+}
+
+// FindByRevision implements repositories.BookRevisionRepository
+func (r *bookRevisionRepository) FindByRevision(ctx context.Context, bookID uint, revision uint) (result *models.BookRevision, err error) {
+	defer r.observe("FindByRevision", time.Now(), &err)
+	return r.next.FindByRevision(ctx, bookID, revision)
+}
+
+// BookRevisionRepository records the latency of every operation of next, a
+// repository backed by datasource.
+func (m *Metrics) BookRevisionRepository(next repositories.BookRevisionRepository, datasource string) repositories.BookRevisionRepository {
+	return &bookRevisionRepository{next: next, observer: m.observer("book_revision", datasource)}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type categoryRepository struct {
+	next repositories.CategoryRepository
+	observer
+}
+
+// FindAll implements repositories.CategoryRepository
+func (r *categoryRepository) FindAll(ctx context.Context) (result []*models.Category, err error) {
+	defer r.observe("FindAll", time.Now(), &err)
+	return r.next.FindAll(ctx)
+}
+
+// FindByID implements repositories.CategoryRepository
+func (r *categoryRepository) FindByID(ctx context.Context, id uint) (result *models.Category, err error) {
+	defer r.observe("FindByID", time.Now(), &err)
+	return r.next.FindByID(ctx, id)
+}
+
+// Create implements repositories.CategoryRepository
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) (result *models.Category, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, category)
+}
+
+// Update implements repositories.CategoryRepository
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) (result *models.Category, err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, category)
+}
+
+// DeleteByID implements repositories.CategoryRepository
+func (r *categoryRepository) DeleteByID(ctx context.Context, id uint) (err error) {
+	defer r.observe("DeleteByID", time.Now(), &err)
+	return r.next.DeleteByID(ctx, id)
+}
+
+// CategoryRepository records the latency of every operation of next, a
+// repository backed by datasource.
+func (m *Metrics) CategoryRepository(next repositories.CategoryRepository, datasource string) repositories.CategoryRepository {
+	return &categoryRepository{next: next, observer: m.observer("category", datasource)}
+}
@@ -0,0 +1,161 @@
+// Package metrics collects the Prometheus metrics of the app: HTTP request
+// and repository latencies, cache lookups, database pool usage and Go runtime
+// metrics.
+package metrics
+
+import (
+	"alterra-agmc-day-7/internal/cache"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+const namespace = "bookstore"
+
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+type Metrics struct {
+	registry           *prometheus.Registry
+	httpDuration       *prometheus.HistogramVec
+	repositoryDuration *prometheus.HistogramVec
+	mongoConnections   *prometheus.GaugeVec
+}
+
+// New creates the metrics of the app in a registry of their own, along with
+// the Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of repository operations by repository, datasource, operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repository", "datasource", "operation", "outcome"}),
+		mongoConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "mongo_pool",
+			Name:      "connections",
+			Help:      "Connections of the MongoDB pool by state, open or in_use.",
+		}, []string{"state"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.repositoryDuration,
+		m.mongoConnections,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Registry returns the registry holding every metric of the app.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveHTTPRequest records a request served by route, the route template
+// such as /v1/books/:id rather than the requested path.
+func (m *Metrics) ObserveHTTPRequest(route string, method string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// RegisterDBStats exposes the connection pool statistics of db as gauges
+// labelled with name.
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterCacheStats exposes the lookup counters of stats, the ones of the
+// cache named name, as counters labelled with name.
+func (m *Metrics) RegisterCacheStats(stats *cache.Stats, name string) error {
+	counters := []struct {
+		name  string
+		help  string
+		value func() uint64
+	}{
+		{name: "hits_total", help: "Lookups served by the cache.", value: stats.Hits},
+		{name: "misses_total", help: "Lookups read through to the repository.", value: stats.Misses},
+		{name: "errors_total", help: "Reads and writes that failed in the cache backend.", value: stats.Errors},
+	}
+	for _, counter := range counters {
+		value := counter.value
+		err := m.registry.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "cache",
+			Name:        counter.name,
+			Help:        counter.help,
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, func() float64 {
+			return float64(value())
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MongoPoolMonitor returns a pool monitor keeping the MongoDB pool gauges up
+// to date. Set it on the client options before connecting.
+func (m *Metrics) MongoPoolMonitor() *event.PoolMonitor {
+	open := m.mongoConnections.WithLabelValues("open")
+	inUse := m.mongoConnections.WithLabelValues("in_use")
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+			case event.ConnectionReturned:
+				inUse.Dec()
+			}
+		},
+	}
+}
+
+// observer records the operations of one repository backed by datasource.
+type observer struct {
+	histogram  *prometheus.HistogramVec
+	repository string
+	datasource string
+}
+
+// observe records operation as started at start and failed when *err is not
+// nil. It is meant to be deferred with a pointer to a named result.
+func (o observer) observe(operation string, start time.Time, err *error) {
+	outcome := outcomeOK
+	if *err != nil {
+		outcome = outcomeError
+	}
+	o.histogram.WithLabelValues(o.repository, o.datasource, operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) observer(repository string, datasource string) observer {
+	return observer{histogram: m.repositoryDuration, repository: repository, datasource: datasource}
+}
@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"alterra-agmc-day-7/internal/cache"
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/metrics"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookRepositoryMetrics(t *testing.T) {
+	// Setup
+	m := metrics.New()
+	repository := m.BookRepository(datasources.NewBookInMemoryDataSource(), "memory")
+	book, err := repository.Create(context.TODO(), &models.Book{Title: "Test Book"})
+	assert.NoError(t, err)
+
+	// Act
+	_, _ = repository.FindByID(context.TODO(), book.ID)
+	_, _ = repository.FindByID(context.TODO(), book.ID+1)
+
+	// Assert
+	families, err := m.Registry().Gather()
+	assert.NoError(t, err)
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "bookstore_repository_operation_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, "book", labels["repository"])
+			assert.Equal(t, "memory", labels["datasource"])
+			counts[labels["operation"]+"/"+labels["outcome"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{"Create/ok": 1, "FindByID/ok": 1, "FindByID/error": 1}, counts)
+}
+
+func TestCacheStatsMetrics(t *testing.T) {
+	// Setup
+	m := metrics.New()
+	repository := cache.NewBookRepository(datasources.NewBookInMemoryDataSource(), cache.NewMemory(10), time.Minute, logger.Discard())
+	assert.NoError(t, m.RegisterCacheStats(repository.Stats(), "book"))
+	book, err := repository.Create(context.TODO(), &models.Book{Title: "Test Book"})
+	assert.NoError(t, err)
+
+	// Act
+	_, _ = repository.FindByID(context.TODO(), book.ID)
+	_, _ = repository.FindByID(context.TODO(), book.ID)
+	_, _ = repository.FindByID(context.TODO(), book.ID)
+
+	// Assert
+	families, err := m.Registry().Gather()
+	assert.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "cache" {
+					assert.Equal(t, "book", label.GetValue())
+					values[family.GetName()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"bookstore_cache_hits_total":   2,
+		"bookstore_cache_misses_total": 1,
+		"bookstore_cache_errors_total": 0,
+	}, values)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type userRepository struct {
+	next repositories.UserRepository
+	observer
+}
+
+// FindAll implements repositories.UserRepository
+func (r *userRepository) FindAll(ctx context.Context) (result []*models.User, err error) {
+	defer r.observe("FindAll", time.Now(), &err)
+	return r.next.FindAll(ctx)
+}
+
+// FindByID implements repositories.UserRepository
+func (r *userRepository) FindByID(ctx context.Context, id uint) (result *models.User, err error) {
+	defer r.observe("FindByID", time.Now(), &err)
+	return r.next.FindByID(ctx, id)
+}
+
+// FindByEmail implements repositories.UserRepository
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (result *models.User, err error) {
+	defer r.observe("FindByEmail", time.Now(), &err)
+	return r.next.FindByEmail(ctx, email)
+}
+
+// Create implements repositories.UserRepository
+func (r *userRepository) Create(ctx context.Context, user *models.User) (result *models.User, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, user)
+}
+
+// Update implements repositories.UserRepository
+func (r *userRepository) Update(ctx context.Context, user *models.User, fields []string) (result *models.User, err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, user, fields)
+}
+
+// DeleteByID implements repositories.UserRepository
+func (r *userRepository) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	defer r.observe("DeleteByID", time.Now(), &err)
+	return r.next.DeleteByID(ctx, id, version)
+}
+
+// FindDeletedByID implements repositories.UserRepository
+func (r *userRepository) FindDeletedByID(ctx context.Context, id uint) (result *models.User, err error) {
+	defer r.observe("FindDeletedByID", time.Now(), &err)
+	return r.next.FindDeletedByID(ctx, id)
+}
+
+// Restore implements repositories.UserRepository
+func (r *userRepository) Restore(ctx context.Context, id uint) (result *models.User, err error) {
+	defer r.observe("Restore", time.Now(), &err)
+	return r.next.Restore(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.UserRepository
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (result int64, err error) {
+	defer r.observe("PurgeDeletedBefore", time.Now(), &err)
+	return r.next.PurgeDeletedBefore(ctx, before)
+}
+
+// UserRepository records the latency of every operation of next, a
+// repository backed by datasource.
+func (m *Metrics) UserRepository(next repositories.UserRepository, datasource string) repositories.UserRepository {
+	return &userRepository{next: next, observer: m.observer("user", datasource)}
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var nonWordCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create adds the up and down files of a new migration named name for every
+// dialect under dir, numbered after the last migration of any dialect, and
+// returns their paths. The files only hold a comment; a migration without
+// statements is refused until they are written.
+func Create(dir string, name string) ([]string, error) {
+	name = strings.Trim(nonWordCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name should contain letters or digits")
+	}
+	var last uint64
+	for _, dialect := range Dialects {
+		entries, err := os.ReadDir(filepath.Join(dir, dialect))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			match := migrationFileName.FindStringSubmatch(entry.Name())
+			if match == nil {
+				continue
+			}
+			if version, err := strconv.ParseUint(match[1], 10, 64); err == nil && version > last {
+				last = version
+			}
+		}
+	}
+	migration := Migration{Version: last + 1, Name: name}
+	var paths []string
+	for _, dialect := range Dialects {
+		if err := os.MkdirAll(filepath.Join(dir, dialect), 0o755); err != nil {
+			return paths, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, dialect, fmt.Sprintf("%s.%s.sql", migration, direction))
+			content := fmt.Sprintf("-- %s %s for %s.\n", migration, direction, dialect)
+			// O_EXCL keeps a racing create from overwriting a written migration.
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return paths, err
+			}
+			_, err = file.WriteString(content)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
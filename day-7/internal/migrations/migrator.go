@@ -0,0 +1,379 @@
+package migrations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// lockExpiry is how long a lock is honoured. A migrator holding it longer
+	// is assumed to have died, so the lock can be taken over.
+	lockExpiry = 15 * time.Minute
+	// lockPollInterval is how often a waiting migrator tries the lock again.
+	lockPollInterval = time.Second
+)
+
+// State of a migration as reported by Status.
+const (
+	// StatePending migrations are yet to be applied.
+	StatePending = "pending"
+	// StateApplied migrations are applied and unchanged since.
+	StateApplied = "applied"
+	// StateModified migrations are applied but their up script changed since.
+	StateModified = "modified"
+	// StateMissing migrations are applied but have no files anymore, e.g.
+	// after a rollback of the app.
+	StateMissing = "missing"
+)
+
+// ErrChecksumMismatch is returned when an applied migration was edited.
+type ErrChecksumMismatch struct {
+	Migration string
+}
+
+func (e ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("migration %s was changed after it was applied, add a new migration instead", e.Migration)
+}
+
+// ErrMissingMigration is returned when an applied migration has no files.
+type ErrMissingMigration struct {
+	Migration string
+}
+
+func (e ErrMissingMigration) Error() string {
+	return fmt.Sprintf("migration %s is applied but unknown to this version of the app", e.Migration)
+}
+
+// ErrOutOfOrder is returned when a migration is pending below an applied one.
+type ErrOutOfOrder struct {
+	Pending string
+	Applied string
+}
+
+func (e ErrOutOfOrder) Error() string {
+	return fmt.Sprintf("migration %s is pending but the later %s is already applied, renumber it", e.Pending, e.Applied)
+}
+
+// Status is the state of one migration in the database.
+type Status struct {
+	Version   uint64
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	Version   uint64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (m appliedMigration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Migrator applies and rolls back migrations, recording them in the
+// schema_migrations table. A lock kept in schema_migrations_lock lets a single
+// migrator at a time change the schema, so replicas can all migrate on boot.
+type Migrator struct {
+	db           *gorm.DB
+	migrations   []Migration
+	owner        string
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+// NewMigrator returns a Migrator of db with the migrations of its dialect
+// found in fsys, usually Files.
+func NewMigrator(db *gorm.DB, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	sub, err := fs.Sub(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("load %s migrations: %v", dialect, err)
+	}
+	return &Migrator{
+		db:           db,
+		migrations:   migrations,
+		owner:        lockOwner(),
+		pollInterval: lockPollInterval,
+		logger:       logger,
+	}, nil
+}
+
+// Up applies the pending migrations in order, at most limit of them when
+// limit is positive, and returns the ones applied.
+func (m *Migrator) Up(ctx context.Context, limit int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		done, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		pending, err := m.pending(done)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && len(pending) > limit {
+			pending = pending[:limit]
+		}
+		for _, migration := range pending {
+			start := time.Now()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Up); err != nil {
+					return err
+				}
+				return tx.Exec(
+					"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+					migration.Version, migration.Name, migration.Checksum(), time.Now().UTC(),
+				).Error
+			})
+			if err != nil {
+				return fmt.Errorf("apply migration %s: %v", migration, err)
+			}
+			m.logger.InfoContext(ctx, "migration applied", "migration", migration.String(), "elapsed_ms", time.Since(start).Milliseconds())
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		done, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		if _, err := m.pending(done); err != nil {
+			return err
+		}
+		for i := len(done) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := m.find(done[i].Version)
+			start := time.Now()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("roll back migration %s: %v", migration, err)
+			}
+			m.logger.InfoContext(ctx, "migration rolled back", "migration", migration.String(), "elapsed_ms", time.Since(start).Milliseconds())
+			rolledBack = append(rolledBack, *migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status returns the state of every known or applied migration, by version.
+// It takes no lock, so it can be used while another migrator is running.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+	if err := ensureTables(db); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint64]appliedMigration, len(done))
+	for _, migration := range done {
+		byVersion[migration.Version] = migration
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if applied, ok := byVersion[migration.Version]; ok {
+			appliedAt := applied.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if applied.Checksum != migration.Checksum() {
+				status.State = StateModified
+			}
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, migration := range done {
+		if _, ok := byVersion[migration.Version]; ok {
+			appliedAt := migration.AppliedAt
+			statuses = append(statuses, Status{Version: migration.Version, Name: migration.Name, State: StateMissing, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Pending returns the migrations yet to be applied, failing like Up would if
+// the applied ones do not match the known ones.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	db := m.db.WithContext(ctx)
+	if err := ensureTables(db); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+	return m.pending(done)
+}
+
+// pending checks the applied migrations against the known ones and returns
+// the known ones left to apply.
+func (m *Migrator) pending(done []appliedMigration) ([]Migration, error) {
+	var last *appliedMigration
+	applied := make(map[uint64]bool, len(done))
+	for i, migration := range done {
+		known := m.find(migration.Version)
+		if known == nil {
+			return nil, ErrMissingMigration{Migration: migration.String()}
+		}
+		if known.Checksum() != migration.Checksum {
+			return nil, ErrChecksumMismatch{Migration: known.String()}
+		}
+		applied[migration.Version] = true
+		last = &done[i]
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		if last != nil && migration.Version < last.Version {
+			return nil, ErrOutOfOrder{Pending: migration.String(), Applied: last.String()}
+		}
+		pending = append(pending, migration)
+	}
+	return pending, nil
+}
+
+func (m *Migrator) find(version uint64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// applied returns the rows of schema_migrations by version.
+func (m *Migrator) applied(db *gorm.DB) ([]appliedMigration, error) {
+	var done []appliedMigration
+	err := db.Raw("SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version").Scan(&done).Error
+	return done, err
+}
+
+// withLock runs fn while holding the migration lock, waiting for it as long
+// as ctx allows.
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if err := ensureTables(db); err != nil {
+		return err
+	}
+	for {
+		now := time.Now().UTC()
+		res := db.Exec(
+			"UPDATE schema_migrations_lock SET locked_by = ?, locked_at = ? WHERE id = 1 AND (locked_by IS NULL OR locked_at < ?)",
+			m.owner, now, now.Add(-lockExpiry),
+		)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			break
+		}
+		m.logger.InfoContext(ctx, "waiting for the migration lock")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("acquire migration lock: %w", ctx.Err())
+		case <-time.After(m.pollInterval):
+		}
+	}
+	defer func() {
+		// Release even when ctx is done, or others wait for the lock to expire.
+		err := m.db.Exec("UPDATE schema_migrations_lock SET locked_by = NULL, locked_at = NULL WHERE id = 1 AND locked_by = ?", m.owner).Error
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to release the migration lock", "error", err)
+		}
+	}()
+	return fn(db)
+}
+
+// ensureTables creates the tables of the migrator, if needed.
+func ensureTables(db *gorm.DB) error {
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  checksum CHAR(64) NOT NULL,
+  applied_at TIMESTAMP NOT NULL
+)`).Error
+	if err != nil {
+		return err
+	}
+	err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+  id INT NOT NULL PRIMARY KEY,
+  locked_by VARCHAR(255) NULL,
+  locked_at TIMESTAMP NULL
+)`).Error
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) FROM schema_migrations_lock WHERE id = 1").Scan(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := db.Exec("INSERT INTO schema_migrations_lock (id) VALUES (1)").Error; err != nil {
+		// Another migrator may have inserted the row in the meantime.
+		if countErr := db.Raw("SELECT COUNT(*) FROM schema_migrations_lock WHERE id = 1").Scan(&count).Error; countErr != nil || count == 0 {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec runs every statement of script. MySQL commits schema changes right
+// away, so a failing script can leave it half applied there.
+func exec(tx *gorm.DB, script string) error {
+	for _, statement := range statements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockOwner identifies this process in schema_migrations_lock.
+func lockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,333 @@
+package migrations_test
+
+import (
+	gormModels "alterra-agmc-day-7/internal/datasources/models"
+	"alterra-agmc-day-7/internal/migrations"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"sql/sqlite/0001_create_authors.up.sql":   {Data: []byte("CREATE TABLE authors (id INTEGER PRIMARY KEY);\n")},
+		"sql/sqlite/0001_create_authors.down.sql": {Data: []byte("DROP TABLE authors;\n")},
+		"sql/sqlite/0002_add_author_name.up.sql": {Data: []byte(
+			"-- Names are optional for now.\nALTER TABLE authors\n  ADD COLUMN name TEXT;\nCREATE INDEX idx_authors_name ON authors (name);\n",
+		)},
+		"sql/sqlite/0002_add_author_name.down.sql": {Data: []byte("DROP INDEX idx_authors_name;\nALTER TABLE authors DROP COLUMN name;\n")},
+	}
+}
+
+func states(t *testing.T, migrator *migrations.Migrator) []string {
+	statuses, err := migrator.Status(context.TODO())
+	assert.NoError(t, err)
+	result := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, status.Name+" "+status.State)
+	}
+	return result
+}
+
+func TestMigratorUpAndDown(t *testing.T) {
+	// Setup
+	db := openTestDB(t)
+	migrator, err := migrations.NewMigrator(db, testFiles(), logger.Discard())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"create_authors pending", "add_author_name pending"}, states(t, migrator))
+
+	// Act & Assert up one step
+	applied, err := migrator.Up(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(applied))
+	assert.True(t, db.Migrator().HasTable("authors"))
+	assert.False(t, db.Migrator().HasColumn("authors", "name"))
+	assert.Equal(t, []string{"create_authors applied", "add_author_name pending"}, states(t, migrator))
+
+	// Act & Assert up the rest
+	applied, err = migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(applied))
+	assert.True(t, db.Migrator().HasColumn("authors", "name"))
+	applied, err = migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(applied))
+	pending, err := migrator.Pending(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(pending))
+
+	// Act & Assert down
+	rolledBack, err := migrator.Down(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0002_add_author_name", rolledBack[0].String())
+	assert.False(t, db.Migrator().HasColumn("authors", "name"))
+	rolledBack, err = migrator.Down(context.TODO(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rolledBack))
+	assert.False(t, db.Migrator().HasTable("authors"))
+	assert.Equal(t, []string{"create_authors pending", "add_author_name pending"}, states(t, migrator))
+}
+
+func TestMigratorDrift(t *testing.T) {
+	testCases := []struct {
+		name           string
+		change         func(files fstest.MapFS)
+		expectedErr    error
+		expectedStates []string
+	}{
+		{
+			name: "Test an edited applied migration should be refused",
+			change: func(files fstest.MapFS) {
+				files["sql/sqlite/0001_create_authors.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE authors (id INTEGER PRIMARY KEY, bio TEXT);\n")}
+			},
+			expectedErr:    migrations.ErrChecksumMismatch{Migration: "0001_create_authors"},
+			expectedStates: []string{"create_authors modified", "add_author_name pending"},
+		},
+		{
+			name: "Test a removed applied migration should be refused",
+			change: func(files fstest.MapFS) {
+				delete(files, "sql/sqlite/0001_create_authors.up.sql")
+				delete(files, "sql/sqlite/0001_create_authors.down.sql")
+			},
+			expectedErr:    migrations.ErrMissingMigration{Migration: "0001_create_authors"},
+			expectedStates: []string{"create_authors missing", "add_author_name pending"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			db := openTestDB(t)
+			migrator, _ := migrations.NewMigrator(db, testFiles(), logger.Discard())
+			_, err := migrator.Up(context.TODO(), 1)
+			assert.NoError(t, err)
+			files := testFiles()
+			tc.change(files)
+
+			// Act
+			migrator, err = migrations.NewMigrator(db, files, logger.Discard())
+			assert.NoError(t, err)
+			_, upErr := migrator.Up(context.TODO(), 0)
+			_, downErr := migrator.Down(context.TODO(), 1)
+
+			// Assert
+			assert.Equal(t, tc.expectedErr, upErr)
+			assert.Equal(t, tc.expectedErr, downErr)
+			assert.Equal(t, tc.expectedStates, states(t, migrator))
+		})
+	}
+}
+
+func TestMigratorOutOfOrder(t *testing.T) {
+	// Setup
+	db := openTestDB(t)
+	files := testFiles()
+	files["sql/sqlite/0003_create_books.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE books (id INTEGER PRIMARY KEY);\n")}
+	files["sql/sqlite/0003_create_books.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE books;\n")}
+	// 0002 comes from a branch merged after 0003 was deployed.
+	deployed := fstest.MapFS{}
+	for name, file := range files {
+		if !strings.Contains(name, "0002_") {
+			deployed[name] = file
+		}
+	}
+	migrator, _ := migrations.NewMigrator(db, deployed, logger.Discard())
+	_, err := migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+
+	// Act
+	migrator, _ = migrations.NewMigrator(db, files, logger.Discard())
+	_, err = migrator.Up(context.TODO(), 0)
+
+	// Assert
+	assert.Equal(t, migrations.ErrOutOfOrder{Pending: "0002_add_author_name", Applied: "0003_create_books"}, err)
+}
+
+func TestMigratorLock(t *testing.T) {
+	// Setup
+	db := openTestDB(t)
+	migrator, _ := migrations.NewMigrator(db, testFiles(), logger.Discard())
+	_, _ = migrator.Status(context.TODO())
+	assert.NoError(t, db.Exec("UPDATE schema_migrations_lock SET locked_by = 'other', locked_at = ? WHERE id = 1", time.Now().UTC()).Error)
+
+	// Act & Assert a held lock
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := migrator.Up(ctx, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, db.Migrator().HasTable("authors"))
+
+	// Act & Assert an expired lock
+	assert.NoError(t, db.Exec("UPDATE schema_migrations_lock SET locked_at = ? WHERE id = 1", time.Now().UTC().Add(-time.Hour)).Error)
+	applied, err := migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(applied))
+	var lockedBy *string
+	assert.NoError(t, db.Raw("SELECT locked_by FROM schema_migrations_lock WHERE id = 1").Scan(&lockedBy).Error)
+	assert.Nil(t, lockedBy)
+}
+
+func TestShippedMigrations(t *testing.T) {
+	// Setup
+	var versions []uint64
+	for _, dialect := range migrations.Dialects {
+		loaded, err := migrations.Load(mustSub(t, "sql"), dialect)
+		assert.NoError(t, err)
+		dialectVersions := make([]uint64, 0, len(loaded))
+		for _, migration := range loaded {
+			dialectVersions = append(dialectVersions, migration.Version)
+		}
+		if versions == nil {
+			versions = dialectVersions
+		}
+		assert.Equal(t, versions, dialectVersions, "every dialect should have the same migrations, %s differs", dialect)
+	}
+	db := openTestDB(t)
+	migrator, err := migrations.NewMigrator(db, migrations.Files, logger.Discard())
+	assert.NoError(t, err)
+
+	// Act & Assert
+	_, err = migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+	_, err = migrator.Down(context.TODO(), len(versions))
+	assert.NoError(t, err)
+	tables, _ := db.Migrator().GetTables()
+	assert.ElementsMatch(t, []string{"schema_migrations", "schema_migrations_lock"}, withoutSQLiteTables(tables))
+	_, err = migrator.Up(context.TODO(), 0)
+	assert.NoError(t, err)
+}
+
+func TestShippedMigrationsNormalizeUserEmails(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup []string
+	}{
+		{
+			name: "Test migrations on a users table created before them should upgrade it and normalize its emails",
+			setup: []string{
+				// The schema AutoMigrate created from gorm.Model before the migrations.
+				"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME, name LONGTEXT, email LONGTEXT, password LONGTEXT)",
+				"CREATE INDEX idx_users_deleted_at ON users (deleted_at)",
+				"INSERT INTO users (email) VALUES ('User@Mail.com'), (' user@mail.com'), ('other@mail.com'), ('USER@MAIL.COM')",
+			},
+		},
+		{
+			name: "Test migrations on an empty database should create a case-insensitive email index",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			db := openTestDB(t)
+			for _, statement := range tc.setup {
+				assert.NoError(t, db.Exec(statement).Error)
+			}
+			migrator, err := migrations.NewMigrator(db, migrations.Files, logger.Discard())
+			assert.NoError(t, err)
+
+			// Act
+			_, err = migrator.Up(context.TODO(), 0)
+
+			// Assert
+			assert.NoError(t, err)
+			var emails []string
+			assert.NoError(t, db.Raw("SELECT email FROM users ORDER BY id").Scan(&emails).Error)
+			if len(tc.setup) > 0 {
+				assert.Equal(t, []string{"user@mail.com", "duplicate-2+user@mail.com", "other@mail.com", "duplicate-4+user@mail.com"}, emails)
+				var versions []uint
+				assert.NoError(t, db.Raw("SELECT version FROM users WHERE is_admin = false ORDER BY id").Scan(&versions).Error)
+				assert.Equal(t, []uint{1, 1, 1, 1}, versions)
+			}
+			assert.True(t, db.Migrator().HasIndex("users", "idx_users_email"))
+			assert.True(t, db.Migrator().HasColumn("users", "is_admin"))
+			assert.True(t, db.Migrator().HasColumn("users", "version"))
+			assert.NoError(t, db.Create(&gormModels.UserGormModel{Email: "new@mail.com", Version: 1}).Error)
+			assert.NoError(t, db.Model(&gormModels.UserGormModel{}).Where("email = ? AND version = ?", "new@mail.com", 1).Updates(map[string]interface{}{"is_admin": true, "version": 2}).Error)
+			assert.Error(t, db.Exec("INSERT INTO users (email) VALUES ('New@Mail.com')").Error)
+		})
+	}
+}
+
+func TestShippedMigrationsCreateUniqueUserEmailIndex(t *testing.T) {
+	// Only the sqlite migrations run here, the others are checked to at least
+	// create the index.
+	for _, dialect := range migrations.Dialects {
+		t.Run(dialect, func(t *testing.T) {
+			// Setup
+			loaded, err := migrations.Load(mustSub(t, "sql"), dialect)
+			assert.NoError(t, err)
+
+			// Act
+			var scripts strings.Builder
+			for _, migration := range loaded {
+				scripts.WriteString(migration.Up)
+			}
+
+			// Assert
+			assert.Contains(t, scripts.String(), "CREATE UNIQUE INDEX idx_users_email ON users")
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, migrations.DialectSQLite), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, migrations.DialectSQLite, "0007_create_authors.up.sql"), []byte("SELECT 1;\n"), 0o644))
+
+	// Act
+	paths, err := migrations.Create(dir, "Add Author's Name")
+	_, emptyErr := migrations.Create(dir, "!!")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2*len(migrations.Dialects), len(paths))
+	assert.Equal(t, filepath.Join(dir, migrations.DialectMySQL, "0008_add_author_s_name.up.sql"), paths[0])
+	assert.Error(t, emptyErr)
+	_, err = migrations.Load(os.DirFS(dir), migrations.DialectMySQL)
+	assert.EqualError(t, err, "mysql/0008_add_author_s_name: both the up and the down script need a statement")
+}
+
+func mustSub(t *testing.T, dir string) fs.FS {
+	sub, err := fs.Sub(migrations.Files, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func withoutSQLiteTables(tables []string) []string {
+	result := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if table != "sqlite_sequence" {
+			result = append(result, table)
+		}
+	}
+	return result
+}
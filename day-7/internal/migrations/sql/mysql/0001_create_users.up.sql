@@ -0,0 +1,13 @@
+-- The users table as AutoMigrate created it before the migrations, so the
+-- databases it created adopt them. Later migrations change it.
+CREATE TABLE IF NOT EXISTS users (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  deleted_at DATETIME(3) NULL,
+  name LONGTEXT,
+  email LONGTEXT,
+  password LONGTEXT,
+  PRIMARY KEY (id),
+  INDEX idx_users_deleted_at (deleted_at)
+);
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS books (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  title VARCHAR(255) NOT NULL,
+  isbn VARCHAR(64) NOT NULL,
+  writer VARCHAR(255) NOT NULL,
+  category_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  user_id BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  deleted_at DATETIME(3) NULL,
+  version BIGINT UNSIGNED NOT NULL DEFAULT 1,
+  PRIMARY KEY (id),
+  INDEX idx_books_isbn (isbn),
+  INDEX idx_books_category_id (category_id),
+  INDEX idx_books_user_id (user_id),
+  INDEX idx_books_deleted_at (deleted_at),
+  CONSTRAINT fk_books_user FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS book_tags (
+  book_id BIGINT UNSIGNED NOT NULL,
+  tag VARCHAR(64) NOT NULL,
+  position BIGINT NOT NULL,
+  PRIMARY KEY (book_id, tag),
+  INDEX idx_book_tags_tag (tag),
+  CONSTRAINT fk_books_tags FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(255) NOT NULL,
+  parent_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_categories_parent_id (parent_id)
+);
+
+CREATE TABLE IF NOT EXISTS book_revisions (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  revision BIGINT UNSIGNED NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  actor_id BIGINT UNSIGNED NOT NULL,
+  snapshot TEXT NOT NULL,
+  created_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_book_revisions_book_revision (book_id, revision)
+);
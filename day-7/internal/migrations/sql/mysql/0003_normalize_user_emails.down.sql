@@ -0,0 +1,3 @@
+-- Emails stay lower-cased.
+DROP INDEX idx_users_email ON users;
+ALTER TABLE users MODIFY email LONGTEXT;
@@ -0,0 +1,14 @@
+-- Emails compare case-insensitively, as does the default collation of the
+-- unique index. Every email is lower-cased; an email shared by several users,
+-- once trimmed, stays with the oldest one and is renamed to
+-- duplicate-<id>+<email> on the others, so their rows can be fixed by hand.
+UPDATE users
+JOIN (
+  SELECT LOWER(TRIM(email)) AS email, MIN(id) AS id FROM users GROUP BY LOWER(TRIM(email))
+) AS owners ON owners.email = LOWER(TRIM(users.email)) AND owners.id <> users.id
+SET users.email = CONCAT('duplicate-', users.id, '+', LOWER(TRIM(users.email)));
+UPDATE users SET email = LOWER(TRIM(email)) WHERE BINARY email <> BINARY LOWER(TRIM(email));
+-- A LONGTEXT column can not be indexed whole, and 191 characters of utf8mb4
+-- fit the key size limit of older InnoDB row formats.
+ALTER TABLE users MODIFY email VARCHAR(191);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
@@ -0,0 +1,12 @@
+-- The users table as AutoMigrate created it before the migrations, so the
+-- databases it created adopt them. Later migrations change it.
+CREATE TABLE IF NOT EXISTS users (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  name TEXT,
+  email TEXT,
+  password TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS books (
+  id BIGSERIAL PRIMARY KEY,
+  title VARCHAR(255) NOT NULL,
+  isbn VARCHAR(64) NOT NULL,
+  writer VARCHAR(255) NOT NULL,
+  category_id BIGINT NOT NULL DEFAULT 0,
+  user_id BIGINT NOT NULL,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  version BIGINT NOT NULL DEFAULT 1,
+  CONSTRAINT fk_books_user FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_books_isbn ON books (isbn);
+CREATE INDEX IF NOT EXISTS idx_books_category_id ON books (category_id);
+CREATE INDEX IF NOT EXISTS idx_books_user_id ON books (user_id);
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
+
+CREATE TABLE IF NOT EXISTS book_tags (
+  book_id BIGINT NOT NULL,
+  tag VARCHAR(64) NOT NULL,
+  position BIGINT NOT NULL,
+  PRIMARY KEY (book_id, tag),
+  CONSTRAINT fk_books_tags FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_book_tags_tag ON book_tags (tag);
+
+CREATE TABLE IF NOT EXISTS categories (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  parent_id BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS book_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  book_id BIGINT NOT NULL,
+  revision BIGINT NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  actor_id BIGINT NOT NULL,
+  snapshot TEXT NOT NULL,
+  created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_book_revisions_book_revision ON book_revisions (book_id, revision);
@@ -0,0 +1,5 @@
+-- Users created before versioning start at version 1, as new ones do.
+ALTER TABLE users
+  ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+UPDATE users SET version = 1;
@@ -0,0 +1,12 @@
+-- The users table as AutoMigrate created it before the migrations, so the
+-- databases it created adopt them. Later migrations change it.
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at DATETIME,
+  updated_at DATETIME,
+  deleted_at DATETIME,
+  name TEXT,
+  email TEXT COLLATE NOCASE,
+  password TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
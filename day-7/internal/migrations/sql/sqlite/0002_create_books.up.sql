@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS books (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  isbn TEXT NOT NULL,
+  writer TEXT NOT NULL,
+  category_id INTEGER NOT NULL DEFAULT 0,
+  user_id INTEGER NOT NULL,
+  created_at DATETIME,
+  updated_at DATETIME,
+  deleted_at DATETIME,
+  version INTEGER NOT NULL DEFAULT 1,
+  CONSTRAINT fk_books_user FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_books_isbn ON books (isbn);
+CREATE INDEX IF NOT EXISTS idx_books_category_id ON books (category_id);
+CREATE INDEX IF NOT EXISTS idx_books_user_id ON books (user_id);
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
+
+CREATE TABLE IF NOT EXISTS book_tags (
+  book_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  position INTEGER NOT NULL,
+  PRIMARY KEY (book_id, tag),
+  CONSTRAINT fk_books_tags FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_book_tags_tag ON book_tags (tag);
+
+CREATE TABLE IF NOT EXISTS categories (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  parent_id INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME,
+  updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS book_revisions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  book_id INTEGER NOT NULL,
+  revision INTEGER NOT NULL,
+  action TEXT NOT NULL,
+  actor_id INTEGER NOT NULL,
+  snapshot TEXT NOT NULL,
+  created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_book_revisions_book_revision ON book_revisions (book_id, revision);
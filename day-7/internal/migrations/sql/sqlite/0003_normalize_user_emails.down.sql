@@ -0,0 +1,2 @@
+-- Emails stay lower-cased.
+DROP INDEX IF EXISTS idx_users_email;
@@ -0,0 +1,13 @@
+-- Emails compare case-insensitively. Every email is lower-cased; an email
+-- shared by several users stays with the oldest one and is renamed to
+-- duplicate-<id>+<email> on the others, so their rows can be fixed by hand.
+UPDATE users SET email = 'duplicate-' || id || '+' || LOWER(TRIM(email))
+WHERE EXISTS (
+  SELECT 1 FROM users AS older
+  WHERE LOWER(TRIM(older.email)) = LOWER(TRIM(users.email)) AND older.id < users.id
+);
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email COLLATE BINARY <> LOWER(TRIM(email));
+-- Tables created before the migrations have a case-sensitive email column,
+-- hence the collation of the index.
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email COLLATE NOCASE);
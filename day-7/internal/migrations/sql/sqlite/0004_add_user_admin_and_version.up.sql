@@ -0,0 +1,4 @@
+-- Users created before versioning start at version 1, as new ones do.
+ALTER TABLE users ADD COLUMN is_admin NUMERIC NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+UPDATE users SET version = 1;
@@ -3,11 +3,43 @@ package models
 import "time"
 
 type Book struct {
-	ID        uint
-	Title     string
-	Isbn      string
-	Writer    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uint
+	ID         uint
+	Title      string
+	Isbn       string
+	Writer     string
+	CategoryID uint
+	Tags       []string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+	Version    uint
+	UserID     uint
+}
+
+type BookFilter struct {
+	UserID      uint
+	Title       string
+	Writer      string
+	Isbn        string
+	CategoryIDs []uint
+	Tags        []string
+}
+
+// Fields of a book that can be changed by an update. They are used as field
+// masks so that only the listed fields are written.
+const (
+	BookFieldTitle      = "title"
+	BookFieldIsbn       = "isbn"
+	BookFieldWriter     = "writer"
+	BookFieldCategoryID = "category_id"
+	BookFieldTags       = "tags"
+)
+
+// BookFields is the mask of a full book update.
+var BookFields = []string{
+	BookFieldTitle,
+	BookFieldIsbn,
+	BookFieldWriter,
+	BookFieldCategoryID,
+	BookFieldTags,
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	BookRevisionCreate  = "create"
+	BookRevisionUpdate  = "update"
+	BookRevisionDelete  = "delete"
+	BookRevisionRestore = "restore"
+	BookRevisionRevert  = "revert"
+)
+
+// BookRevision is an immutable snapshot of a book taken after every change.
+type BookRevision struct {
+	ID        uint
+	BookID    uint
+	Revision  uint
+	Action    string
+	ActorID   uint
+	CreatedAt time.Time
+	Snapshot  Book
+}
+
+type BookFieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Category struct {
+	ID        uint
+	Name      string
+	ParentID  uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
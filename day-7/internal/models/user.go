@@ -6,7 +6,17 @@ type User struct {
 	Name      string
 	Email     string
 	Password  string
+	IsAdmin   bool
 	ID        uint
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt *time.Time
+	Version   uint
 }
+
+// Fields of a user that can be changed by an update, used as field masks.
+const (
+	UserFieldName     = "name"
+	UserFieldEmail    = "email"
+	UserFieldPassword = "password"
+)
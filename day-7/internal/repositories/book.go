@@ -3,12 +3,31 @@ package repositories
 import (
 	"alterra-agmc-day-7/internal/models"
 	"context"
+	"time"
 )
 
 type BookRepository interface {
-	FindAll(ctx context.Context) ([]*models.Book, error)
+	FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error)
 	FindByID(ctx context.Context, id uint) (*models.Book, error)
+	ExistsByIsbn(ctx context.Context, isbn string) (bool, error)
+	Iterate(ctx context.Context, filter models.BookFilter) (BookIterator, error)
 	Create(ctx context.Context, book *models.Book) (*models.Book, error)
-	DeleteByID(ctx context.Context, id uint) error
-	Update(ctx context.Context, book *models.Book) (*models.Book, error)
+	// DeleteByID soft deletes the book of id if it still has version, and
+	// returns ErrVersionConflict otherwise.
+	DeleteByID(ctx context.Context, id uint, version uint) error
+	// Update writes only the fields of book named in fields, see models.BookFields.
+	Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error)
+	FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error)
+	FindDeletedByID(ctx context.Context, id uint) (*models.Book, error)
+	Restore(ctx context.Context, id uint) (*models.Book, error)
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// BookIterator walks over books one at a time without loading the whole
+// result set in memory. Callers must Close it once they are done.
+type BookIterator interface {
+	Next(ctx context.Context) bool
+	Book() *models.Book
+	Err() error
+	Close(ctx context.Context) error
 }
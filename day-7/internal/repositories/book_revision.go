@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"context"
+)
+
+type BookRevisionRepository interface {
+	Create(ctx context.Context, revision *models.BookRevision) (*models.BookRevision, error)
+	FindByBookID(ctx context.Context, bookID uint) ([]*models.BookRevision, error)
+	FindByRevision(ctx context.Context, bookID uint, revision uint) (*models.BookRevision, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"context"
+)
+
+type CategoryRepository interface {
+	FindAll(ctx context.Context) ([]*models.Category, error)
+	FindByID(ctx context.Context, id uint) (*models.Category, error)
+	Create(ctx context.Context, category *models.Category) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category) (*models.Category, error)
+	DeleteByID(ctx context.Context, id uint) error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+// ErrRecordNotFound is returned when no record matches the id or criteria
+// asked for. Datasources translate the not found errors of their drivers into
+// it, so callers need not know the backend.
+type ErrRecordNotFound struct{}
+
+func (e ErrRecordNotFound) Error() string {
+	return "record not found"
+}
+
+// ErrVersionConflict is returned by Update, and DeleteByID for books, when the
+// stored record no longer has the version of the record passed in, i.e.
+// someone else updated it first. A successful Update increments the version.
+type ErrVersionConflict struct{}
+
+func (e ErrVersionConflict) Error() string {
+	return "version conflict"
+}
+
+// ErrDuplicateKey is returned by Create and Update when the record would share
+// the value of a unique Field with another record.
+type ErrDuplicateKey struct {
+	Field string
+}
+
+func (e ErrDuplicateKey) Error() string {
+	return "duplicate " + e.Field
+}
@@ -4,7 +4,9 @@ package mocks
 
 import (
 	models "alterra-agmc-day-7/internal/models"
+	repositories "alterra-agmc-day-7/internal/repositories"
 	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -37,13 +39,13 @@ func (_m *BookRepository) Create(ctx context.Context, book *models.Book) (*model
 	return r0, r1
 }
 
-// DeleteByID provides a mock function with given fields: ctx, id
-func (_m *BookRepository) DeleteByID(ctx context.Context, id uint) error {
-	ret := _m.Called(ctx, id)
+// DeleteByID provides a mock function with given fields: ctx, id, version
+func (_m *BookRepository) DeleteByID(ctx context.Context, id uint, version uint) error {
+	ret := _m.Called(ctx, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -51,13 +53,34 @@ func (_m *BookRepository) DeleteByID(ctx context.Context, id uint) error {
 	return r0
 }
 
-// FindAll provides a mock function with given fields: ctx
-func (_m *BookRepository) FindAll(ctx context.Context) ([]*models.Book, error) {
-	ret := _m.Called(ctx)
+// ExistsByIsbn provides a mock function with given fields: ctx, isbn
+func (_m *BookRepository) ExistsByIsbn(ctx context.Context, isbn string) (bool, error) {
+	ret := _m.Called(ctx, isbn)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, isbn)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, isbn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAll provides a mock function with given fields: ctx, filter
+func (_m *BookRepository) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	ret := _m.Called(ctx, filter)
 
 	var r0 []*models.Book
-	if rf, ok := ret.Get(0).(func(context.Context) []*models.Book); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, models.BookFilter) []*models.Book); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Book)
@@ -65,8 +88,8 @@ func (_m *BookRepository) FindAll(ctx context.Context) ([]*models.Book, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, models.BookFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -97,13 +120,36 @@ func (_m *BookRepository) FindByID(ctx context.Context, id uint) (*models.Book,
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, book
-func (_m *BookRepository) Update(ctx context.Context, book *models.Book) (*models.Book, error) {
-	ret := _m.Called(ctx, book)
+// FindDeleted provides a mock function with given fields: ctx, userID
+func (_m *BookRepository) FindDeleted(ctx context.Context, userID uint) ([]*models.Book, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []*models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*models.Book); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDeletedByID provides a mock function with given fields: ctx, id
+func (_m *BookRepository) FindDeletedByID(ctx context.Context, id uint) (*models.Book, error) {
+	ret := _m.Called(ctx, id)
 
 	var r0 *models.Book
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Book) *models.Book); ok {
-		r0 = rf(ctx, book)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Book); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Book)
@@ -111,8 +157,98 @@ func (_m *BookRepository) Update(ctx context.Context, book *models.Book) (*model
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.Book) error); ok {
-		r1 = rf(ctx, book)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Iterate provides a mock function with given fields: ctx, filter
+func (_m *BookRepository) Iterate(ctx context.Context, filter models.BookFilter) (repositories.BookIterator, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 repositories.BookIterator
+	if rf, ok := ret.Get(0).(func(context.Context, models.BookFilter) repositories.BookIterator); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.BookIterator)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.BookFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedBefore provides a mock function with given fields: ctx, before
+func (_m *BookRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *BookRepository) Restore(ctx context.Context, id uint) (*models.Book, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Book); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, book, fields
+func (_m *BookRepository) Update(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	ret := _m.Called(ctx, book, fields)
+
+	var r0 *models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Book, []string) *models.Book); ok {
+		r0 = rf(ctx, book, fields)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Book, []string) error); ok {
+		r1 = rf(ctx, book, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
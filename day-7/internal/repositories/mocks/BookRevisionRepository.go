@@ -0,0 +1,99 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "alterra-agmc-day-7/internal/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BookRevisionRepository is an autogenerated mock type for the BookRevisionRepository type
+type BookRevisionRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, revision
+func (_m *BookRevisionRepository) Create(ctx context.Context, revision *models.BookRevision) (*models.BookRevision, error) {
+	ret := _m.Called(ctx, revision)
+
+	var r0 *models.BookRevision
+	if rf, ok := ret.Get(0).(func(context.Context, *models.BookRevision) *models.BookRevision); ok {
+		r0 = rf(ctx, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BookRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.BookRevision) error); ok {
+		r1 = rf(ctx, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByBookID provides a mock function with given fields: ctx, bookID
+func (_m *BookRevisionRepository) FindByBookID(ctx context.Context, bookID uint) ([]*models.BookRevision, error) {
+	ret := _m.Called(ctx, bookID)
+
+	var r0 []*models.BookRevision
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*models.BookRevision); ok {
+		r0 = rf(ctx, bookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.BookRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, bookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByRevision provides a mock function with given fields: ctx, bookID, revision
+func (_m *BookRevisionRepository) FindByRevision(ctx context.Context, bookID uint, revision uint) (*models.BookRevision, error) {
+	ret := _m.Called(ctx, bookID, revision)
+
+	var r0 *models.BookRevision
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *models.BookRevision); ok {
+		r0 = rf(ctx, bookID, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BookRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, bookID, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewBookRevisionRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBookRevisionRepository creates a new instance of BookRevisionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBookRevisionRepository(t mockConstructorTestingTNewBookRevisionRepository) *BookRevisionRepository {
+	mock := &BookRevisionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,136 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "alterra-agmc-day-7/internal/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CategoryRepository is an autogenerated mock type for the CategoryRepository type
+type CategoryRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, category
+func (_m *CategoryRepository) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	ret := _m.Called(ctx, category)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category) *models.Category); ok {
+		r0 = rf(ctx, category)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category) error); ok {
+		r1 = rf(ctx, category)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteByID provides a mock function with given fields: ctx, id
+func (_m *CategoryRepository) DeleteByID(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindAll provides a mock function with given fields: ctx
+func (_m *CategoryRepository) FindAll(ctx context.Context) ([]*models.Category, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*models.Category
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Category); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *CategoryRepository) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Category); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, category
+func (_m *CategoryRepository) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	ret := _m.Called(ctx, category)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category) *models.Category); ok {
+		r0 = rf(ctx, category)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category) error); ok {
+		r1 = rf(ctx, category)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewCategoryRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewCategoryRepository creates a new instance of CategoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewCategoryRepository(t mockConstructorTestingTNewCategoryRepository) *CategoryRepository {
+	mock := &CategoryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -37,13 +39,13 @@ func (_m *UserRepository) Create(ctx context.Context, user *models.User) (*model
 	return r0, r1
 }
 
-// DeleteByID provides a mock function with given fields: ctx, id
-func (_m *UserRepository) DeleteByID(ctx context.Context, id uint) error {
-	ret := _m.Called(ctx, id)
+// DeleteByID provides a mock function with given fields: ctx, id, version
+func (_m *UserRepository) DeleteByID(ctx context.Context, id uint, version uint) error {
+	ret := _m.Called(ctx, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -120,13 +122,13 @@ func (_m *UserRepository) FindByID(ctx context.Context, id uint) (*models.User,
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, user
-func (_m *UserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	ret := _m.Called(ctx, user)
+// FindDeletedByID provides a mock function with given fields: ctx, id
+func (_m *UserRepository) FindDeletedByID(ctx context.Context, id uint) (*models.User, error) {
+	ret := _m.Called(ctx, id)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.User); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -134,8 +136,75 @@ func (_m *UserRepository) Update(ctx context.Context, user *models.User) (*model
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(ctx, user)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedBefore provides a mock function with given fields: ctx, before
+func (_m *UserRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *UserRepository) Restore(ctx context.Context, id uint) (*models.User, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, user, fields
+func (_m *UserRepository) Update(ctx context.Context, user *models.User, fields []string) (*models.User, error) {
+	ret := _m.Called(ctx, user, fields)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, []string) *models.User); ok {
+		r0 = rf(ctx, user, fields)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, []string) error); ok {
+		r1 = rf(ctx, user, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"alterra-agmc-day-7/internal/models"
 	"context"
+	"time"
 )
 
 type UserRepository interface {
@@ -10,6 +11,12 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uint) (*models.User, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	Create(ctx context.Context, user *models.User) (*models.User, error)
-	Update(ctx context.Context, user *models.User) (*models.User, error)
-	DeleteByID(ctx context.Context, id uint) error
+	// Update writes only the fields of user named in fields.
+	Update(ctx context.Context, user *models.User, fields []string) (*models.User, error)
+	// DeleteByID soft deletes the user of id if it still has version, and
+	// returns ErrVersionConflict otherwise.
+	DeleteByID(ctx context.Context, id uint, version uint) error
+	FindDeletedByID(ctx context.Context, id uint) (*models.User, error)
+	Restore(ctx context.Context, id uint) (*models.User, error)
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
 }
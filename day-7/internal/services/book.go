@@ -4,60 +4,266 @@ import (
 	"alterra-agmc-day-7/internal/models"
 	"alterra-agmc-day-7/internal/repositories"
 	"context"
-	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 )
 
 type BookService interface {
-	FindAll(ctx context.Context) ([]*models.Book, error)
+	FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error)
+	Stream(ctx context.Context, filter models.BookFilter, fn func(book *models.Book) error) error
 	FindByID(ctx context.Context, id uint) (*models.Book, error)
 	Create(ctx context.Context, book *models.Book) (*models.Book, error)
-	DeleteByID(ctx context.Context, id uint, userId uint) error
+	DeleteByID(ctx context.Context, id uint, userId uint, version uint) error
 	Update(ctx context.Context, book *models.Book) (*models.Book, error)
+	Patch(ctx context.Context, book *models.Book, fields []string) (*models.Book, error)
+	Import(ctx context.Context, books []*models.Book, dryRun bool) ([]*BookImportResult, error)
+	Restore(ctx context.Context, id uint, userID uint) (*models.Book, error)
+	FindRevisions(ctx context.Context, id uint) ([]*models.BookRevision, error)
+	FindRevision(ctx context.Context, id uint, revision uint) (*models.BookRevision, error)
+	DiffRevisions(ctx context.Context, id uint, from uint, to uint) ([]models.BookFieldChange, error)
+	Revert(ctx context.Context, id uint, revision uint, userID uint) (*models.Book, error)
+}
+
+type BookImportStatus string
+
+const (
+	BookImportCreated BookImportStatus = "created"
+	BookImportSkipped BookImportStatus = "skipped"
+	BookImportFailed  BookImportStatus = "failed"
+)
+
+type BookImportResult struct {
+	Book   *models.Book
+	Status BookImportStatus
+	Reason string
 }
 
 type bookServiceImpl struct {
-	repo repositories.BookRepository
+	repo               repositories.BookRepository
+	categoryRepository repositories.CategoryRepository
+	revisionRepository repositories.BookRevisionRepository
+	logger             *slog.Logger
 }
 
 // Create implements BookService
 func (s *bookServiceImpl) Create(ctx context.Context, book *models.Book) (*models.Book, error) {
-	return s.repo.Create(ctx, book)
+	if err := s.prepare(ctx, book); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, book)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(ctx, models.BookRevisionCreate, created.UserID, created); err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "book created", "book_id", created.ID, "user_id", created.UserID)
+	return created, nil
 }
 
 // DeleteByID implements BookService
-func (s *bookServiceImpl) DeleteByID(ctx context.Context, id uint, userID uint) error {
+func (s *bookServiceImpl) DeleteByID(ctx context.Context, id uint, userID uint, version uint) error {
 	book, err := s.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 	if book.UserID != userID {
-		return errors.New("access denied")
+		return ErrForbidden{}
+	}
+	if !versionMatches(version, book.Version) {
+		return ErrPreconditionFailed{}
+	}
+	// The repository deletes the book only if it still has the version read,
+	// so an update made meanwhile, or a stale read, fails the precondition.
+	if err := s.repo.DeleteByID(ctx, id, book.Version); err != nil {
+		return repositoryError(err, "book")
 	}
-	return s.repo.DeleteByID(ctx, id)
+	deleted, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return repositoryError(err, "book")
+	}
+	if err := s.recordRevision(ctx, models.BookRevisionDelete, userID, deleted); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "book deleted", "book_id", id, "user_id", userID)
+	return nil
 }
 
 // FindAll implements BookService
-func (s *bookServiceImpl) FindAll(ctx context.Context) ([]*models.Book, error) {
-	return s.repo.FindAll(ctx)
+func (s *bookServiceImpl) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	return s.repo.FindAll(ctx, filter)
+}
+
+// Stream implements BookService
+func (s *bookServiceImpl) Stream(ctx context.Context, filter models.BookFilter, fn func(book *models.Book) error) error {
+	it, err := s.repo.Iterate(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer it.Close(ctx)
+	for it.Next(ctx) {
+		if err := fn(it.Book()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
 }
 
 // FindByID implements BookService
 func (s *bookServiceImpl) FindByID(ctx context.Context, id uint) (*models.Book, error) {
-	return s.repo.FindByID(ctx, id)
+	book, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "book")
+	}
+	return book, nil
 }
 
 // Update implements BookService
 func (s *bookServiceImpl) Update(ctx context.Context, book *models.Book) (*models.Book, error) {
+	return s.Patch(ctx, book, models.BookFields)
+}
+
+// Patch implements BookService
+func (s *bookServiceImpl) Patch(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
 	b, err := s.FindByID(ctx, book.ID)
 	if err != nil {
 		return nil, err
 	}
 	if b.UserID != book.UserID {
-		return nil, errors.New("access denied")
+		return nil, ErrForbidden{}
 	}
-	return s.repo.Update(ctx, book)
+	if !versionMatches(book.Version, b.Version) {
+		return nil, ErrPreconditionFailed{}
+	}
+	book.Version = b.Version
+	if err := s.prepare(ctx, book); err != nil {
+		return nil, err
+	}
+	return s.updateAndRecord(ctx, book, fields, models.BookRevisionUpdate)
+}
+
+// Restore implements BookService
+func (s *bookServiceImpl) Restore(ctx context.Context, id uint, userID uint) (*models.Book, error) {
+	book, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "book")
+	}
+	if book.UserID != userID {
+		return nil, ErrForbidden{}
+	}
+	restored, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "book")
+	}
+	if err := s.recordRevision(ctx, models.BookRevisionRestore, userID, restored); err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "book restored", "book_id", id, "user_id", userID)
+	return restored, nil
 }
 
-func NewBookService(repo repositories.BookRepository) BookService {
-	return &bookServiceImpl{repo: repo}
+// Import implements BookService
+func (s *bookServiceImpl) Import(ctx context.Context, books []*models.Book, dryRun bool) ([]*BookImportResult, error) {
+	results := make([]*BookImportResult, 0, len(books))
+	seenIsbn := make(map[string]bool)
+	for _, book := range books {
+		if seenIsbn[book.Isbn] {
+			results = append(results, &BookImportResult{
+				Book:   book,
+				Status: BookImportSkipped,
+				Reason: fmt.Sprintf("duplicate isbn %q in import", book.Isbn),
+			})
+			continue
+		}
+		seenIsbn[book.Isbn] = true
+		if err := s.prepare(ctx, book); err != nil {
+			results = append(results, &BookImportResult{
+				Book:   book,
+				Status: BookImportFailed,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		exists, err := s.repo.ExistsByIsbn(ctx, book.Isbn)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			results = append(results, &BookImportResult{
+				Book:   book,
+				Status: BookImportSkipped,
+				Reason: fmt.Sprintf("book with isbn %q already exists", book.Isbn),
+			})
+			continue
+		}
+		if dryRun {
+			results = append(results, &BookImportResult{Book: book, Status: BookImportCreated})
+			continue
+		}
+		created, err := s.repo.Create(ctx, book)
+		if err != nil {
+			s.logger.WarnContext(ctx, "book import row failed", "isbn", book.Isbn, "error", err)
+			results = append(results, &BookImportResult{
+				Book:   book,
+				Status: BookImportFailed,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		if err := s.recordRevision(ctx, models.BookRevisionCreate, created.UserID, created); err != nil {
+			return nil, err
+		}
+		results = append(results, &BookImportResult{Book: created, Status: BookImportCreated})
+	}
+	return results, nil
+}
+
+// prepare normalizes the tags of book and makes sure its category exists.
+func (s *bookServiceImpl) prepare(ctx context.Context, book *models.Book) error {
+	book.Tags = normalizeTags(book.Tags)
+	if book.CategoryID == 0 {
+		return nil
+	}
+	if _, err := s.categoryRepository.FindByID(ctx, book.CategoryID); err != nil {
+		return ErrValidation{Reason: "category not found"}
+	}
+	return nil
+}
+
+// versionMatches reports whether the version a client sent, 0 when it sent
+// none, allows changing a record stored at current.
+func versionMatches(expected uint, current uint) bool {
+	return expected == 0 || expected == current
+}
+
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func NewBookService(
+	repo repositories.BookRepository,
+	categoryRepository repositories.CategoryRepository,
+	revisionRepository repositories.BookRevisionRepository,
+	logger *slog.Logger,
+) BookService {
+	return &bookServiceImpl{
+		repo:               repo,
+		categoryRepository: categoryRepository,
+		revisionRepository: revisionRepository,
+		logger:             logger,
+	}
 }
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"context"
+	"reflect"
+)
+
+// FindRevisions implements BookService
+func (s *bookServiceImpl) FindRevisions(ctx context.Context, id uint) ([]*models.BookRevision, error) {
+	revisions, err := s.revisionRepository.FindByBookID(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "book")
+	}
+	return revisions, nil
+}
+
+// FindRevision implements BookService
+func (s *bookServiceImpl) FindRevision(ctx context.Context, id uint, revision uint) (*models.BookRevision, error) {
+	bookRevision, err := s.revisionRepository.FindByRevision(ctx, id, revision)
+	if err != nil {
+		return nil, repositoryError(err, "book revision")
+	}
+	return bookRevision, nil
+}
+
+// DiffRevisions implements BookService
+func (s *bookServiceImpl) DiffRevisions(ctx context.Context, id uint, from uint, to uint) ([]models.BookFieldChange, error) {
+	fromRevision, err := s.FindRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.FindRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffBooks(&fromRevision.Snapshot, &toRevision.Snapshot), nil
+}
+
+// Revert implements BookService
+func (s *bookServiceImpl) Revert(ctx context.Context, id uint, revision uint, userID uint) (*models.Book, error) {
+	book, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if book.UserID != userID {
+		return nil, ErrForbidden{}
+	}
+	target, err := s.FindRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	reverted := *book
+	reverted.Title = target.Snapshot.Title
+	reverted.Isbn = target.Snapshot.Isbn
+	reverted.Writer = target.Snapshot.Writer
+	reverted.CategoryID = target.Snapshot.CategoryID
+	reverted.Tags = append([]string(nil), target.Snapshot.Tags...)
+	return s.updateAndRecord(ctx, &reverted, models.BookFields, models.BookRevisionRevert)
+}
+
+// updateAndRecord updates the fields of book and records a revision holding
+// the stored result.
+func (s *bookServiceImpl) updateAndRecord(ctx context.Context, book *models.Book, fields []string, action string) (*models.Book, error) {
+	actorID := book.UserID
+	if _, err := s.repo.Update(ctx, book, fields); err != nil {
+		return nil, repositoryError(err, "book")
+	}
+	updated, err := s.FindByID(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(ctx, action, actorID, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *bookServiceImpl) recordRevision(ctx context.Context, action string, actorID uint, book *models.Book) error {
+	snapshot := *book
+	snapshot.Tags = append([]string(nil), book.Tags...)
+	_, err := s.revisionRepository.Create(ctx, &models.BookRevision{
+		BookID:   book.ID,
+		Action:   action,
+		ActorID:  actorID,
+		Snapshot: snapshot,
+	})
+	return err
+}
+
+// diffBooks lists the user editable fields that differ between from and to.
+func diffBooks(from *models.Book, to *models.Book) []models.BookFieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", from.Title, to.Title},
+		{"isbn", from.Isbn, to.Isbn},
+		{"writer", from.Writer, to.Writer},
+		{"category_id", from.CategoryID, to.CategoryID},
+		{"tags", nonNilTags(from.Tags), nonNilTags(to.Tags)},
+		{"user_id", from.UserID, to.UserID},
+		{"deleted_at", from.DeletedAt, to.DeletedAt},
+	}
+	changes := make([]models.BookFieldChange, 0)
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.from, f.to) {
+			changes = append(changes, models.BookFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
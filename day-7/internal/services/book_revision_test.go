@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookRevisionDiffAndRevert(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	bookService := services.NewBookService(
+		datasources.NewBookInMemoryDataSource(),
+		nil,
+		datasources.NewBookRevisionInMemoryDataSource(),
+		logger.Discard(),
+	)
+	book, err := bookService.Create(ctx, &models.Book{Title: "Title", Isbn: "isbn", Writer: "Writer", Tags: []string{"Go"}, UserID: 1})
+	assert.NoError(t, err)
+	_, err = bookService.Update(ctx, &models.Book{ID: book.ID, Title: "New Title", Isbn: "isbn", Writer: "Writer", UserID: 1})
+	assert.NoError(t, err)
+
+	// Act
+	changes, err := bookService.DiffRevisions(ctx, book.ID, 1, 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BookFieldChange{
+		{Field: "title", From: "Title", To: "New Title"},
+		{Field: "tags", From: []string{"go"}, To: []string{}},
+	}, changes)
+
+	// Act
+	_, err = bookService.Revert(ctx, book.ID, 1, 2)
+
+	// Assert
+	assert.EqualError(t, err, "forbidden")
+
+	// Act
+	reverted, err := bookService.Revert(ctx, book.ID, 1, 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Title", reverted.Title)
+	assert.Equal(t, []string{"go"}, reverted.Tags)
+	revisions, err := bookService.FindRevisions(ctx, book.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 3)
+	assert.Equal(t, uint(3), revisions[2].Revision)
+	assert.Equal(t, models.BookRevisionRevert, revisions[2].Action)
+	assert.Equal(t, uint(1), revisions[2].ActorID)
+}
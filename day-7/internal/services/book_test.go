@@ -1,7 +1,182 @@
 package services_test
 
-import "testing"
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/internal/repositories/mocks"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
 
 func TestFindAllBook(t *testing.T) {
 
 }
+
+func TestImportBook(t *testing.T) {
+	testCases := []struct {
+		name             string
+		books            []*models.Book
+		existingIsbn     map[string]bool
+		dryRun           bool
+		expectedStatuses []services.BookImportStatus
+		expectedCreates  int
+	}{
+		{
+			name: "Test import should skip isbn duplicated in import and in repository",
+			books: []*models.Book{
+				{Title: "title", Isbn: "isbn", Writer: "writer"},
+				{Title: "title2", Isbn: "isbn", Writer: "writer2"},
+				{Title: "title3", Isbn: "isbn3", Writer: "writer3"},
+			},
+			existingIsbn: map[string]bool{"isbn3": true},
+			expectedStatuses: []services.BookImportStatus{
+				services.BookImportCreated,
+				services.BookImportSkipped,
+				services.BookImportSkipped,
+			},
+			expectedCreates: 1,
+		},
+		{
+			name: "Test import with dry run should not create books",
+			books: []*models.Book{
+				{Title: "title", Isbn: "isbn", Writer: "writer"},
+			},
+			dryRun:           true,
+			expectedStatuses: []services.BookImportStatus{services.BookImportCreated},
+			expectedCreates:  0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			repo := mocks.NewBookRepository(t)
+			repo.On("ExistsByIsbn", mock.Anything, mock.AnythingOfType("string")).Return(func(_ context.Context, isbn string) bool {
+				return tc.existingIsbn[isbn]
+			}, nil)
+			if tc.expectedCreates > 0 {
+				repo.On("Create", mock.Anything, mock.Anything).Return(func(_ context.Context, book *models.Book) *models.Book {
+					return book
+				}, nil).Times(tc.expectedCreates)
+			}
+			service := services.NewBookService(repo, nil, datasources.NewBookRevisionInMemoryDataSource(), logger.Discard())
+
+			// Act
+			results, err := service.Import(context.TODO(), tc.books, tc.dryRun)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, len(tc.expectedStatuses), len(results))
+			for i, r := range results {
+				assert.Equal(t, tc.expectedStatuses[i], r.Status)
+			}
+		})
+	}
+}
+
+func TestRestoreBook(t *testing.T) {
+	testCases := []struct {
+		name        string
+		deletedBook *models.Book
+		userID      uint
+		expectedErr string
+	}{
+		{
+			name:        "Test restore book owned by another user should return forbidden",
+			deletedBook: &models.Book{ID: 1, UserID: 12},
+			userID:      15,
+			expectedErr: "forbidden",
+		},
+		{
+			name:        "Test restore book owned by user should restore book",
+			deletedBook: &models.Book{ID: 1, UserID: 12},
+			userID:      12,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			repo := mocks.NewBookRepository(t)
+			repo.On("FindDeletedByID", mock.Anything, tc.deletedBook.ID).Return(tc.deletedBook, nil)
+			if tc.expectedErr == "" {
+				repo.On("Restore", mock.Anything, tc.deletedBook.ID).Return(tc.deletedBook, nil)
+			}
+			service := services.NewBookService(repo, nil, datasources.NewBookRevisionInMemoryDataSource(), logger.Discard())
+
+			// Act
+			result, err := service.Restore(context.TODO(), tc.deletedBook.ID, tc.userID)
+
+			// Assert
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.deletedBook.ID, result.ID)
+		})
+	}
+}
+
+func TestUpdateBookVersion(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	bookService := services.NewBookService(
+		datasources.NewBookInMemoryDataSource(),
+		nil,
+		datasources.NewBookRevisionInMemoryDataSource(),
+		logger.Discard(),
+	)
+	book, err := bookService.Create(ctx, &models.Book{Title: "Title", Isbn: "isbn", Writer: "Writer", UserID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), book.Version)
+
+	// Act
+	updated, err := bookService.Update(ctx, &models.Book{ID: book.ID, Title: "First", Isbn: "isbn", Writer: "Writer", UserID: 1, Version: 1})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), updated.Version)
+
+	// Act
+	_, err = bookService.Update(ctx, &models.Book{ID: book.ID, Title: "Second", Isbn: "isbn", Writer: "Writer", UserID: 1, Version: 1})
+
+	// Assert
+	assert.Equal(t, services.ErrPreconditionFailed{}, err)
+	assert.Equal(t, services.ErrPreconditionFailed{}, bookService.DeleteByID(ctx, book.ID, 1, 1))
+	assert.NoError(t, bookService.DeleteByID(ctx, book.ID, 1, 2))
+}
+
+func TestDeleteBookWithStaleRead(t *testing.T) {
+	// Setup
+	// The read, from a cache say, still has version 1 while the store has
+	// moved on: the repository refuses the delete.
+	repo := mocks.NewBookRepository(t)
+	repo.On("FindByID", mock.Anything, uint(1)).Return(&models.Book{ID: 1, UserID: 12, Version: 1}, nil)
+	repo.On("DeleteByID", mock.Anything, uint(1), uint(1)).Return(repositories.ErrVersionConflict{})
+	service := services.NewBookService(repo, nil, datasources.NewBookRevisionInMemoryDataSource(), logger.Discard())
+
+	// Act
+	err := service.DeleteByID(context.TODO(), 1, 12, 1)
+
+	// Assert
+	assert.Equal(t, services.ErrPreconditionFailed{}, err)
+}
+
+func TestFindBookByIDNotFound(t *testing.T) {
+	// Setup
+	bookService := services.NewBookService(datasources.NewBookInMemoryDataSource(), nil, nil, logger.Discard())
+
+	// Act
+	book, err := bookService.FindByID(context.Background(), 1)
+
+	// Assert
+	assert.Nil(t, book)
+	assert.Equal(t, services.ErrNotFound{Resource: "book"}, err)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"errors"
+)
+
+type CategoryService interface {
+	FindAll(ctx context.Context) ([]*models.Category, error)
+	FindByID(ctx context.Context, id uint) (*models.Category, error)
+	FindBooks(ctx context.Context, id uint, includeDescendants bool, filter models.BookFilter) ([]*models.Book, error)
+	Create(ctx context.Context, category *models.Category, userID uint) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category, userID uint) (*models.Category, error)
+	DeleteByID(ctx context.Context, id uint, userID uint) error
+}
+
+type categoryServiceImpl struct {
+	repo           repositories.CategoryRepository
+	bookRepository repositories.BookRepository
+	userRepository repositories.UserRepository
+}
+
+// Create implements CategoryService
+func (s *categoryServiceImpl) Create(ctx context.Context, category *models.Category, userID uint) (*models.Category, error) {
+	if err := s.authorizeAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if category.ParentID != 0 {
+		if _, err := s.repo.FindByID(ctx, category.ParentID); err != nil {
+			return nil, ErrValidation{Reason: "parent category not found"}
+		}
+	}
+	return s.repo.Create(ctx, category)
+}
+
+// DeleteByID implements CategoryService
+func (s *categoryServiceImpl) DeleteByID(ctx context.Context, id uint, userID uint) error {
+	if err := s.authorizeAdmin(ctx, userID); err != nil {
+		return err
+	}
+	categories, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if c.ParentID == id {
+			return ErrConflict{Reason: "category still has child categories"}
+		}
+	}
+	it, err := s.bookRepository.Iterate(ctx, models.BookFilter{CategoryIDs: []uint{id}})
+	if err != nil {
+		return err
+	}
+	defer it.Close(ctx)
+	if it.Next(ctx) {
+		return ErrConflict{Reason: "category still has books"}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return repositoryError(s.repo.DeleteByID(ctx, id), "category")
+}
+
+// FindAll implements CategoryService
+func (s *categoryServiceImpl) FindAll(ctx context.Context) ([]*models.Category, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// FindByID implements CategoryService
+func (s *categoryServiceImpl) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "category")
+	}
+	return category, nil
+}
+
+// FindBooks implements CategoryService
+func (s *categoryServiceImpl) FindBooks(ctx context.Context, id uint, includeDescendants bool, filter models.BookFilter) ([]*models.Book, error) {
+	if _, err := s.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	filter.CategoryIDs = []uint{id}
+	if includeDescendants {
+		categories, err := s.repo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIDs = descendantCategoryIDs(categories, id)
+	}
+	return s.bookRepository.FindAll(ctx, filter)
+}
+
+// Update implements CategoryService
+func (s *categoryServiceImpl) Update(ctx context.Context, category *models.Category, userID uint) (*models.Category, error) {
+	if err := s.authorizeAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.FindByID(ctx, category.ID); err != nil {
+		return nil, err
+	}
+	if category.ParentID != 0 {
+		categories, err := s.repo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.FindByID(ctx, category.ParentID); err != nil {
+			return nil, ErrValidation{Reason: "parent category not found"}
+		}
+		for _, descendantID := range descendantCategoryIDs(categories, category.ID) {
+			if descendantID == category.ParentID {
+				return nil, ErrValidation{Reason: "category cannot be moved under itself or its descendants"}
+			}
+		}
+	}
+	updated, err := s.repo.Update(ctx, category)
+	if err != nil {
+		return nil, repositoryError(err, "category")
+	}
+	return updated, nil
+}
+
+func (s *categoryServiceImpl) authorizeAdmin(ctx context.Context, userID uint) error {
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if errors.As(err, new(repositories.ErrRecordNotFound)) {
+		// The token outlived its user.
+		return ErrUnauthenticated{}
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsAdmin {
+		return ErrForbidden{}
+	}
+	return nil
+}
+
+// descendantCategoryIDs returns id followed by the ids of every category
+// below it in the tree.
+func descendantCategoryIDs(categories []*models.Category, id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c.ID)
+	}
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
+
+func NewCategoryService(
+	repo repositories.CategoryRepository,
+	bookRepository repositories.BookRepository,
+	userRepository repositories.UserRepository,
+) CategoryService {
+	return &categoryServiceImpl{
+		repo:           repo,
+		bookRepository: bookRepository,
+		userRepository: userRepository,
+	}
+}
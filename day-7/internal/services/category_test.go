@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/internal/repositories/mocks"
+	"alterra-agmc-day-7/internal/services"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCategoryFindBooks(t *testing.T) {
+	testCases := []struct {
+		name               string
+		id                 uint
+		includeDescendants bool
+		expectedTitles     []string
+	}{
+		{
+			name:           "Test find books without descendants should only return books of the category",
+			id:             1,
+			expectedTitles: []string{"Fiction Book"},
+		},
+		{
+			name:               "Test find books with descendants should return books of the whole subtree",
+			id:                 1,
+			includeDescendants: true,
+			expectedTitles:     []string{"Fiction Book", "Fantasy Book", "Epic Fantasy Book"},
+		},
+		{
+			name:               "Test find books with descendants of a leaf should return only its books",
+			id:                 3,
+			includeDescendants: true,
+			expectedTitles:     []string{"Epic Fantasy Book"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			categoryRepository := datasources.NewCategoryInMemoryDataSource()
+			fiction, _ := categoryRepository.Create(context.TODO(), &models.Category{Name: "Fiction"})
+			fantasy, _ := categoryRepository.Create(context.TODO(), &models.Category{Name: "Fantasy", ParentID: fiction.ID})
+			epic, _ := categoryRepository.Create(context.TODO(), &models.Category{Name: "Epic", ParentID: fantasy.ID})
+			_, _ = categoryRepository.Create(context.TODO(), &models.Category{Name: "Science"})
+			bookRepository := datasources.NewBookInMemoryDataSource()
+			_, _ = bookRepository.Create(context.TODO(), &models.Book{Title: "Fiction Book", CategoryID: fiction.ID})
+			_, _ = bookRepository.Create(context.TODO(), &models.Book{Title: "Fantasy Book", CategoryID: fantasy.ID})
+			_, _ = bookRepository.Create(context.TODO(), &models.Book{Title: "Epic Fantasy Book", CategoryID: epic.ID})
+			_, _ = bookRepository.Create(context.TODO(), &models.Book{Title: "Uncategorized Book"})
+			service := services.NewCategoryService(categoryRepository, bookRepository, mocks.NewUserRepository(t))
+
+			// Act
+			books, err := service.FindBooks(context.TODO(), tc.id, tc.includeDescendants, models.BookFilter{})
+
+			// Assert
+			assert.NoError(t, err)
+			titles := make([]string, 0)
+			for _, b := range books {
+				titles = append(titles, b.Title)
+			}
+			assert.Equal(t, tc.expectedTitles, titles)
+		})
+	}
+}
+
+func TestCategoryUpdate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		user        *models.User
+		userErr     error
+		category    *models.Category
+		expectedErr error
+	}{
+		{
+			name:        "Test update category by an unknown user should return unauthenticated",
+			user:        &models.User{ID: 1},
+			userErr:     repositories.ErrRecordNotFound{},
+			category:    &models.Category{ID: 2, Name: "Fantasy", ParentID: 1},
+			expectedErr: services.ErrUnauthenticated{},
+		},
+		{
+			name:        "Test update category when the user can not be read should return the error",
+			user:        &models.User{ID: 1},
+			userErr:     errors.New("connection refused"),
+			category:    &models.Category{ID: 2, Name: "Fantasy", ParentID: 1},
+			expectedErr: errors.New("connection refused"),
+		},
+		{
+			name:        "Test update category by non admin should return forbidden",
+			user:        &models.User{ID: 1},
+			category:    &models.Category{ID: 2, Name: "Fantasy", ParentID: 1},
+			expectedErr: services.ErrForbidden{},
+		},
+		{
+			name:        "Test update category under its descendant should return invalid category",
+			user:        &models.User{ID: 1, IsAdmin: true},
+			category:    &models.Category{ID: 1, Name: "Fiction", ParentID: 2},
+			expectedErr: services.ErrValidation{Reason: "category cannot be moved under itself or its descendants"},
+		},
+		{
+			name:        "Test update category under unknown parent should return invalid category",
+			user:        &models.User{ID: 1, IsAdmin: true},
+			category:    &models.Category{ID: 2, Name: "Fantasy", ParentID: 99},
+			expectedErr: services.ErrValidation{Reason: "parent category not found"},
+		},
+		{
+			name:     "Test update category by admin should update category",
+			user:     &models.User{ID: 1, IsAdmin: true},
+			category: &models.Category{ID: 2, Name: "High Fantasy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			categoryRepository := datasources.NewCategoryInMemoryDataSource()
+			fiction, _ := categoryRepository.Create(context.TODO(), &models.Category{Name: "Fiction"})
+			_, _ = categoryRepository.Create(context.TODO(), &models.Category{Name: "Fantasy", ParentID: fiction.ID})
+			userRepository := mocks.NewUserRepository(t)
+			userRepository.On("FindByID", mock.Anything, tc.user.ID).Return(tc.user, tc.userErr)
+			service := services.NewCategoryService(categoryRepository, datasources.NewBookInMemoryDataSource(), userRepository)
+
+			// Act
+			result, err := service.Update(context.TODO(), tc.category, tc.user.ID)
+
+			// Assert
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.category.Name, result.Name)
+			}
+		})
+	}
+}
@@ -1,7 +1,70 @@
 package services
 
-type ErrUnauthorized struct{}
+import (
+	"alterra-agmc-day-7/internal/repositories"
+	"errors"
+)
 
-func (e ErrUnauthorized) Error() string {
-	return "unauthorized"
+// ErrNotFound is returned when the requested resource does not exist.
+type ErrNotFound struct {
+	Resource string
+}
+
+func (e ErrNotFound) Error() string {
+	return e.Resource + " not found"
+}
+
+// ErrUnauthenticated is returned when the caller could not be identified.
+type ErrUnauthenticated struct{}
+
+func (e ErrUnauthenticated) Error() string {
+	return "unauthenticated"
+}
+
+// ErrForbidden is returned when the caller is known but is not allowed to
+// perform the action.
+type ErrForbidden struct{}
+
+func (e ErrForbidden) Error() string {
+	return "forbidden"
+}
+
+// ErrConflict is returned when the action conflicts with the current state of
+// the resource. Code, when set, identifies the conflict for clients.
+type ErrConflict struct {
+	Code   string
+	Reason string
+}
+
+func (e ErrConflict) Error() string {
+	return e.Reason
+}
+
+// ErrValidation is returned when the input is well formed but not acceptable.
+type ErrValidation struct {
+	Reason string
+}
+
+func (e ErrValidation) Error() string {
+	return e.Reason
+}
+
+type ErrPreconditionFailed struct{}
+
+func (e ErrPreconditionFailed) Error() string {
+	return "version does not match"
+}
+
+// repositoryError translates the errors of the repositories into domain
+// errors. Not found errors are reported as ErrNotFound for resource.
+func repositoryError(err error, resource string) error {
+	var recordNotFound repositories.ErrRecordNotFound
+	var versionConflict repositories.ErrVersionConflict
+	switch {
+	case errors.As(err, &recordNotFound):
+		return ErrNotFound{Resource: resource}
+	case errors.As(err, &versionConflict):
+		return ErrPreconditionFailed{}
+	}
+	return err
 }
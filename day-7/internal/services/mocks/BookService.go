@@ -4,6 +4,7 @@ package mocks
 
 import (
 	models "alterra-agmc-day-7/internal/models"
+	services "alterra-agmc-day-7/internal/services"
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
@@ -37,13 +38,13 @@ func (_m *BookService) Create(ctx context.Context, book *models.Book) (*models.B
 	return r0, r1
 }
 
-// DeleteByID provides a mock function with given fields: ctx, id, userId
-func (_m *BookService) DeleteByID(ctx context.Context, id uint, userId uint) error {
-	ret := _m.Called(ctx, id, userId)
+// DeleteByID provides a mock function with given fields: ctx, id, userId, version
+func (_m *BookService) DeleteByID(ctx context.Context, id uint, userId uint, version uint) error {
+	ret := _m.Called(ctx, id, userId, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
-		r0 = rf(ctx, id, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) error); ok {
+		r0 = rf(ctx, id, userId, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -51,13 +52,36 @@ func (_m *BookService) DeleteByID(ctx context.Context, id uint, userId uint) err
 	return r0
 }
 
-// FindAll provides a mock function with given fields: ctx
-func (_m *BookService) FindAll(ctx context.Context) ([]*models.Book, error) {
-	ret := _m.Called(ctx)
+// DiffRevisions provides a mock function with given fields: ctx, id, from, to
+func (_m *BookService) DiffRevisions(ctx context.Context, id uint, from uint, to uint) ([]models.BookFieldChange, error) {
+	ret := _m.Called(ctx, id, from, to)
+
+	var r0 []models.BookFieldChange
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) []models.BookFieldChange); ok {
+		r0 = rf(ctx, id, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookFieldChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, uint) error); ok {
+		r1 = rf(ctx, id, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAll provides a mock function with given fields: ctx, filter
+func (_m *BookService) FindAll(ctx context.Context, filter models.BookFilter) ([]*models.Book, error) {
+	ret := _m.Called(ctx, filter)
 
 	var r0 []*models.Book
-	if rf, ok := ret.Get(0).(func(context.Context) []*models.Book); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, models.BookFilter) []*models.Book); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Book)
@@ -65,8 +89,8 @@ func (_m *BookService) FindAll(ctx context.Context) ([]*models.Book, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, models.BookFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -97,6 +121,158 @@ func (_m *BookService) FindByID(ctx context.Context, id uint) (*models.Book, err
 	return r0, r1
 }
 
+// FindRevision provides a mock function with given fields: ctx, id, revision
+func (_m *BookService) FindRevision(ctx context.Context, id uint, revision uint) (*models.BookRevision, error) {
+	ret := _m.Called(ctx, id, revision)
+
+	var r0 *models.BookRevision
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *models.BookRevision); ok {
+		r0 = rf(ctx, id, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BookRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, id, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindRevisions provides a mock function with given fields: ctx, id
+func (_m *BookService) FindRevisions(ctx context.Context, id uint) ([]*models.BookRevision, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []*models.BookRevision
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*models.BookRevision); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.BookRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Import provides a mock function with given fields: ctx, books, dryRun
+func (_m *BookService) Import(ctx context.Context, books []*models.Book, dryRun bool) ([]*services.BookImportResult, error) {
+	ret := _m.Called(ctx, books, dryRun)
+
+	var r0 []*services.BookImportResult
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.Book, bool) []*services.BookImportResult); ok {
+		r0 = rf(ctx, books, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*services.BookImportResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []*models.Book, bool) error); ok {
+		r1 = rf(ctx, books, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, book, fields
+func (_m *BookService) Patch(ctx context.Context, book *models.Book, fields []string) (*models.Book, error) {
+	ret := _m.Called(ctx, book, fields)
+
+	var r0 *models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Book, []string) *models.Book); ok {
+		r0 = rf(ctx, book, fields)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Book, []string) error); ok {
+		r1 = rf(ctx, book, fields)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id, userID
+func (_m *BookService) Restore(ctx context.Context, id uint, userID uint) (*models.Book, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 *models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *models.Book); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revert provides a mock function with given fields: ctx, id, revision, userID
+func (_m *BookService) Revert(ctx context.Context, id uint, revision uint, userID uint) (*models.Book, error) {
+	ret := _m.Called(ctx, id, revision, userID)
+
+	var r0 *models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) *models.Book); ok {
+		r0 = rf(ctx, id, revision, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, uint) error); ok {
+		r1 = rf(ctx, id, revision, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stream provides a mock function with given fields: ctx, filter, fn
+func (_m *BookService) Stream(ctx context.Context, filter models.BookFilter, fn func(*models.Book) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.BookFilter, func(*models.Book) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, book
 func (_m *BookService) Update(ctx context.Context, book *models.Book) (*models.Book, error) {
 	ret := _m.Called(ctx, book)
@@ -0,0 +1,159 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "alterra-agmc-day-7/internal/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CategoryService is an autogenerated mock type for the CategoryService type
+type CategoryService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, category, userID
+func (_m *CategoryService) Create(ctx context.Context, category *models.Category, userID uint) (*models.Category, error) {
+	ret := _m.Called(ctx, category, userID)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category, uint) *models.Category); ok {
+		r0 = rf(ctx, category, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category, uint) error); ok {
+		r1 = rf(ctx, category, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteByID provides a mock function with given fields: ctx, id, userID
+func (_m *CategoryService) DeleteByID(ctx context.Context, id uint, userID uint) error {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindAll provides a mock function with given fields: ctx
+func (_m *CategoryService) FindAll(ctx context.Context) ([]*models.Category, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*models.Category
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Category); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindBooks provides a mock function with given fields: ctx, id, includeDescendants, filter
+func (_m *CategoryService) FindBooks(ctx context.Context, id uint, includeDescendants bool, filter models.BookFilter) ([]*models.Book, error) {
+	ret := _m.Called(ctx, id, includeDescendants, filter)
+
+	var r0 []*models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uint, bool, models.BookFilter) []*models.Book); ok {
+		r0 = rf(ctx, id, includeDescendants, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, bool, models.BookFilter) error); ok {
+		r1 = rf(ctx, id, includeDescendants, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *CategoryService) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Category); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, category, userID
+func (_m *CategoryService) Update(ctx context.Context, category *models.Category, userID uint) (*models.Category, error) {
+	ret := _m.Called(ctx, category, userID)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category, uint) *models.Category); ok {
+		r0 = rf(ctx, category, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category, uint) error); ok {
+		r1 = rf(ctx, category, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewCategoryService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewCategoryService creates a new instance of CategoryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewCategoryService(t mockConstructorTestingTNewCategoryService) *CategoryService {
+	mock := &CategoryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	services "alterra-agmc-day-7/internal/services"
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TrashService is an autogenerated mock type for the TrashService type
+type TrashService struct {
+	mock.Mock
+}
+
+// FindAll provides a mock function with given fields: ctx, userID
+func (_m *TrashService) FindAll(ctx context.Context, userID uint) (*services.Trash, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 *services.Trash
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *services.Trash); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.Trash)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *TrashService) Purge(ctx context.Context, before time.Time) (*services.PurgeResult, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 *services.PurgeResult
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) *services.PurgeResult); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.PurgeResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTrashService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTrashService creates a new instance of TrashService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTrashService(t mockConstructorTestingTNewTrashService) *TrashService {
+	mock := &TrashService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -37,13 +37,13 @@ func (_m *UserService) Create(ctx context.Context, user *models.User) (*models.U
 	return r0, r1
 }
 
-// DeleteByID provides a mock function with given fields: ctx, id, userID
-func (_m *UserService) DeleteByID(ctx context.Context, id uint, userID uint) error {
-	ret := _m.Called(ctx, id, userID)
+// DeleteByID provides a mock function with given fields: ctx, id, userID, version
+func (_m *UserService) DeleteByID(ctx context.Context, id uint, userID uint, version uint) error {
+	ret := _m.Called(ctx, id, userID, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
-		r0 = rf(ctx, id, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) error); ok {
+		r0 = rf(ctx, id, userID, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -118,6 +118,52 @@ func (_m *UserService) Login(ctx context.Context, email string, password string)
 	return r0, r1
 }
 
+// Patch provides a mock function with given fields: ctx, user, fields, userID
+func (_m *UserService) Patch(ctx context.Context, user *models.User, fields []string, userID uint) (*models.User, error) {
+	ret := _m.Called(ctx, user, fields, userID)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, []string, uint) *models.User); ok {
+		r0 = rf(ctx, user, fields, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, []string, uint) error); ok {
+		r1 = rf(ctx, user, fields, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id, userID
+func (_m *UserService) Restore(ctx context.Context, id uint, userID uint) (*models.User, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *models.User); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, user, userID
 func (_m *UserService) Update(ctx context.Context, user *models.User, userID uint) (*models.User, error) {
 	ret := _m.Called(ctx, user, userID)
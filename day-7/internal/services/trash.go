@@ -0,0 +1,65 @@
+package services
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type TrashService interface {
+	FindAll(ctx context.Context, userID uint) (*Trash, error)
+	Purge(ctx context.Context, before time.Time) (*PurgeResult, error)
+}
+
+// Trash holds the soft deleted items belonging to a user.
+type Trash struct {
+	Books []*models.Book
+	Users []*models.User
+}
+
+type PurgeResult struct {
+	Books int64
+	Users int64
+}
+
+type trashServiceImpl struct {
+	bookRepository repositories.BookRepository
+	userRepository repositories.UserRepository
+}
+
+// FindAll implements TrashService
+func (s *trashServiceImpl) FindAll(ctx context.Context, userID uint) (*Trash, error) {
+	books, err := s.bookRepository.FindDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	trash := &Trash{Books: books, Users: make([]*models.User, 0)}
+	if user, err := s.userRepository.FindDeletedByID(ctx, userID); err == nil {
+		trash.Users = append(trash.Users, user)
+	}
+	return trash, nil
+}
+
+// Purge implements TrashService
+func (s *trashServiceImpl) Purge(ctx context.Context, before time.Time) (*PurgeResult, error) {
+	books, err := s.bookRepository.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepository.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeResult{Books: books, Users: users}, nil
+}
+
+func NewTrashService(
+	bookRepository repositories.BookRepository,
+	userRepository repositories.UserRepository,
+) TrashService {
+	return &trashServiceImpl{
+		bookRepository: bookRepository,
+		userRepository: userRepository,
+	}
+}
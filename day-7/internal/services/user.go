@@ -5,32 +5,64 @@ import (
 	"alterra-agmc-day-7/internal/repositories"
 	"alterra-agmc-day-7/pkg/jwt"
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
 )
 
+// ErrEmailTaken is returned when another user already registered the email.
+var ErrEmailTaken = ErrConflict{Code: "EMAIL_TAKEN", Reason: "email already registered"}
+
 type UserService interface {
 	Login(ctx context.Context, email string, password string) (string, error)
 	FindAll(ctx context.Context) ([]*models.User, error)
 	FindByID(ctx context.Context, id uint) (*models.User, error)
 	Create(ctx context.Context, user *models.User) (*models.User, error)
 	Update(ctx context.Context, user *models.User, userID uint) (*models.User, error)
-	DeleteByID(ctx context.Context, id uint, userID uint) error
+	Patch(ctx context.Context, user *models.User, fields []string, userID uint) (*models.User, error)
+	DeleteByID(ctx context.Context, id uint, userID uint, version uint) error
+	Restore(ctx context.Context, id uint, userID uint) (*models.User, error)
 }
 
 type userServiceImpl struct {
 	userRepository repositories.UserRepository
+	tokenIssuer    *jwt.Issuer
+	logger         *slog.Logger
 }
 
 // Create implements UserService
 func (s *userServiceImpl) Create(ctx context.Context, user *models.User) (*models.User, error) {
-	return s.userRepository.Create(ctx, user)
+	user.Email = normalizeEmail(user.Email)
+	if _, err := s.userRepository.FindByEmail(ctx, user.Email); err == nil {
+		return nil, ErrEmailTaken
+	}
+	created, err := s.userRepository.Create(ctx, user)
+	if err != nil {
+		return nil, userRepositoryError(err)
+	}
+	s.logger.InfoContext(ctx, "user created", "user_id", created.ID)
+	return created, nil
 }
 
 // DeleteByID implements UserService
-func (s *userServiceImpl) DeleteByID(ctx context.Context, id uint, userID uint) error {
+func (s *userServiceImpl) DeleteByID(ctx context.Context, id uint, userID uint, version uint) error {
 	if id != userID {
-		return ErrUnauthorized{}
+		return ErrForbidden{}
 	}
-	return s.userRepository.DeleteByID(ctx, id)
+	user, err := s.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !versionMatches(version, user.Version) {
+		return ErrPreconditionFailed{}
+	}
+	// The repository deletes the user only if it still has the version read,
+	// so an update made meanwhile, or a stale read, fails the precondition.
+	if err := s.userRepository.DeleteByID(ctx, id, user.Version); err != nil {
+		return repositoryError(err, "user")
+	}
+	s.logger.InfoContext(ctx, "user deleted", "user_id", id)
+	return nil
 }
 
 // FindAll implements UserService
@@ -40,37 +72,117 @@ func (s *userServiceImpl) FindAll(ctx context.Context) ([]*models.User, error) {
 
 // FindByID implements UserService
 func (s *userServiceImpl) FindByID(ctx context.Context, id uint) (*models.User, error) {
-	return s.userRepository.FindByID(ctx, id)
+	user, err := s.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "user")
+	}
+	return user, nil
 }
 
 // Update implements UserService
 func (s *userServiceImpl) Update(ctx context.Context, user *models.User, userID uint) (*models.User, error) {
+	fields := make([]string, 0, 3)
+	if user.Name != "" {
+		fields = append(fields, models.UserFieldName)
+	}
+	if user.Email != "" {
+		fields = append(fields, models.UserFieldEmail)
+	}
+	if user.Password != "" {
+		fields = append(fields, models.UserFieldPassword)
+	}
+	return s.Patch(ctx, user, fields, userID)
+}
+
+// Patch implements UserService
+func (s *userServiceImpl) Patch(ctx context.Context, user *models.User, fields []string, userID uint) (*models.User, error) {
 	if user.ID != userID {
-		return nil, ErrUnauthorized{}
+		return nil, ErrForbidden{}
+	}
+	current, err := s.FindByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !versionMatches(user.Version, current.Version) {
+		return nil, ErrPreconditionFailed{}
 	}
-	return s.userRepository.Update(ctx, user)
+	user.Version = current.Version
+	if containsString(fields, models.UserFieldEmail) {
+		user.Email = normalizeEmail(user.Email)
+		if other, err := s.userRepository.FindByEmail(ctx, user.Email); err == nil && other.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+	}
+	updated, err := s.userRepository.Update(ctx, user, fields)
+	if err != nil {
+		return nil, userRepositoryError(err)
+	}
+	return updated, nil
+}
+
+// Restore implements UserService
+func (s *userServiceImpl) Restore(ctx context.Context, id uint, userID uint) (*models.User, error) {
+	if id != userID {
+		return nil, ErrForbidden{}
+	}
+	restored, err := s.userRepository.Restore(ctx, id)
+	if err != nil {
+		return nil, repositoryError(err, "user")
+	}
+	s.logger.InfoContext(ctx, "user restored", "user_id", id)
+	return restored, nil
 }
 
 // Login implements UserService
 func (s *userServiceImpl) Login(ctx context.Context, email string, password string) (string, error) {
-	user, err := s.userRepository.FindByEmail(ctx, email)
+	user, err := s.userRepository.FindByEmail(ctx, normalizeEmail(email))
 	if err != nil {
-		return "", ErrUnauthorized{}
+		s.logger.WarnContext(ctx, "login failed", "email", email, "reason", "unknown email")
+		return "", ErrUnauthenticated{}
 	}
 	if user.Password != password {
-		return "", ErrUnauthorized{}
+		s.logger.WarnContext(ctx, "login failed", "email", email, "user_id", user.ID, "reason", "wrong password")
+		return "", ErrUnauthenticated{}
 	}
-	token, err := jwt.NewToken(user.ID)
+	token, err := s.tokenIssuer.NewToken(user.ID)
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
+// userRepositoryError translates the errors of the user repository, reporting
+// a violated unique email as ErrEmailTaken.
+func userRepositoryError(err error) error {
+	var duplicateKey repositories.ErrDuplicateKey
+	if errors.As(err, &duplicateKey) && duplicateKey.Field == models.UserFieldEmail {
+		return ErrEmailTaken
+	}
+	return repositoryError(err, "user")
+}
+
+// normalizeEmail makes emails compare case-insensitively.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func NewUserService(
 	userRepository repositories.UserRepository,
+	tokenIssuer *jwt.Issuer,
+	logger *slog.Logger,
 ) UserService {
 	return &userServiceImpl{
 		userRepository: userRepository,
+		tokenIssuer:    tokenIssuer,
+		logger:         logger,
 	}
 }
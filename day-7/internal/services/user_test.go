@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/internal/repositories/mocks"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateUserUniqueEmail(t *testing.T) {
+	testCases := []struct {
+		name             string
+		email            string
+		existingUser     *models.User
+		errReturnCreate  error
+		expectedEmail    string
+		expectedErr      error
+		expectCreateCall bool
+	}{
+		{
+			name:             "Test create user should store the email in lower case",
+			email:            " User_1@Email.com ",
+			expectedEmail:    "user_1@email.com",
+			expectCreateCall: true,
+		},
+		{
+			name:          "Test create user when email is registered in another case should return email taken",
+			email:         "USER_1@email.com",
+			existingUser:  &models.User{ID: 1, Email: "user_1@email.com"},
+			expectedEmail: "user_1@email.com",
+			expectedErr:   services.ErrEmailTaken,
+		},
+		{
+			name:             "Test create user when unique index is violated should return email taken",
+			email:            "user_1@email.com",
+			errReturnCreate:  repositories.ErrDuplicateKey{Field: models.UserFieldEmail},
+			expectedEmail:    "user_1@email.com",
+			expectedErr:      services.ErrEmailTaken,
+			expectCreateCall: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			userRepository := mocks.NewUserRepository(t)
+			if tc.existingUser != nil {
+				userRepository.On("FindByEmail", mock.Anything, tc.expectedEmail).Return(tc.existingUser, nil)
+			} else {
+				userRepository.On("FindByEmail", mock.Anything, tc.expectedEmail).Return(nil, errors.New("record not found"))
+			}
+			if tc.expectCreateCall {
+				userRepository.On("Create", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.Email == tc.expectedEmail
+				})).Return(func(ctx context.Context, user *models.User) *models.User {
+					if tc.errReturnCreate != nil {
+						return nil
+					}
+					return user
+				}, tc.errReturnCreate)
+			}
+			service := services.NewUserService(userRepository, nil, logger.Discard())
+
+			// Act
+			user, err := service.Create(context.TODO(), &models.User{Name: "user_1", Email: tc.email, Password: "password"})
+
+			// Assert
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.expectedEmail, user.Email)
+			}
+		})
+	}
+}
+
+func TestPatchUserEmailTaken(t *testing.T) {
+	// Setup
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("FindByID", mock.Anything, uint(2)).Return(&models.User{ID: 2, Email: "user_2@email.com", Version: 1}, nil)
+	userRepository.On("FindByEmail", mock.Anything, "user_1@email.com").Return(&models.User{ID: 1, Email: "user_1@email.com"}, nil)
+	service := services.NewUserService(userRepository, nil, logger.Discard())
+
+	// Act
+	_, err := service.Patch(context.TODO(), &models.User{ID: 2, Email: "User_1@email.com"}, []string{models.UserFieldEmail}, 2)
+
+	// Assert
+	assert.Equal(t, services.ErrEmailTaken, err)
+}
+
+func TestDeleteUserWithStaleRead(t *testing.T) {
+	// Setup
+	// The user is updated between the read and the delete: the repository
+	// refuses the delete at the version read.
+	repo := mocks.NewUserRepository(t)
+	repo.On("FindByID", mock.Anything, uint(1)).Return(&models.User{ID: 1, Version: 1}, nil)
+	repo.On("DeleteByID", mock.Anything, uint(1), uint(1)).Return(repositories.ErrVersionConflict{})
+	service := services.NewUserService(repo, nil, logger.Discard())
+
+	// Act
+	err := service.DeleteByID(context.TODO(), 1, 1, 0)
+
+	// Assert
+	assert.Equal(t, services.ErrPreconditionFailed{}, err)
+}
@@ -0,0 +1,67 @@
+// Package storage lets the configuration choose the backend of each store of
+// the app among the ones registered under a name.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Factory opens a backend of a store.
+type Factory[T any] func(ctx context.Context) (T, error)
+
+// ErrUnknownBackend is returned when opening a backend nobody registered.
+type ErrUnknownBackend struct {
+	Store   string
+	Backend string
+	Known   []string
+}
+
+func (e ErrUnknownBackend) Error() string {
+	return fmt.Sprintf("unknown %s backend %q, use one of %s", e.Store, e.Backend, strings.Join(e.Known, ", "))
+}
+
+// Registry holds the factories of the backends of one store, by name.
+type Registry[T any] struct {
+	store     string
+	factories map[string]Factory[T]
+}
+
+// NewRegistry creates an empty registry for store, named in errors.
+func NewRegistry[T any](store string) *Registry[T] {
+	return &Registry[T]{
+		store:     store,
+		factories: make(map[string]Factory[T]),
+	}
+}
+
+// Register makes factory available under name. Registering a name twice is a
+// programming error and panics.
+func (r *Registry[T]) Register(name string, factory Factory[T]) {
+	if _, ok := r.factories[name]; ok {
+		panic(fmt.Sprintf("storage: %s backend %q registered twice", r.store, name))
+	}
+	r.factories[name] = factory
+}
+
+// Names returns the registered backend names, sorted.
+func (r *Registry[T]) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the backend registered under name.
+func (r *Registry[T]) Open(ctx context.Context, name string) (T, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		var zero T
+		return zero, ErrUnknownBackend{Store: r.store, Backend: name, Known: r.Names()}
+	}
+	return factory(ctx)
+}
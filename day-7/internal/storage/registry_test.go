@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryOpen(t *testing.T) {
+	testCases := []struct {
+		name          string
+		backend       string
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "Test registered backend should be opened",
+			backend:  "memory",
+			expected: "memory store",
+		},
+		{
+			name:          "Test unknown backend should list the registered ones",
+			backend:       "redis",
+			expectedError: ErrUnknownBackend{Store: "book", Backend: "redis", Known: []string{"memory", "mongo"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			registry := NewRegistry[string]("book")
+			registry.Register("mongo", func(ctx context.Context) (string, error) { return "mongo store", nil })
+			registry.Register("memory", func(ctx context.Context) (string, error) { return "memory store", nil })
+
+			// Act
+			store, err := registry.Open(context.Background(), testCase.backend)
+
+			// Assert
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expected, store)
+		})
+	}
+}
+
+func TestRegistryRegisterTwice(t *testing.T) {
+	// Arrange
+	registry := NewRegistry[string]("user")
+	factory := func(ctx context.Context) (string, error) { return "", nil }
+	registry.Register("mysql", factory)
+
+	// Act & Assert
+	assert.Panics(t, func() { registry.Register("mysql", factory) })
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type bookRepository struct {
+	next repositories.BookRepository
+	spanStarter
+}
+
+// FindAll implements repositories.BookRepository
+func (t *bookRepository) FindAll(ctx context.Context, filter models.BookFilter) (result []*models.Book, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx, filter)
+}
+
+// FindByID implements repositories.BookRepository
+func (t *bookRepository) FindByID(ctx context.Context, id uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// ExistsByIsbn implements repositories.BookRepository
+func (t *bookRepository) ExistsByIsbn(ctx context.Context, isbn string) (result bool, err error) {
+	ctx, span := t.start(ctx, "ExistsByIsbn")
+	defer end(span, &err)
+	return t.next.ExistsByIsbn(ctx, isbn)
+}
+
+// Iterate implements repositories.BookRepository
+func (t *bookRepository) Iterate(ctx context.Context, filter models.BookFilter) (result repositories.BookIterator, err error) {
+	ctx, span := t.start(ctx, "Iterate")
+	defer end(span, &err)
+	return t.next.Iterate(ctx, filter)
+}
+
+// Create implements repositories.BookRepository
+func (t *bookRepository) Create(ctx context.Context, book *models.Book) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, book)
+}
+
+// DeleteByID implements repositories.BookRepository
+func (t *bookRepository) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id, version)
+}
+
+// Update implements repositories.BookRepository
+func (t *bookRepository) Update(ctx context.Context, book *models.Book, fields []string) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, book, fields)
+}
+
+// FindDeleted implements repositories.BookRepository
+func (t *bookRepository) FindDeleted(ctx context.Context, userID uint) (result []*models.Book, err error) {
+	ctx, span := t.start(ctx, "FindDeleted")
+	defer end(span, &err)
+	return t.next.FindDeleted(ctx, userID)
+}
+
+// FindDeletedByID implements repositories.BookRepository
+func (t *bookRepository) FindDeletedByID(ctx context.Context, id uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "FindDeletedByID")
+	defer end(span, &err)
+	return t.next.FindDeletedByID(ctx, id)
+}
+
+// Restore implements repositories.BookRepository
+func (t *bookRepository) Restore(ctx context.Context, id uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer end(span, &err)
+	return t.next.Restore(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.BookRepository
+func (t *bookRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (result int64, err error) {
+	ctx, span := t.start(ctx, "PurgeDeletedBefore")
+	defer end(span, &err)
+	return t.next.PurgeDeletedBefore(ctx, before)
+}
+
+// BookRepository records every operation of next, a repository backed by
+// datasource, as a span.
+func (t *Tracing) BookRepository(next repositories.BookRepository, datasource string) repositories.BookRepository {
+	return &bookRepository{next: next, spanStarter: spanStarter{tracing: t, component: "BookRepository", attributes: datasourceAttributes(datasource)}}
+}
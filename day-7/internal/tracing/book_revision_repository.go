@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+)
+
+type bookRevisionRepository struct {
+	next repositories.BookRevisionRepository
+	spanStarter
+}
+
+// Create implements repositories.BookRevisionRepository
+func (t *bookRevisionRepository) Create(ctx context.Context, revision *models.BookRevision) (result *models.BookRevision, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, revision)
+}
+
+// FindByBookID implements repositories.BookRevisionRepository
+func (t *bookRevisionRepository) FindByBookID(ctx context.Context, bookID uint) (result []*models.BookRevision, err error) {
+	ctx, span := t.start(ctx, "FindByBookID")
+	defer end(span, &err)
+	return t.next.FindByBookID(ctx, bookID)
+}
+
+// FindByRevision implements repositories.BookRevisionRepository
+func (t *bookRevisionRepository) FindByRevision(ctx context.Context, bookID uint, revision uint) (result *models.BookRevision, err error) {
+	ctx, span := t.start(ctx, "FindByRevision")
+	defer end(span, &err)
+	return t.next.FindByRevision(ctx, bookID, revision)
+}
+
+// BookRevisionRepository records every operation of next, a repository backed by
+// datasource, as a span.
+func (t *Tracing) BookRevisionRepository(next repositories.BookRevisionRepository, datasource string) repositories.BookRevisionRepository {
+	return &bookRevisionRepository{next: next, spanStarter: spanStarter{tracing: t, component: "BookRevisionRepository", attributes: datasourceAttributes(datasource)}}
+}
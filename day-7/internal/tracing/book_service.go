@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"context"
+)
+
+type bookService struct {
+	next services.BookService
+	spanStarter
+}
+
+// FindAll implements services.BookService
+func (t *bookService) FindAll(ctx context.Context, filter models.BookFilter) (result []*models.Book, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx, filter)
+}
+
+// Stream implements services.BookService
+func (t *bookService) Stream(ctx context.Context, filter models.BookFilter, fn func(book *models.Book) error) (err error) {
+	ctx, span := t.start(ctx, "Stream")
+	defer end(span, &err)
+	return t.next.Stream(ctx, filter, fn)
+}
+
+// FindByID implements services.BookService
+func (t *bookService) FindByID(ctx context.Context, id uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// Create implements services.BookService
+func (t *bookService) Create(ctx context.Context, book *models.Book) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, book)
+}
+
+// DeleteByID implements services.BookService
+func (t *bookService) DeleteByID(ctx context.Context, id uint, userID uint, version uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id, userID, version)
+}
+
+// Update implements services.BookService
+func (t *bookService) Update(ctx context.Context, book *models.Book) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, book)
+}
+
+// Patch implements services.BookService
+func (t *bookService) Patch(ctx context.Context, book *models.Book, fields []string) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Patch")
+	defer end(span, &err)
+	return t.next.Patch(ctx, book, fields)
+}
+
+// Import implements services.BookService
+func (t *bookService) Import(ctx context.Context, books []*models.Book, dryRun bool) (result []*services.BookImportResult, err error) {
+	ctx, span := t.start(ctx, "Import")
+	defer end(span, &err)
+	return t.next.Import(ctx, books, dryRun)
+}
+
+// Restore implements services.BookService
+func (t *bookService) Restore(ctx context.Context, id uint, userID uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer end(span, &err)
+	return t.next.Restore(ctx, id, userID)
+}
+
+// FindRevisions implements services.BookService
+func (t *bookService) FindRevisions(ctx context.Context, id uint) (result []*models.BookRevision, err error) {
+	ctx, span := t.start(ctx, "FindRevisions")
+	defer end(span, &err)
+	return t.next.FindRevisions(ctx, id)
+}
+
+// FindRevision implements services.BookService
+func (t *bookService) FindRevision(ctx context.Context, id uint, revision uint) (result *models.BookRevision, err error) {
+	ctx, span := t.start(ctx, "FindRevision")
+	defer end(span, &err)
+	return t.next.FindRevision(ctx, id, revision)
+}
+
+// DiffRevisions implements services.BookService
+func (t *bookService) DiffRevisions(ctx context.Context, id uint, from uint, to uint) (result []models.BookFieldChange, err error) {
+	ctx, span := t.start(ctx, "DiffRevisions")
+	defer end(span, &err)
+	return t.next.DiffRevisions(ctx, id, from, to)
+}
+
+// Revert implements services.BookService
+func (t *bookService) Revert(ctx context.Context, id uint, revision uint, userID uint) (result *models.Book, err error) {
+	ctx, span := t.start(ctx, "Revert")
+	defer end(span, &err)
+	return t.next.Revert(ctx, id, revision, userID)
+}
+
+// BookService records every call of next as a span.
+func (t *Tracing) BookService(next services.BookService) services.BookService {
+	return &bookService{next: next, spanStarter: spanStarter{tracing: t, component: "BookService"}}
+}
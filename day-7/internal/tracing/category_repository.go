@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+)
+
+type categoryRepository struct {
+	next repositories.CategoryRepository
+	spanStarter
+}
+
+// FindAll implements repositories.CategoryRepository
+func (t *categoryRepository) FindAll(ctx context.Context) (result []*models.Category, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx)
+}
+
+// FindByID implements repositories.CategoryRepository
+func (t *categoryRepository) FindByID(ctx context.Context, id uint) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// Create implements repositories.CategoryRepository
+func (t *categoryRepository) Create(ctx context.Context, category *models.Category) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, category)
+}
+
+// Update implements repositories.CategoryRepository
+func (t *categoryRepository) Update(ctx context.Context, category *models.Category) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, category)
+}
+
+// DeleteByID implements repositories.CategoryRepository
+func (t *categoryRepository) DeleteByID(ctx context.Context, id uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id)
+}
+
+// CategoryRepository records every operation of next, a repository backed by
+// datasource, as a span.
+func (t *Tracing) CategoryRepository(next repositories.CategoryRepository, datasource string) repositories.CategoryRepository {
+	return &categoryRepository{next: next, spanStarter: spanStarter{tracing: t, component: "CategoryRepository", attributes: datasourceAttributes(datasource)}}
+}
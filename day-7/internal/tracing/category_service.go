@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"context"
+)
+
+type categoryService struct {
+	next services.CategoryService
+	spanStarter
+}
+
+// FindAll implements services.CategoryService
+func (t *categoryService) FindAll(ctx context.Context) (result []*models.Category, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx)
+}
+
+// FindByID implements services.CategoryService
+func (t *categoryService) FindByID(ctx context.Context, id uint) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// FindBooks implements services.CategoryService
+func (t *categoryService) FindBooks(ctx context.Context, id uint, includeDescendants bool, filter models.BookFilter) (result []*models.Book, err error) {
+	ctx, span := t.start(ctx, "FindBooks")
+	defer end(span, &err)
+	return t.next.FindBooks(ctx, id, includeDescendants, filter)
+}
+
+// Create implements services.CategoryService
+func (t *categoryService) Create(ctx context.Context, category *models.Category, userID uint) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, category, userID)
+}
+
+// Update implements services.CategoryService
+func (t *categoryService) Update(ctx context.Context, category *models.Category, userID uint) (result *models.Category, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, category, userID)
+}
+
+// DeleteByID implements services.CategoryService
+func (t *categoryService) DeleteByID(ctx context.Context, id uint, userID uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id, userID)
+}
+
+// CategoryService records every call of next as a span.
+func (t *Tracing) CategoryService(next services.CategoryService) services.CategoryService {
+	return &categoryService{next: next, spanStarter: spanStarter{tracing: t, component: "CategoryService"}}
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxMongoStatementLength keeps large inserts from bloating spans.
+const maxMongoStatementLength = 4096
+
+// MongoCommandMonitor returns a command monitor recording every command sent
+// to MongoDB as a span carrying the shape of the command as db.statement: its
+// keys and operators, with every value replaced by ?, so that no data of the
+// documents ends in traces. Set it on the client options before connecting.
+func (t *Tracing) MongoCommandMonitor() *event.CommandMonitor {
+	var spans sync.Map
+	finish := func(requestID int64, failure string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failure != "" {
+			span.SetStatus(codes.Error, failure)
+		}
+		span.End()
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			var statement strings.Builder
+			writeMongoShape(&statement, e.Command)
+			attributes := []attribute.KeyValue{
+				semconv.DBSystemMongoDB,
+				semconv.DBNameKey.String(e.DatabaseName),
+				semconv.DBOperationKey.String(e.CommandName),
+				semconv.DBStatementKey.String(truncate(statement.String(), maxMongoStatementLength)),
+			}
+			// The command names the collection it runs on, when it has one.
+			if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+				attributes = append(attributes, semconv.DBMongoDBCollectionKey.String(collection))
+			}
+			_, span := t.Start(ctx, "mongo."+e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes...),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, "")
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.Failure)
+		},
+	}
+}
+
+// writeMongoShape writes the keys of document to b, nested documents
+// included, with ? in place of every value. An array is written as the shape
+// of its first element, documents in an insert being alike.
+func writeMongoShape(b *strings.Builder, document bson.Raw) {
+	elements, err := document.Elements()
+	if err != nil {
+		b.WriteString("?")
+		return
+	}
+	b.WriteString("{")
+	for i, element := range elements {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(element.Key())
+		b.WriteString(": ")
+		writeMongoValueShape(b, element.Value())
+	}
+	b.WriteString("}")
+}
+
+func writeMongoValueShape(b *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		writeMongoShape(b, value.Document())
+	case bsontype.Array:
+		b.WriteString("[")
+		if values, err := value.Array().Values(); err == nil && len(values) > 0 {
+			writeMongoValueShape(b, values[0])
+		}
+		b.WriteString("]")
+	default:
+		b.WriteString("?")
+	}
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
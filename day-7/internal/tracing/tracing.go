@@ -0,0 +1,133 @@
+// Package tracing sets up OpenTelemetry tracing and wraps the services and
+// repositories of the app so every call is recorded as a span.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "alterra-agmc-day-7/internal/tracing"
+
+// Exporters supported by NewExporter.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+	ExporterMemory = "memory"
+)
+
+// NewExporter creates the span exporter named kind. The OTLP exporter sends
+// spans over HTTP and is configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables. ExporterNone returns a nil exporter.
+func NewExporter(ctx context.Context, kind string) (sdktrace.SpanExporter, error) {
+	switch kind {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New()
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case ExporterMemory:
+		return tracetest.NewInMemoryExporter(), nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", kind)
+	}
+}
+
+// NewProvider creates a tracer provider for serviceName sending its spans to
+// exporter in batches. A nil exporter records nothing.
+func NewProvider(serviceName string, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+		)),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Tracing starts the spans of the app and propagates them across process
+// boundaries with W3C trace context headers.
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New creates a Tracing recording to provider. It also installs provider and
+// the W3C propagator as the global ones, used by libraries instrumenting
+// themselves.
+func New(provider trace.TracerProvider) *Tracing {
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return &Tracing{
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagator,
+	}
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func (t *Tracing) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, opts...)
+}
+
+// Extract returns ctx with the remote span found in carrier, such as the
+// headers of an incoming request.
+func (t *Tracing) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return t.propagator.Extract(ctx, carrier)
+}
+
+// Inject writes the span of ctx into carrier, such as the headers of a
+// response or an outgoing request.
+func (t *Tracing) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	t.propagator.Inject(ctx, carrier)
+}
+
+// spanStarter starts the span of one call of a wrapped service or repository.
+type spanStarter struct {
+	tracing    *Tracing
+	component  string
+	attributes []attribute.KeyValue
+}
+
+func (s spanStarter) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return s.tracing.Start(ctx, s.component+"."+operation, trace.WithAttributes(s.attributes...))
+}
+
+// end ends span, marking it failed when *err is not nil. It is meant to be
+// deferred with a pointer to a named result.
+func end(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// datasourceAttributes describes the database system behind a repository.
+func datasourceAttributes(datasource string) []attribute.KeyValue {
+	switch datasource {
+	case "mongo":
+		return []attribute.KeyValue{semconv.DBSystemMongoDB}
+	case "mysql":
+		return []attribute.KeyValue{semconv.DBSystemMySQL}
+	default:
+		return []attribute.KeyValue{semconv.DBSystemKey.String(datasource)}
+	}
+}
@@ -0,0 +1,160 @@
+package tracing_test
+
+import (
+	"alterra-agmc-day-7/internal/datasources"
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/tracing"
+	"alterra-agmc-day-7/internal/transportlayers/http/middlewares"
+	"alterra-agmc-day-7/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func TestTracingFromHandlerToDatasource(t *testing.T) {
+	// Setup
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tr := tracing.New(provider)
+	bookRepository := tr.BookRepository(datasources.NewBookInMemoryDataSource(), "memory")
+	_, _ = bookRepository.Create(context.TODO(), &models.Book{Title: "Test Book"})
+	exporter.Reset()
+	bookService := tr.BookService(services.NewBookService(
+		bookRepository,
+		datasources.NewCategoryInMemoryDataSource(),
+		datasources.NewBookRevisionInMemoryDataSource(),
+		logger.Discard(),
+	))
+	e := echo.New()
+	e.Use(middlewares.Tracing(tr))
+	e.GET("/books/:id", func(c echo.Context) error {
+		id, _ := strconv.Atoi(c.Param("id"))
+		if _, err := bookService.FindByID(c.Request().Context(), uint(id)); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	// Act
+	e.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	server, ok := spans["GET /books/:id"]
+	assert.True(t, ok)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Contains(t, rec.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	service, ok := spans["BookService.FindByID"]
+	assert.True(t, ok)
+	assert.Equal(t, server.SpanContext.SpanID(), service.Parent.SpanID())
+
+	repository, ok := spans["BookRepository.FindByID"]
+	assert.True(t, ok)
+	assert.Equal(t, service.SpanContext.SpanID(), repository.Parent.SpanID())
+	assert.Contains(t, repository.Attributes, attribute.String("db.system", "memory"))
+}
+
+func TestServicesRecordSpans(t *testing.T) {
+	// Setup
+	exporter := tracetest.NewInMemoryExporter()
+	tr := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	bookRepository := tr.BookRepository(datasources.NewBookInMemoryDataSource(), "memory")
+	userRepository := datasources.NewUserInMemoryDataSource()
+	categoryService := tr.CategoryService(services.NewCategoryService(datasources.NewCategoryInMemoryDataSource(), bookRepository, userRepository))
+	trashService := tr.TrashService(services.NewTrashService(bookRepository, userRepository))
+
+	// Act
+	_, categoryErr := categoryService.FindAll(context.TODO())
+	_, trashErr := trashService.FindAll(context.TODO(), 1)
+
+	// Assert
+	assert.NoError(t, categoryErr)
+	assert.NoError(t, trashErr)
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	for _, name := range []string{"CategoryService.FindAll", "TrashService.FindAll"} {
+		_, ok := spans[name]
+		assert.True(t, ok, "%s should have been recorded", name)
+	}
+}
+
+func TestGormPluginNamesSpansAfterDialect(t *testing.T) {
+	// Setup
+	exporter := tracetest.NewInMemoryExporter()
+	tr := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Discard})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(tr.GormPlugin()))
+
+	// Act
+	var one int
+	err = db.WithContext(context.TODO()).Raw("SELECT ?", 1).Scan(&one).Error
+
+	// Assert
+	assert.NoError(t, err)
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "sqlite.row", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.system", "sqlite"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.statement", "SELECT ?"))
+}
+
+func TestMongoCommandMonitorRecordsCommandShape(t *testing.T) {
+	// Setup
+	exporter := tracetest.NewInMemoryExporter()
+	tr := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	monitor := tr.MongoCommandMonitor()
+	command, err := bson.Marshal(bson.D{
+		{Key: "update", Value: "books"},
+		{Key: "updates", Value: bson.A{
+			bson.D{
+				{Key: "q", Value: bson.D{{Key: "_id", Value: 1}, {Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{2, 3}}}}}},
+				{Key: "u", Value: bson.D{{Key: "$set", Value: bson.D{{Key: "title", Value: "Secret Title"}}}}},
+			},
+		}},
+		{Key: "ordered", Value: true},
+	})
+	assert.NoError(t, err)
+
+	// Act
+	monitor.Started(context.TODO(), &event.CommandStartedEvent{Command: command, DatabaseName: "library", CommandName: "update", RequestID: 1})
+	monitor.Succeeded(context.TODO(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "update", RequestID: 1}})
+
+	// Assert
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "mongo.update", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.mongodb.collection", "books"))
+	assert.Contains(t, spans[0].Attributes, attribute.String(
+		"db.statement",
+		"{update: ?, updates: [{q: {_id: ?, version: {$in: [?]}}, u: {$set: {title: ?}}}], ordered: ?}",
+	))
+}
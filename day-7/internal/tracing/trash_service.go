@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"context"
+	"time"
+)
+
+type trashService struct {
+	next services.TrashService
+	spanStarter
+}
+
+// FindAll implements services.TrashService
+func (t *trashService) FindAll(ctx context.Context, userID uint) (result *services.Trash, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx, userID)
+}
+
+// Purge implements services.TrashService
+func (t *trashService) Purge(ctx context.Context, before time.Time) (result *services.PurgeResult, err error) {
+	ctx, span := t.start(ctx, "Purge")
+	defer end(span, &err)
+	return t.next.Purge(ctx, before)
+}
+
+// TrashService records every call of next as a span.
+func (t *Tracing) TrashService(next services.TrashService) services.TrashService {
+	return &trashService{next: next, spanStarter: spanStarter{tracing: t, component: "TrashService"}}
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"context"
+	"time"
+)
+
+type userRepository struct {
+	next repositories.UserRepository
+	spanStarter
+}
+
+// FindAll implements repositories.UserRepository
+func (t *userRepository) FindAll(ctx context.Context) (result []*models.User, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx)
+}
+
+// FindByID implements repositories.UserRepository
+func (t *userRepository) FindByID(ctx context.Context, id uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// FindByEmail implements repositories.UserRepository
+func (t *userRepository) FindByEmail(ctx context.Context, email string) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "FindByEmail")
+	defer end(span, &err)
+	return t.next.FindByEmail(ctx, email)
+}
+
+// Create implements repositories.UserRepository
+func (t *userRepository) Create(ctx context.Context, user *models.User) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, user)
+}
+
+// Update implements repositories.UserRepository
+func (t *userRepository) Update(ctx context.Context, user *models.User, fields []string) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, user, fields)
+}
+
+// DeleteByID implements repositories.UserRepository
+func (t *userRepository) DeleteByID(ctx context.Context, id uint, version uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id, version)
+}
+
+// FindDeletedByID implements repositories.UserRepository
+func (t *userRepository) FindDeletedByID(ctx context.Context, id uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "FindDeletedByID")
+	defer end(span, &err)
+	return t.next.FindDeletedByID(ctx, id)
+}
+
+// Restore implements repositories.UserRepository
+func (t *userRepository) Restore(ctx context.Context, id uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer end(span, &err)
+	return t.next.Restore(ctx, id)
+}
+
+// PurgeDeletedBefore implements repositories.UserRepository
+func (t *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (result int64, err error) {
+	ctx, span := t.start(ctx, "PurgeDeletedBefore")
+	defer end(span, &err)
+	return t.next.PurgeDeletedBefore(ctx, before)
+}
+
+// UserRepository records every operation of next, a repository backed by
+// datasource, as a span.
+func (t *Tracing) UserRepository(next repositories.UserRepository, datasource string) repositories.UserRepository {
+	return &userRepository{next: next, spanStarter: spanStarter{tracing: t, component: "UserRepository", attributes: datasourceAttributes(datasource)}}
+}
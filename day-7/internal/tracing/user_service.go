@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"context"
+)
+
+type userService struct {
+	next services.UserService
+	spanStarter
+}
+
+// Login implements services.UserService
+func (t *userService) Login(ctx context.Context, email string, password string) (result string, err error) {
+	ctx, span := t.start(ctx, "Login")
+	defer end(span, &err)
+	return t.next.Login(ctx, email, password)
+}
+
+// FindAll implements services.UserService
+func (t *userService) FindAll(ctx context.Context) (result []*models.User, err error) {
+	ctx, span := t.start(ctx, "FindAll")
+	defer end(span, &err)
+	return t.next.FindAll(ctx)
+}
+
+// FindByID implements services.UserService
+func (t *userService) FindByID(ctx context.Context, id uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "FindByID")
+	defer end(span, &err)
+	return t.next.FindByID(ctx, id)
+}
+
+// Create implements services.UserService
+func (t *userService) Create(ctx context.Context, user *models.User) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Create")
+	defer end(span, &err)
+	return t.next.Create(ctx, user)
+}
+
+// Update implements services.UserService
+func (t *userService) Update(ctx context.Context, user *models.User, userID uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Update")
+	defer end(span, &err)
+	return t.next.Update(ctx, user, userID)
+}
+
+// Patch implements services.UserService
+func (t *userService) Patch(ctx context.Context, user *models.User, fields []string, userID uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Patch")
+	defer end(span, &err)
+	return t.next.Patch(ctx, user, fields, userID)
+}
+
+// DeleteByID implements services.UserService
+func (t *userService) DeleteByID(ctx context.Context, id uint, userID uint, version uint) (err error) {
+	ctx, span := t.start(ctx, "DeleteByID")
+	defer end(span, &err)
+	return t.next.DeleteByID(ctx, id, userID, version)
+}
+
+// Restore implements services.UserService
+func (t *userService) Restore(ctx context.Context, id uint, userID uint) (result *models.User, err error) {
+	ctx, span := t.start(ctx, "Restore")
+	defer end(span, &err)
+	return t.next.Restore(ctx, id, userID)
+}
+
+// UserService records every call of next as a span.
+func (t *Tracing) UserService(next services.UserService) services.UserService {
+	return &userService{next: next, spanStarter: spanStarter{tracing: t, component: "UserService"}}
+}
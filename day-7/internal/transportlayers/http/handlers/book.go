@@ -5,8 +5,10 @@ import (
 	"alterra-agmc-day-7/internal/services"
 	"alterra-agmc-day-7/internal/transportlayers/http/request"
 	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -16,11 +18,22 @@ type BookHandler interface {
 	GetByID(c echo.Context) error
 	Delete(c echo.Context) error
 	Update(c echo.Context) error
+	Patch(c echo.Context) error
 	Create(c echo.Context) error
+	Import(c echo.Context) error
+	Export(c echo.Context) error
+	Restore(c echo.Context) error
+	GetRevisions(c echo.Context) error
+	GetRevision(c echo.Context) error
+	DiffRevisions(c echo.Context) error
+	Revert(c echo.Context) error
 }
 
 type bookHandlerImpl struct {
 	service services.BookService
+	// importMaxSize is how many bytes an imported file may have.
+	importMaxSize int64
+	logger        *slog.Logger
 }
 
 // Create implements BookHandler
@@ -31,48 +44,27 @@ func (h *bookHandlerImpl) Create(c echo.Context) error {
 	}
 	var requestBody request.CreateBookRequest
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidBody(err)
 	}
 	if err := c.Validate(requestBody); err != nil {
-		switch err := err.(type) {
-		case *echo.HTTPError:
-			return c.JSON(err.Code, err.Message)
-		default:
-			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Code:    "BAD_REQUEST",
-				Message: err.Error(),
-			})
-		}
+		return err
 	}
 	book, err := h.service.Create(
 		c.Request().Context(),
 		&models.Book{
-			Title:  requestBody.Title,
-			Isbn:   requestBody.Isbn,
-			Writer: requestBody.Writer,
-			UserID: uid,
+			Title:      requestBody.Title,
+			Isbn:       requestBody.Isbn,
+			Writer:     requestBody.Writer,
+			CategoryID: requestBody.CategoryID,
+			Tags:       requestBody.Tags,
+			UserID:     uid,
 		},
 	)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
-	}
-	bookResponse := response.BookResponse{
-		ID:        book.ID,
-		Title:     book.Title,
-		Writer:    book.Writer,
-		Isbn:      book.Isbn,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
+		return err
 	}
+	bookResponse := newBookResponse(book)
+	setETag(c, book.Version)
 
 	return c.JSON(http.StatusCreated, response.SuccessResponse[response.BookResponse]{
 		Status: http.StatusCreated,
@@ -89,18 +81,14 @@ func (h *bookHandlerImpl) Delete(c echo.Context) error {
 	bookIdParam := c.Param("id")
 	bookId, err := strconv.Atoi(bookIdParam)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
-	if err := h.service.DeleteByID(c.Request().Context(), uid, uint(bookId)); err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
+	version, err := ifMatchVersion(c, h.currentVersion(c, uint(bookId)))
+	if err != nil {
+		return err
+	}
+	if err := h.service.DeleteByID(c.Request().Context(), uint(bookId), uid, version); err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, response.SuccessResponse[any]{
 		Status: http.StatusOK,
@@ -110,24 +98,17 @@ func (h *bookHandlerImpl) Delete(c echo.Context) error {
 
 // GetAll implements BookHandler
 func (h *bookHandlerImpl) GetAll(c echo.Context) error {
-	books, err := h.service.FindAll(c.Request().Context())
+	var requestQuery request.ListBookRequest
+	if err := c.Bind(&requestQuery); err != nil {
+		return invalidQuery(err)
+	}
+	books, err := h.service.FindAll(c.Request().Context(), bookFilterFromRequest(requestQuery))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
+		return err
 	}
 	booksResponse := []response.BookResponse{}
 	for _, b := range books {
-		bookResponse := response.BookResponse{
-			ID:        b.ID,
-			Title:     b.Title,
-			Writer:    b.Writer,
-			Isbn:      b.Isbn,
-			CreatedAt: b.CreatedAt,
-			UpdatedAt: b.UpdatedAt,
-		}
+		bookResponse := newBookResponse(b)
 		booksResponse = append(booksResponse, bookResponse)
 	}
 	return c.JSON(http.StatusOK, response.SuccessResponse[[]response.BookResponse]{
@@ -141,28 +122,15 @@ func (h *bookHandlerImpl) GetByID(c echo.Context) error {
 	strId := c.Param("id")
 	id, err := strconv.Atoi(strId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
 	book, err := h.service.FindByID(c.Request().Context(), uint(id))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"status":  "INTERNAL_SERVER_ERROR",
-			"message": err.Error(),
-		})
+		return err
 	}
 
-	bookResponse := response.BookResponse{
-		ID:        book.ID,
-		Title:     book.Title,
-		Writer:    book.Writer,
-		Isbn:      book.Isbn,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
-	}
+	bookResponse := newBookResponse(book)
+	setETag(c, book.Version)
 
 	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookResponse]{
 		Status: http.StatusOK,
@@ -178,58 +146,38 @@ func (h *bookHandlerImpl) Update(c echo.Context) error {
 	}
 	var requestBody request.UpdateBookRequest
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidBody(err)
 	}
 	strId := c.Param("id")
 	id, err := strconv.Atoi(strId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
 	if err := c.Validate(requestBody); err != nil {
-		switch err := err.(type) {
-		case *echo.HTTPError:
-			return c.JSON(err.Code, err.Message)
-		default:
-			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Code:    "BAD_REQUEST",
-				Message: err.Error(),
-			})
-		}
+		return err
+	}
+	version, err := ifMatchVersion(c, h.currentVersion(c, uint(id)))
+	if err != nil {
+		return err
 	}
 	book, err := h.service.Update(
 		c.Request().Context(),
 		&models.Book{
-			ID:     uint(id),
-			Title:  requestBody.Title,
-			Isbn:   requestBody.Isbn,
-			Writer: requestBody.Writer,
-			UserID: uid,
+			ID:         uint(id),
+			Title:      requestBody.Title,
+			Isbn:       requestBody.Isbn,
+			Writer:     requestBody.Writer,
+			CategoryID: requestBody.CategoryID,
+			Tags:       requestBody.Tags,
+			Version:    version,
+			UserID:     uid,
 		},
 	)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
-	}
-	bookResponse := response.BookResponse{
-		ID:        book.ID,
-		Title:     book.Title,
-		Writer:    book.Writer,
-		Isbn:      book.Isbn,
-		CreatedAt: book.CreatedAt,
-		UpdatedAt: book.UpdatedAt,
+		return err
 	}
+	bookResponse := newBookResponse(book)
+	setETag(c, book.Version)
 
 	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookResponse]{
 		Status: http.StatusOK,
@@ -237,8 +185,143 @@ func (h *bookHandlerImpl) Update(c echo.Context) error {
 	})
 }
 
-func NewBookHandler(bookService services.BookService) BookHandler {
+// Patch implements BookHandler
+func (h *bookHandlerImpl) Patch(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	patch, fields, err := readMergePatch(c, models.BookFields...)
+	if err != nil {
+		return err
+	}
+	current, err := h.service.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		return err
+	}
+	version, err := ifMatchVersion(c, func() (uint, error) { return current.Version, nil })
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		version = current.Version
+	}
+	var requestBody request.PatchBookRequest
+	err = applyMergePatch(request.PatchBookRequest{
+		Title:      current.Title,
+		Isbn:       current.Isbn,
+		Writer:     current.Writer,
+		CategoryID: current.CategoryID,
+		Tags:       current.Tags,
+	}, patch, &requestBody)
+	if err != nil {
+		return err
+	}
+	if err := c.Validate(requestBody); err != nil {
+		return err
+	}
+	book, err := h.service.Patch(
+		c.Request().Context(),
+		&models.Book{
+			ID:         uint(id),
+			Title:      requestBody.Title,
+			Isbn:       requestBody.Isbn,
+			Writer:     requestBody.Writer,
+			CategoryID: requestBody.CategoryID,
+			Tags:       requestBody.Tags,
+			Version:    version,
+			UserID:     uid,
+		},
+		fields,
+	)
+	if err != nil {
+		return err
+	}
+	setETag(c, book.Version)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookResponse]{
+		Status: http.StatusOK,
+		Data:   newBookResponse(book),
+	})
+}
+
+// Restore implements BookHandler
+func (h *bookHandlerImpl) Restore(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	book, err := h.service.Restore(c.Request().Context(), uint(id), uid)
+	if err != nil {
+		return err
+	}
+	setETag(c, book.Version)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookResponse]{
+		Status: http.StatusOK,
+		Data:   newBookResponse(book),
+	})
+}
+
+func bookFilterFromRequest(requestQuery request.ListBookRequest) models.BookFilter {
+	filter := models.BookFilter{
+		UserID: requestQuery.UserID,
+		Title:  requestQuery.Title,
+		Writer: requestQuery.Writer,
+		Isbn:   requestQuery.Isbn,
+	}
+	if requestQuery.CategoryID != 0 {
+		filter.CategoryIDs = []uint{requestQuery.CategoryID}
+	}
+	for _, tag := range requestQuery.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	return filter
+}
+
+func newBookResponse(book *models.Book) response.BookResponse {
+	tags := book.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return response.BookResponse{
+		ID:         book.ID,
+		Title:      book.Title,
+		Writer:     book.Writer,
+		Isbn:       book.Isbn,
+		CategoryID: book.CategoryID,
+		Tags:       tags,
+		CreatedAt:  book.CreatedAt,
+		UpdatedAt:  book.UpdatedAt,
+		DeletedAt:  book.DeletedAt,
+		Version:    book.Version,
+	}
+}
+
+func NewBookHandler(bookService services.BookService, importMaxSize int64, logger *slog.Logger) BookHandler {
 	return &bookHandlerImpl{
-		service: bookService,
+		service:       bookService,
+		importMaxSize: importMaxSize,
+		logger:        logger,
+	}
+}
+
+// currentVersion returns a function reading the version of the book of id,
+// for ifMatchVersion to resolve an If-Match header listing several.
+func (h *bookHandlerImpl) currentVersion(c echo.Context, id uint) func() (uint, error) {
+	return func() (uint, error) {
+		book, err := h.service.FindByID(c.Request().Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return book.Version, nil
 	}
 }
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/transportlayers/http/request"
+	"alterra-agmc-day-7/pkg/xlsx"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bookExportFlushEvery controls how many rows are written before the
+// response is flushed to the client.
+const bookExportFlushEvery = 100
+
+var bookExportHeader = []string{"id", "title", "isbn", "writer", "user_id", "created_at", "updated_at"}
+
+// bookRowWriter writes exported books in one of the supported formats.
+type bookRowWriter interface {
+	Write(book *models.Book) error
+	Flush() error
+	Close() error
+}
+
+// Export implements BookHandler
+func (h *bookHandlerImpl) Export(c echo.Context) error {
+	var requestQuery request.ExportBookRequest
+	if err := c.Bind(&requestQuery); err != nil {
+		return invalidQuery(err)
+	}
+	if err := c.Validate(requestQuery); err != nil {
+		return err
+	}
+
+	var writer bookRowWriter
+	var contentType string
+	res := c.Response()
+	switch requestQuery.Format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+		writer = newBookCSVWriter(res)
+	case "ndjson":
+		contentType = "application/x-ndjson"
+		writer = newBookNDJSONWriter(res)
+	case "xlsx":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		writer = &lazyBookXLSXWriter{res: res}
+	}
+	fileName := fmt.Sprintf("books-%s.%s", time.Now().UTC().Format("20060102T150405Z"), requestQuery.Format)
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+
+	// Once the first row is written the status can no longer change, so errors
+	// happening mid stream only abort the response.
+	rows := 0
+	err := h.service.Stream(c.Request().Context(), bookFilterFromRequest(requestQuery.ListBookRequest), func(book *models.Book) error {
+		if rows == 0 {
+			res.WriteHeader(http.StatusOK)
+		}
+		if err := writer.Write(book); err != nil {
+			return err
+		}
+		rows++
+		if rows%bookExportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			res.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !res.Committed {
+			res.Header().Del(echo.HeaderContentDisposition)
+			return err
+		}
+		// The client only sees a truncated file, so leave a trace of why.
+		h.logger.ErrorContext(c.Request().Context(), "book export aborted",
+			"format", requestQuery.Format, "rows", rows, "error", err)
+		return err
+	}
+	if rows == 0 {
+		res.WriteHeader(http.StatusOK)
+	}
+	return writer.Close()
+}
+
+type bookCSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newBookCSVWriter(res *echo.Response) *bookCSVWriter {
+	return &bookCSVWriter{w: csv.NewWriter(res)}
+}
+
+func (w *bookCSVWriter) Write(book *models.Book) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.w.Write(bookExportRecord(book))
+}
+
+func (w *bookCSVWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.w.Write(bookExportHeader)
+}
+
+func (w *bookCSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *bookCSVWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+type bookNDJSONWriter struct {
+	encoder *json.Encoder
+}
+
+func newBookNDJSONWriter(res *echo.Response) *bookNDJSONWriter {
+	return &bookNDJSONWriter{encoder: json.NewEncoder(res)}
+}
+
+func (w *bookNDJSONWriter) Write(book *models.Book) error {
+	return w.encoder.Encode(newBookResponse(book))
+}
+
+func (w *bookNDJSONWriter) Flush() error {
+	return nil
+}
+
+func (w *bookNDJSONWriter) Close() error {
+	return nil
+}
+
+// lazyBookXLSXWriter only starts the archive once there is something to write,
+// so the response stays untouched if the export fails before the first row.
+type lazyBookXLSXWriter struct {
+	res *echo.Response
+	w   *xlsx.Writer
+}
+
+func (w *lazyBookXLSXWriter) open() error {
+	if w.w != nil {
+		return nil
+	}
+	xw, err := xlsx.NewWriter(w.res, "books")
+	if err != nil {
+		return err
+	}
+	w.w = xw
+	return w.w.WriteRow(bookExportHeader)
+}
+
+func (w *lazyBookXLSXWriter) Write(book *models.Book) error {
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.w.WriteRow(bookExportRecord(book))
+}
+
+func (w *lazyBookXLSXWriter) Flush() error {
+	if w.w == nil {
+		return nil
+	}
+	return w.w.Flush()
+}
+
+func (w *lazyBookXLSXWriter) Close() error {
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.w.Close()
+}
+
+func bookExportRecord(book *models.Book) []string {
+	return []string{
+		strconv.FormatUint(uint64(book.ID), 10),
+		book.Title,
+		book.Isbn,
+		book.Writer,
+		strconv.FormatUint(uint64(book.UserID), 10),
+		book.CreatedAt.UTC().Format(time.RFC3339),
+		book.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
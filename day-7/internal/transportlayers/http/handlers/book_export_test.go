@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/pkg/logger"
+	"alterra-agmc-day-7/pkg/validator"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportBooks(t *testing.T) {
+	books := []*models.Book{
+		{
+			ID:        1,
+			Title:     "Test Book",
+			Isbn:      "ISBN",
+			Writer:    "Alfian Akmal Hanantio",
+			UserID:    1001,
+			CreatedAt: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	testCases := []struct {
+		name                string
+		query               string
+		streamErr           error
+		expectedFilter      models.BookFilter
+		expectedCode        int
+		expectedContentType string
+		expectBody          func(t *testing.T, body []byte)
+	}{
+		{
+			name:         "Test export books when format is invalid should return bad request",
+			query:        "?format=pdf",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:                "Test export books as csv should stream header and rows with filters",
+			query:               "?format=csv&writer=alfian&user_id=1001",
+			expectedFilter:      models.BookFilter{Writer: "alfian", UserID: 1001},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectBody: func(t *testing.T, body []byte) {
+				assert.Equal(t, "id,title,isbn,writer,user_id,created_at,updated_at\n1,Test Book,ISBN,Alfian Akmal Hanantio,1001,2022-10-01T00:00:00Z,2022-10-02T00:00:00Z\n", string(body))
+			},
+		},
+		{
+			name:                "Test export books as ndjson should stream one json per line",
+			query:               "?format=ndjson",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectBody: func(t *testing.T, body []byte) {
+				lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+				assert.Equal(t, 1, len(lines))
+				assert.Contains(t, lines[0], `"title":"Test Book"`)
+			},
+		},
+		{
+			name:                "Test export books as xlsx should stream a workbook",
+			query:               "?format=xlsx",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			expectBody: func(t *testing.T, body []byte) {
+				archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+				assert.NoError(t, err)
+				for _, f := range archive.File {
+					if f.Name != "xl/worksheets/sheet1.xml" {
+						continue
+					}
+					r, _ := f.Open()
+					sheet, _ := io.ReadAll(r)
+					assert.Contains(t, string(sheet), `<c r="B2" t="inlineStr"><is><t xml:space="preserve">Test Book</t></is></c>`)
+					return
+				}
+				t.Error("sheet1.xml not found")
+			},
+		},
+		{
+			name:         "Test export books when service fails before streaming should return internal server error",
+			query:        "?format=csv",
+			streamErr:    errors.New("something bad"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			bookService := mocks.NewBookService(t)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
+			if testCase.expectedCode != http.StatusBadRequest {
+				bookService.On("Stream", mock.Anything, testCase.expectedFilter, mock.Anything).Return(
+					func(_ context.Context, _ models.BookFilter, fn func(book *models.Book) error) error {
+						if testCase.streamErr != nil {
+							return testCase.streamErr
+						}
+						for _, b := range books {
+							if err := fn(b); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				)
+			}
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodGet, "/"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/books/export")
+
+			// Act
+			handle(c, bookHandler.Export)
+
+			// Assert
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.expectBody != nil {
+				assert.Equal(t, testCase.expectedContentType, rec.Header().Get(echo.HeaderContentType))
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+				testCase.expectBody(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
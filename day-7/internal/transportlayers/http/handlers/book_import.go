@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/transportlayers/http/request"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	bookImportFormatCSV    = "csv"
+	bookImportFormatNDJSON = "ndjson"
+)
+
+// bookImportColumns maps each book field to the column (CSV) or key (NDJSON)
+// it is read from.
+type bookImportColumns struct {
+	title  string
+	isbn   string
+	writer string
+}
+
+type bookImportRow struct {
+	row  int
+	book request.CreateBookRequest
+	err  error
+}
+
+// Import implements BookHandler
+func (h *bookHandlerImpl) Import(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	var requestQuery request.ImportBookRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &requestQuery); err != nil {
+		return invalidQuery(err)
+	}
+	if err := c.Validate(requestQuery); err != nil {
+		return err
+	}
+	format := requestQuery.Format
+	if format == "" {
+		format = bookImportFormatFromContentType(c.Request().Header.Get(echo.HeaderContentType))
+	}
+	if format == "" {
+		return errRequest{status: http.StatusUnsupportedMediaType, key: messageUnsupportedImport}
+	}
+	columns := bookImportColumns{
+		title:  valueOrDefault(requestQuery.TitleColumn, "title"),
+		isbn:   valueOrDefault(requestQuery.IsbnColumn, "isbn"),
+		writer: valueOrDefault(requestQuery.WriterColumn, "writer"),
+	}
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, h.importMaxSize)
+	rows, err := decodeBookImportRows(body, format, columns)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+	case errors.As(err, &tooLarge):
+		return errRequest{status: http.StatusRequestEntityTooLarge, key: messageImportTooLarge, args: []interface{}{tooLarge.Limit}, err: err}
+	case errors.As(err, new(errRequest)):
+		return err
+	default:
+		return errRequest{status: http.StatusBadRequest, key: messageInvalidImport, err: err}
+	}
+
+	report := response.BookImportResponse{
+		DryRun: requestQuery.DryRun,
+		Rows:   make([]response.BookImportRowResponse, len(rows)),
+	}
+	books := make([]*models.Book, 0, len(rows))
+	bookRows := make([]int, 0, len(rows))
+	for i, row := range rows {
+		report.Rows[i].Row = row.row
+		if row.err == nil {
+			if err := c.Validate(row.book); err != nil {
+				row.err = errors.New(errorMessage(err))
+			}
+		}
+		if row.err != nil {
+			report.Rows[i].Status = string(services.BookImportFailed)
+			report.Rows[i].Reason = row.err.Error()
+			continue
+		}
+		books = append(books, &models.Book{
+			Title:  row.book.Title,
+			Isbn:   row.book.Isbn,
+			Writer: row.book.Writer,
+			UserID: uid,
+		})
+		bookRows = append(bookRows, i)
+	}
+
+	results, err := h.service.Import(c.Request().Context(), books, requestQuery.DryRun)
+	if err != nil {
+		return err
+	}
+	for i, result := range results {
+		rowResponse := &report.Rows[bookRows[i]]
+		rowResponse.Status = string(result.Status)
+		rowResponse.Reason = result.Reason
+		if result.Status == services.BookImportCreated {
+			bookResponse := newBookResponse(result.Book)
+			rowResponse.Book = &bookResponse
+		}
+	}
+	for _, row := range report.Rows {
+		switch services.BookImportStatus(row.Status) {
+		case services.BookImportCreated:
+			report.Created++
+		case services.BookImportSkipped:
+			report.Skipped++
+		case services.BookImportFailed:
+			report.Failed++
+		}
+	}
+
+	h.logger.InfoContext(c.Request().Context(), "books imported",
+		"format", format, "dry_run", report.DryRun,
+		"created", report.Created, "skipped", report.Skipped, "failed", report.Failed)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookImportResponse]{
+		Status: http.StatusOK,
+		Data:   report,
+	})
+}
+
+func bookImportFormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return bookImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return bookImportFormatNDJSON
+	}
+	return ""
+}
+
+func decodeBookImportRows(r io.Reader, format string, columns bookImportColumns) ([]bookImportRow, error) {
+	switch format {
+	case bookImportFormatCSV:
+		return decodeBookImportCSV(r, columns)
+	case bookImportFormatNDJSON:
+		return decodeBookImportNDJSON(r, columns)
+	}
+	return nil, errRequest{status: http.StatusUnsupportedMediaType, key: messageUnsupportedImport}
+}
+
+func decodeBookImportCSV(r io.Reader, columns bookImportColumns) ([]bookImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errRequest{status: http.StatusBadRequest, key: messageMissingCSVHeader}
+	}
+	if err != nil {
+		return nil, err
+	}
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		headerIndex[name] = i
+	}
+	indexOf := func(column string) (int, error) {
+		i, ok := headerIndex[column]
+		if !ok {
+			return 0, errRequest{status: http.StatusBadRequest, key: messageMissingCSVColumn, args: []interface{}{column}}
+		}
+		return i, nil
+	}
+	titleIndex, err := indexOf(columns.title)
+	if err != nil {
+		return nil, err
+	}
+	isbnIndex, err := indexOf(columns.isbn)
+	if err != nil {
+		return nil, err
+	}
+	writerIndex, err := indexOf(columns.writer)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]bookImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row := bookImportRow{row: len(rows) + 1}
+		// The reader resumes after a malformed record, only that row fails.
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.err = fmt.Errorf("invalid csv: %v", parseErr)
+			rows = append(rows, row)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(i int, column string) string {
+			if i >= len(record) {
+				row.err = fmt.Errorf("column %q is missing", column)
+				return ""
+			}
+			return record[i]
+		}
+		row.book = request.CreateBookRequest{
+			Title:  field(titleIndex, columns.title),
+			Isbn:   field(isbnIndex, columns.isbn),
+			Writer: field(writerIndex, columns.writer),
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeBookImportNDJSON(r io.Reader, columns bookImportColumns) ([]bookImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	rows := make([]bookImportRow, 0)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := bookImportRow{row: len(rows) + 1}
+		var object map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil {
+			row.err = fmt.Errorf("invalid json: %v", err)
+			rows = append(rows, row)
+			continue
+		}
+		field := func(key string) string {
+			switch value := object[key].(type) {
+			case nil:
+				return ""
+			case string:
+				return value
+			case json.Number:
+				return value.String()
+			default:
+				row.err = fmt.Errorf("field %q should be a string", key)
+				return ""
+			}
+		}
+		row.book = request.CreateBookRequest{
+			Title:  field(columns.title),
+			Isbn:   field(columns.isbn),
+			Writer: field(columns.writer),
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
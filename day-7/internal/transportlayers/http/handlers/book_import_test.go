@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/pkg/logger"
+	"alterra-agmc-day-7/pkg/validator"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testBookImportMaxSize is the largest file the book handlers of the tests
+// import.
+const testBookImportMaxSize = 1 << 20
+
+func TestImportBooks(t *testing.T) {
+	testCases := []struct {
+		name            string
+		maxSize         int64
+		query           string
+		contentType     string
+		body            string
+		importedCount   int
+		resultsReturn   []*services.BookImportResult
+		expectedCode    int
+		expectMessage   *struct{ value string }
+		expectStatuses  []string
+		expectDryRun    bool
+		expectedCreated int
+	}{
+		{
+			name:          "Test import books when content type is unknown should return unsupported media type",
+			contentType:   echo.MIMETextPlain,
+			body:          "title,isbn,writer\n",
+			expectedCode:  http.StatusUnsupportedMediaType,
+			expectMessage: &struct{ value string }{"content type should be text/csv or application/x-ndjson"},
+		},
+		{
+			name:          "Test import books when mapped column is missing should return bad request",
+			query:         "?title_column=judul",
+			contentType:   "text/csv",
+			body:          "title,isbn,writer\nTest Book,ISBN,Writer\n",
+			expectedCode:  http.StatusBadRequest,
+			expectMessage: &struct{ value string }{`column "judul" not found in csv header`},
+		},
+		{
+			name:          "Test import books from csv should validate rows and report service results",
+			query:         "?title_column=judul&writer_column=penulis",
+			contentType:   "text/csv",
+			body:          "judul,isbn,penulis\nTest Book,ISBN-1,Writer\n,ISBN-2,Writer\nOther Book,ISBN-1,Writer\n",
+			importedCount: 2,
+			resultsReturn: []*services.BookImportResult{
+				{Book: &models.Book{ID: 1, Title: "Test Book", Isbn: "ISBN-1", Writer: "Writer"}, Status: services.BookImportCreated},
+				{Book: &models.Book{Title: "Other Book", Isbn: "ISBN-1", Writer: "Writer"}, Status: services.BookImportSkipped, Reason: "duplicate"},
+			},
+			expectedCode:    http.StatusOK,
+			expectStatuses:  []string{"created", "failed", "skipped"},
+			expectedCreated: 1,
+		},
+		{
+			name:          "Test import books when file is too large should return request entity too large",
+			maxSize:       16,
+			contentType:   "text/csv",
+			body:          "title,isbn,writer\nTest Book,ISBN-1,Writer\n",
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectMessage: &struct{ value string }{"import file should not exceed 16 bytes"},
+		},
+		{
+			name:          "Test import books from csv with a malformed record should only fail its row",
+			contentType:   "text/csv",
+			body:          "title,isbn,writer\nTest Book,ISBN-1,Writer\nBad \"Book,ISBN-2,Writer\nOther Book,ISBN-3,Writer\n",
+			importedCount: 2,
+			resultsReturn: []*services.BookImportResult{
+				{Book: &models.Book{ID: 1, Title: "Test Book", Isbn: "ISBN-1", Writer: "Writer"}, Status: services.BookImportCreated},
+				{Book: &models.Book{ID: 2, Title: "Other Book", Isbn: "ISBN-3", Writer: "Writer"}, Status: services.BookImportCreated},
+			},
+			expectedCode:    http.StatusOK,
+			expectStatuses:  []string{"created", "failed", "created"},
+			expectedCreated: 2,
+		},
+		{
+			name:          "Test import books from ndjson with dry run should pass dry run to service",
+			query:         "?dry_run=true",
+			contentType:   "application/x-ndjson",
+			body:          "{\"title\":\"Test Book\",\"isbn\":9786020000000,\"writer\":\"Writer\"}\n\n{not json}\n",
+			importedCount: 1,
+			resultsReturn: []*services.BookImportResult{
+				{Book: &models.Book{Title: "Test Book", Isbn: "9786020000000", Writer: "Writer"}, Status: services.BookImportCreated},
+			},
+			expectedCode:    http.StatusOK,
+			expectStatuses:  []string{"created", "failed"},
+			expectDryRun:    true,
+			expectedCreated: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			bookService := mocks.NewBookService(t)
+			maxSize := int64(testBookImportMaxSize)
+			if testCase.maxSize != 0 {
+				maxSize = testCase.maxSize
+			}
+			bookHandler := NewBookHandler(bookService, maxSize, logger.Discard())
+			if testCase.resultsReturn != nil {
+				bookService.On("Import", mock.Anything, mock.MatchedBy(func(books []*models.Book) bool {
+					return len(books) == testCase.importedCount
+				}), testCase.expectDryRun).Return(testCase.resultsReturn, nil)
+			}
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodPost, "/"+testCase.query, strings.NewReader(testCase.body))
+			req.Header.Set(echo.HeaderContentType, testCase.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/books/import")
+			c.Set("user", &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			})
+
+			// Act
+			handle(c, bookHandler.Import)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.expectMessage != nil {
+				assert.Equal(t, testCase.expectMessage.value, payload["message"])
+			}
+			if testCase.expectStatuses != nil {
+				data := payload["data"].(map[string]interface{})
+				rows := data["rows"].([]interface{})
+				assert.Equal(t, len(testCase.expectStatuses), len(rows))
+				for i, row := range rows {
+					assert.Equal(t, testCase.expectStatuses[i], row.(map[string]interface{})["status"])
+					assert.Equal(t, float64(i+1), row.(map[string]interface{})["row"])
+				}
+				assert.Equal(t, testCase.expectDryRun, data["dry_run"])
+				assert.Equal(t, float64(testCase.expectedCreated), data["created"])
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/transportlayers/http/request"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetRevisions implements BookHandler
+func (h *bookHandlerImpl) GetRevisions(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	revisions, err := h.service.FindRevisions(c.Request().Context(), uint(id))
+	if err != nil {
+		return err
+	}
+	revisionsResponse := []response.BookRevisionResponse{}
+	for _, r := range revisions {
+		revisionsResponse = append(revisionsResponse, newBookRevisionResponse(r))
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[[]response.BookRevisionResponse]{
+		Status: http.StatusOK,
+		Data:   revisionsResponse,
+	})
+}
+
+// GetRevision implements BookHandler
+func (h *bookHandlerImpl) GetRevision(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		return invalidParam("revision", err)
+	}
+	bookRevision, err := h.service.FindRevision(c.Request().Context(), uint(id), uint(revision))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookRevisionResponse]{
+		Status: http.StatusOK,
+		Data:   newBookRevisionResponse(bookRevision),
+	})
+}
+
+// DiffRevisions implements BookHandler
+func (h *bookHandlerImpl) DiffRevisions(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	var requestQuery request.DiffBookRevisionRequest
+	if err := c.Bind(&requestQuery); err != nil {
+		return invalidQuery(err)
+	}
+	if err := c.Validate(requestQuery); err != nil {
+		return err
+	}
+	changes, err := h.service.DiffRevisions(c.Request().Context(), uint(id), requestQuery.From, requestQuery.To)
+	if err != nil {
+		return err
+	}
+	diffResponse := response.BookRevisionDiffResponse{
+		From:    requestQuery.From,
+		To:      requestQuery.To,
+		Changes: []response.BookFieldChangeResponse{},
+	}
+	for _, change := range changes {
+		diffResponse.Changes = append(diffResponse.Changes, response.BookFieldChangeResponse{
+			Field: change.Field,
+			From:  change.From,
+			To:    change.To,
+		})
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookRevisionDiffResponse]{
+		Status: http.StatusOK,
+		Data:   diffResponse,
+	})
+}
+
+// Revert implements BookHandler
+func (h *bookHandlerImpl) Revert(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		return invalidParam("revision", err)
+	}
+	book, err := h.service.Revert(c.Request().Context(), uint(id), uint(revision), uid)
+	if err != nil {
+		return err
+	}
+	setETag(c, book.Version)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.BookResponse]{
+		Status: http.StatusOK,
+		Data:   newBookResponse(book),
+	})
+}
+
+func newBookRevisionResponse(revision *models.BookRevision) response.BookRevisionResponse {
+	return response.BookRevisionResponse{
+		Revision:  revision.Revision,
+		Action:    revision.Action,
+		ActorID:   revision.ActorID,
+		CreatedAt: revision.CreatedAt,
+		Snapshot:  newBookResponse(&revision.Snapshot),
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/pkg/logger"
+	"alterra-agmc-day-7/pkg/validator"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDiffBookRevisions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		changesReturn []models.BookFieldChange
+		expectedCode  int
+		expectChanges int
+	}{
+		{
+			name:         "Test diff revisions without to should return bad request",
+			query:        "?from=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Test diff revisions should return changed fields",
+			query: "?from=1&to=2",
+			changesReturn: []models.BookFieldChange{
+				{Field: "title", From: "Title", To: "New Title"},
+			},
+			expectedCode:  http.StatusOK,
+			expectChanges: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			bookService := mocks.NewBookService(t)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
+			if testCase.changesReturn != nil {
+				bookService.On("DiffRevisions", mock.Anything, uint(1), uint(1), uint(2)).Return(testCase.changesReturn, nil)
+			}
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodGet, "/"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/books/:id/revisions/diff")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			// Act
+			handle(c, bookHandler.DiffRevisions)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.changesReturn != nil {
+				data := payload["data"].(map[string]interface{})
+				changes := data["changes"].([]interface{})
+				assert.Equal(t, testCase.expectChanges, len(changes))
+				assert.Equal(t, "title", changes[0].(map[string]interface{})["field"])
+				assert.Equal(t, float64(2), data["to"])
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"alterra-agmc-day-7/internal/datasources"
 	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/repositories"
+	"alterra-agmc-day-7/internal/services"
 	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/pkg/logger"
 	"alterra-agmc-day-7/pkg/validator"
 	"encoding/json"
 	"errors"
@@ -65,20 +67,19 @@ func TestGetAllBooks(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			// Arrange
 			bookService := mocks.NewBookService(t)
-			bookHandler := NewBookHandler(bookService)
-			bookService.On("FindAll", mock.Anything).Return(testCase.booksReturnFromService, testCase.errReturnFromService)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
+			bookService.On("FindAll", mock.Anything, mock.Anything).Return(testCase.booksReturnFromService, testCase.errReturnFromService)
 			e := echo.New()
 			req := httptest.NewRequest(http.MethodGet, "/books", nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
 			// Act
-			result := bookHandler.GetAll(c)
+			handle(c, bookHandler.GetAll)
 
 			// Assert
 			var payload map[string]interface{}
 			err := json.NewDecoder(rec.Body).Decode(&payload)
-			assert.NoError(t, result)
 			assert.NoError(t, err)
 			assert.Equal(t, testCase.expectedCode, rec.Code)
 			if testCase.errReturnFromService == nil {
@@ -102,14 +103,14 @@ func TestGetBookById(t *testing.T) {
 			name:          "Test get book by id when id is NaN should return bad request with message",
 			bookId:        "NaN",
 			expectedCode:  http.StatusBadRequest,
-			expectMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
-			name:          "Test get book by id when book is not found should return bad requst with message",
+			name:          "Test get book by id when book is not found should return not found with message",
 			bookId:        "123",
-			errReturn:     datasources.ErrRecordNotFound{},
-			expectedCode:  http.StatusInternalServerError,
-			expectMessage: &struct{ value string }{"record not found"},
+			errReturn:     services.ErrNotFound{Resource: "book"},
+			expectedCode:  http.StatusNotFound,
+			expectMessage: &struct{ value string }{"book not found"},
 		},
 		{
 			name:   "Test get book by id when book is found should return ok with book data",
@@ -139,7 +140,7 @@ func TestGetBookById(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			// Arrange
 			bookService := mocks.NewBookService(t)
-			bookHandler := NewBookHandler(bookService)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
 			if testCase.bookReturn != nil || testCase.errReturn != nil {
 				bookService.On("FindByID", mock.Anything, mock.AnythingOfType("uint")).Return(testCase.bookReturn, testCase.errReturn)
 			}
@@ -152,12 +153,11 @@ func TestGetBookById(t *testing.T) {
 			c.SetParamValues(testCase.bookId)
 
 			// Act
-			result := bookHandler.GetByID(c)
+			handle(c, bookHandler.GetByID)
 
 			// Assert
 			var payload map[string]interface{}
 			err := json.NewDecoder(rec.Body).Decode(&payload)
-			assert.NoError(t, result)
 			assert.NoError(t, err)
 			assert.Equal(t, testCase.expectedCode, rec.Code)
 			if testCase.expectMessage != nil {
@@ -199,7 +199,7 @@ func TestCreateBook(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
 			expectedCode:  http.StatusBadRequest,
-			expectMessage: &struct{ value string }{"Key: 'CreateBookRequest.Title' Error:Field validation for 'Title' failed on the 'required' tag\nKey: 'CreateBookRequest.Isbn' Error:Field validation for 'Isbn' failed on the 'required' tag\nKey: 'CreateBookRequest.Writer' Error:Field validation for 'Writer' failed on the 'required' tag"},
+			expectMessage: &struct{ value string }{"validation failed"},
 		},
 		{
 			name: "Test create book when user is authorized and service error should reteurn internal server error",
@@ -214,7 +214,7 @@ func TestCreateBook(t *testing.T) {
 			},
 			errReturn:     errors.New("unknown error"),
 			expectedCode:  http.StatusInternalServerError,
-			expectMessage: &struct{ value string }{"unknown error"},
+			expectMessage: &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name: "Test create book when user is authorized and book is valid should return bad created with data",
@@ -247,7 +247,7 @@ func TestCreateBook(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			// Arrange
 			bookService := mocks.NewBookService(t)
-			bookHandler := NewBookHandler(bookService)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
 			if testCase.bookReturn != nil || testCase.errReturn != nil {
 				bookService.On("Create", mock.Anything, mock.Anything).Return(testCase.bookReturn, testCase.errReturn)
 			}
@@ -264,7 +264,7 @@ func TestCreateBook(t *testing.T) {
 			}
 
 			// Act
-			bookHandler.Create(c)
+			handle(c, bookHandler.Create)
 
 			// Assert
 			var payload map[string]interface{}
@@ -286,15 +286,19 @@ func TestCreateBook(t *testing.T) {
 
 func TestUpdateBook(t *testing.T) {
 	testCases := []struct {
-		name          string
-		bookId        string
-		bookPayload   map[string]interface{}
-		bookReturn    *models.Book
-		errReturn     error
-		token         *jwt.Token
-		expectedCode  int
-		expectMessage *struct{ value string }
-		expectBook    *models.Book
+		name           string
+		bookId         string
+		bookPayload    map[string]interface{}
+		bookReturn     *models.Book
+		errReturn      error
+		token          *jwt.Token
+		ifMatch        string
+		currentVersion uint
+		expectVersion  uint
+		expectedCode   int
+		expectMessage  *struct{ value string }
+		expectBook     *models.Book
+		expectETag     string
 	}{
 		{
 			name:          "Test update book when user is unauthorized should return unauthorized with message",
@@ -310,7 +314,7 @@ func TestUpdateBook(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
 			expectedCode:  http.StatusBadRequest,
-			expectMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
 			name:        "Test update book when user is authorized and book not found",
@@ -319,10 +323,10 @@ func TestUpdateBook(t *testing.T) {
 				Valid:  true,
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
-			errReturn:     datasources.ErrRecordNotFound{},
+			errReturn:     repositories.ErrRecordNotFound{},
 			bookId:        "100",
 			expectedCode:  http.StatusInternalServerError,
-			expectMessage: &struct{ value string }{"record not found"},
+			expectMessage: &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name:        "Test update book when user is authorized and return error from service should return internal server error",
@@ -334,7 +338,70 @@ func TestUpdateBook(t *testing.T) {
 			errReturn:     errors.New("error"),
 			bookId:        "123",
 			expectedCode:  http.StatusInternalServerError,
-			expectMessage: &struct{ value string }{"error"},
+			expectMessage: &struct{ value string }{"Internal Server Error"},
+		},
+		{
+			name:        "Test update book when if match header is malformed should return bad request",
+			bookPayload: map[string]interface{}{},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:       `abc`,
+			bookId:        "1",
+			expectedCode:  http.StatusBadRequest,
+			expectMessage: &struct{ value string }{"invalid If-Match header"},
+		},
+		{
+			name:        "Test update book when if match header holds a tag that is not a version should return precondition failed",
+			bookPayload: map[string]interface{}{},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:       `"abc"`,
+			bookId:        "1",
+			expectedCode:  http.StatusPreconditionFailed,
+			expectMessage: &struct{ value string }{"version does not match"},
+		},
+		{
+			name:        "Test update book when if match header holds a weak tag should return precondition failed",
+			bookPayload: map[string]interface{}{},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:       `W/"2"`,
+			bookId:        "1",
+			expectedCode:  http.StatusPreconditionFailed,
+			expectMessage: &struct{ value string }{"version does not match"},
+		},
+		{
+			name:        "Test update book when no tag of the if match list is the current version should return precondition failed",
+			bookPayload: map[string]interface{}{},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:        `"4", "5"`,
+			currentVersion: 2,
+			bookId:         "1",
+			expectedCode:   http.StatusPreconditionFailed,
+			expectMessage:  &struct{ value string }{"version does not match"},
+		},
+		{
+			name:        "Test update book when version is stale should return precondition failed",
+			bookPayload: map[string]interface{}{},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:       `"1"`,
+			expectVersion: 1,
+			errReturn:     services.ErrPreconditionFailed{},
+			bookId:        "1",
+			expectedCode:  http.StatusPreconditionFailed,
+			expectMessage: &struct{ value string }{"version does not match"},
 		},
 		{
 			name: "Test update book when user is authorized and success edit book should return ok",
@@ -347,14 +414,18 @@ func TestUpdateBook(t *testing.T) {
 				Valid:  true,
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
+			ifMatch:       `"2"`,
+			expectVersion: 2,
 			bookReturn: &models.Book{
-				ID:     1,
-				Title:  "New Title",
-				Isbn:   "New ISBN",
-				Writer: "New Writer",
+				ID:      1,
+				Title:   "New Title",
+				Isbn:    "New ISBN",
+				Writer:  "New Writer",
+				Version: 3,
 			},
 			bookId:       "1",
 			expectedCode: http.StatusOK,
+			expectETag:   `"3"`,
 			expectBook: &models.Book{
 				ID:     1,
 				Title:  "New Title",
@@ -362,21 +433,54 @@ func TestUpdateBook(t *testing.T) {
 				Writer: "New Writer",
 			},
 		},
+		{
+			name: "Test update book when the if match list holds the current version should update that version",
+			bookPayload: map[string]interface{}{
+				"title":  "New Title",
+				"isbn":   "New ISBN",
+				"writer": "New Writer",
+			},
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			ifMatch:        `W/"3", "5",	"2"`,
+			currentVersion: 2,
+			expectVersion:  2,
+			bookReturn: &models.Book{
+				ID:      1,
+				Title:   "New Title",
+				Isbn:    "New ISBN",
+				Writer:  "New Writer",
+				Version: 3,
+			},
+			bookId:       "1",
+			expectedCode: http.StatusOK,
+			expectETag:   `"3"`,
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			// Arrange
 			bookService := mocks.NewBookService(t)
-			bookHandler := NewBookHandler(bookService)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
 			if testCase.bookReturn != nil || testCase.errReturn != nil {
-				bookService.On("Update", mock.Anything, mock.Anything).Return(testCase.bookReturn, testCase.errReturn)
+				bookService.On("Update", mock.Anything, mock.MatchedBy(func(book *models.Book) bool {
+					return book.Version == testCase.expectVersion
+				})).Return(testCase.bookReturn, testCase.errReturn)
+			}
+			if testCase.currentVersion != 0 {
+				bookService.On("FindByID", mock.Anything, uint(1)).Return(&models.Book{ID: 1, Version: testCase.currentVersion}, nil)
 			}
 			e := echo.New()
 			e.Validator = validator.NewCustomValidator()
 			jsonPayload, _ := json.Marshal(&testCase.bookPayload)
 			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(string(jsonPayload)))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetPath("/books/:id")
@@ -387,7 +491,7 @@ func TestUpdateBook(t *testing.T) {
 			}
 
 			// Act
-			bookHandler.Update(c)
+			handle(c, bookHandler.Update)
 
 			// Assert
 			var payload map[string]interface{}
@@ -403,6 +507,7 @@ func TestUpdateBook(t *testing.T) {
 				assert.Equal(t, testCase.expectBook.Title, data["title"])
 				assert.Equal(t, testCase.expectBook.Writer, data["writer"])
 			}
+			assert.Equal(t, testCase.expectETag, rec.Header().Get("ETag"))
 		})
 	}
 }
@@ -430,7 +535,7 @@ func TestDeleteBook(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
 			expectedCode:  http.StatusBadRequest,
-			expectMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
 			name: "Test delete book when user is authorized and service error should return internal server error",
@@ -442,7 +547,7 @@ func TestDeleteBook(t *testing.T) {
 			errReturn:     errors.New("error"),
 			bookId:        "100",
 			expectedCode:  http.StatusInternalServerError,
-			expectMessage: &struct{ value string }{"error"},
+			expectMessage: &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name: "Test delete book when user is authorized and has access to delete book",
@@ -460,9 +565,9 @@ func TestDeleteBook(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			// Arrange
 			bookService := mocks.NewBookService(t)
-			bookHandler := NewBookHandler(bookService)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
 			if testCase.callService {
-				bookService.On("DeleteByID", mock.Anything, mock.Anything, mock.Anything).Return(testCase.errReturn)
+				bookService.On("DeleteByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(testCase.errReturn)
 			}
 			e := echo.New()
 			e.Validator = validator.NewCustomValidator()
@@ -478,7 +583,7 @@ func TestDeleteBook(t *testing.T) {
 			}
 
 			// Act
-			bookHandler.Delete(c)
+			handle(c, bookHandler.Delete)
 
 			// Assert
 			var payload map[string]interface{}
@@ -491,3 +596,108 @@ func TestDeleteBook(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchBook(t *testing.T) {
+	current := &models.Book{
+		ID:         1,
+		Title:      "Title",
+		Isbn:       "ISBN",
+		Writer:     "Writer",
+		CategoryID: 2,
+		Tags:       []string{"go"},
+		Version:    4,
+		UserID:     123,
+	}
+	testCases := []struct {
+		name          string
+		contentType   string
+		body          string
+		expectFields  []string
+		expectBook    *models.Book
+		expectedCode  int
+		expectMessage *struct{ value string }
+	}{
+		{
+			name:          "Test patch book when content type is not a merge patch should return unsupported media type",
+			contentType:   echo.MIMETextPlain,
+			body:          `{"title":"New Title"}`,
+			expectedCode:  http.StatusUnsupportedMediaType,
+			expectMessage: &struct{ value string }{"content type should be application/merge-patch+json"},
+		},
+		{
+			name:          "Test patch book when field is unknown should return bad request",
+			contentType:   "application/merge-patch+json",
+			body:          `{"user_id":1}`,
+			expectedCode:  http.StatusBadRequest,
+			expectMessage: &struct{ value string }{`field "user_id" can not be patched`},
+		},
+		{
+			name:         "Test patch book when required field is set to null should return bad request",
+			contentType:  "application/merge-patch+json",
+			body:         `{"title":null}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Test patch book should only change sent fields and clear null fields",
+			contentType:  "application/merge-patch+json",
+			body:         `{"title":"New Title","tags":null,"category_id":null}`,
+			expectFields: []string{"category_id", "tags", "title"},
+			expectBook: &models.Book{
+				ID:      1,
+				Title:   "New Title",
+				Isbn:    "ISBN",
+				Writer:  "Writer",
+				Version: 4,
+				UserID:  123,
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			bookService := mocks.NewBookService(t)
+			bookHandler := NewBookHandler(bookService, testBookImportMaxSize, logger.Discard())
+			if testCase.expectedCode != http.StatusUnsupportedMediaType && testCase.expectMessage == nil {
+				bookService.On("FindByID", mock.Anything, uint(1)).Return(current, nil)
+			}
+			if testCase.expectBook != nil {
+				patched := *testCase.expectBook
+				patched.Version++
+				bookService.On("Patch", mock.Anything, testCase.expectBook, testCase.expectFields).Return(&patched, nil)
+			}
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(testCase.body))
+			req.Header.Set(echo.HeaderContentType, testCase.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/books/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set("user", &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			})
+
+			// Act
+			handle(c, bookHandler.Patch)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.expectMessage != nil {
+				assert.Equal(t, testCase.expectMessage.value, payload["message"])
+			}
+			if testCase.expectBook != nil {
+				data := payload["data"].(map[string]interface{})
+				assert.Equal(t, testCase.expectBook.Title, data["title"])
+				assert.Equal(t, []interface{}{}, data["tags"])
+				assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
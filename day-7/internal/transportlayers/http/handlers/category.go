@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/transportlayers/http/request"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryHandler interface {
+	GetAll(c echo.Context) error
+	GetByID(c echo.Context) error
+	GetBooks(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type categoryHandlerImpl struct {
+	service services.CategoryService
+}
+
+// Create implements CategoryHandler
+func (h *categoryHandlerImpl) Create(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	var requestBody request.CreateCategoryRequest
+	if err := c.Bind(&requestBody); err != nil {
+		return invalidBody(err)
+	}
+	if err := c.Validate(requestBody); err != nil {
+		return err
+	}
+	category, err := h.service.Create(
+		c.Request().Context(),
+		&models.Category{
+			Name:     requestBody.Name,
+			ParentID: requestBody.ParentID,
+		},
+		uid,
+	)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, response.SuccessResponse[response.CategoryResponse]{
+		Status: http.StatusCreated,
+		Data:   newCategoryResponse(category),
+	})
+}
+
+// Delete implements CategoryHandler
+func (h *categoryHandlerImpl) Delete(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	if err := h.service.DeleteByID(c.Request().Context(), uint(id), uid); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[any]{
+		Status: http.StatusOK,
+		Data:   nil,
+	})
+}
+
+// GetAll implements CategoryHandler
+func (h *categoryHandlerImpl) GetAll(c echo.Context) error {
+	categories, err := h.service.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	categoriesResponse := []response.CategoryResponse{}
+	for _, category := range categories {
+		categoriesResponse = append(categoriesResponse, newCategoryResponse(category))
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[[]response.CategoryResponse]{
+		Status: http.StatusOK,
+		Data:   categoriesResponse,
+	})
+}
+
+// GetBooks implements CategoryHandler
+func (h *categoryHandlerImpl) GetBooks(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	var requestQuery request.ListCategoryBookRequest
+	if err := c.Bind(&requestQuery); err != nil {
+		return invalidQuery(err)
+	}
+	books, err := h.service.FindBooks(
+		c.Request().Context(),
+		uint(id),
+		requestQuery.IncludeDescendants,
+		bookFilterFromRequest(requestQuery.ListBookRequest),
+	)
+	if err != nil {
+		return err
+	}
+	booksResponse := []response.BookResponse{}
+	for _, b := range books {
+		booksResponse = append(booksResponse, newBookResponse(b))
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[[]response.BookResponse]{
+		Status: http.StatusOK,
+		Data:   booksResponse,
+	})
+}
+
+// GetByID implements CategoryHandler
+func (h *categoryHandlerImpl) GetByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	category, err := h.service.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.CategoryResponse]{
+		Status: http.StatusOK,
+		Data:   newCategoryResponse(category),
+	})
+}
+
+// Update implements CategoryHandler
+func (h *categoryHandlerImpl) Update(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	var requestBody request.UpdateCategoryRequest
+	if err := c.Bind(&requestBody); err != nil {
+		return invalidBody(err)
+	}
+	if err := c.Validate(requestBody); err != nil {
+		return err
+	}
+	category, err := h.service.Update(
+		c.Request().Context(),
+		&models.Category{
+			ID:       uint(id),
+			Name:     requestBody.Name,
+			ParentID: requestBody.ParentID,
+		},
+		uid,
+	)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.CategoryResponse]{
+		Status: http.StatusOK,
+		Data:   newCategoryResponse(category),
+	})
+}
+
+func newCategoryResponse(category *models.Category) response.CategoryResponse {
+	return response.CategoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+}
+
+func NewCategoryHandler(categoryService services.CategoryService) CategoryHandler {
+	return &categoryHandlerImpl{
+		service: categoryService,
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/pkg/validator"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCategory(t *testing.T) {
+	testCases := []struct {
+		name          string
+		payload       string
+		categoryRet   *models.Category
+		errReturn     error
+		expectedCode  int
+		expectMessage *struct{ value string }
+	}{
+		{
+			name:          "Test create category when user is not admin should return forbidden",
+			payload:       `{"name":"Fiction"}`,
+			errReturn:     services.ErrForbidden{},
+			expectedCode:  http.StatusForbidden,
+			expectMessage: &struct{ value string }{"forbidden"},
+		},
+		{
+			name:          "Test create category when parent is invalid should return bad request",
+			payload:       `{"name":"Fantasy","parent_id":99}`,
+			errReturn:     services.ErrValidation{Reason: "parent category not found"},
+			expectedCode:  http.StatusBadRequest,
+			expectMessage: &struct{ value string }{"parent category not found"},
+		},
+		{
+			name:         "Test create category when user is admin should return created",
+			payload:      `{"name":"Fantasy","parent_id":1}`,
+			categoryRet:  &models.Category{ID: 2, Name: "Fantasy", ParentID: 1},
+			expectedCode: http.StatusCreated,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			categoryService := mocks.NewCategoryService(t)
+			categoryHandler := NewCategoryHandler(categoryService)
+			categoryService.On("Create", mock.Anything, mock.Anything, uint(123)).Return(testCase.categoryRet, testCase.errReturn)
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testCase.payload))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/categories")
+			c.Set("user", &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			})
+
+			// Act
+			handle(c, categoryHandler.Create)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.expectMessage != nil {
+				assert.Equal(t, testCase.expectMessage.value, payload["message"])
+			}
+			if testCase.categoryRet != nil {
+				data := payload["data"].(map[string]interface{})
+				assert.Equal(t, testCase.categoryRet.Name, data["name"])
+				assert.Equal(t, float64(testCase.categoryRet.ParentID), data["parent_id"])
+			}
+		})
+	}
+}
+
+func TestGetCategoryBooks(t *testing.T) {
+	// Arrange
+	categoryService := mocks.NewCategoryService(t)
+	categoryHandler := NewCategoryHandler(categoryService)
+	categoryService.On("FindBooks", mock.Anything, uint(7), true, models.BookFilter{Tags: []string{"novel", "classic"}}).
+		Return([]*models.Book{{ID: 1, Title: "Bumi Manusia", CategoryID: 8, Tags: []string{"novel", "classic"}}}, nil)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?include_descendants=true&tag=Novel&tag=classic", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/categories/:id/books")
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	// Act
+	handle(c, categoryHandler.GetBooks)
+
+	// Assert
+	var payload map[string]interface{}
+	err := json.NewDecoder(rec.Body).Decode(&payload)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	data := payload["data"].([]interface{})
+	assert.Equal(t, 1, len(data))
+	assert.Equal(t, []interface{}{"novel", "classic"}, data[0].(map[string]interface{})["tags"])
+}
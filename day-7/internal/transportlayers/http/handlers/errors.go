@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"alterra-agmc-day-7/pkg/validator"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewHTTPErrorHandler returns the handler writing every error returned by a
+// handler or a middleware as a response.ErrorResponse, choosing the status
+// from the error type and the language of the message from the
+// Accept-Language header. Errors of no known type answer a generic internal
+// server error, their cause only going to logger.
+func NewHTTPErrorHandler(logger *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+		locale := requestLocale(c)
+		status := errorStatus(err)
+		errorResponse := response.ErrorResponse{
+			Status:  uint(status),
+			Code:    strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+			Message: localizedErrorMessage(locale, err),
+		}
+		var conflict services.ErrConflict
+		if errors.As(err, &conflict) && conflict.Code != "" {
+			errorResponse.Code = conflict.Code
+		}
+		var validationErr validator.ValidationError
+		if errors.As(err, &validationErr) {
+			errorResponse.Message = messages.Translate(locale, messageValidationFailed)
+			errorResponse.Errors = newFieldErrorResponses(validationErr.Localize(locale))
+		}
+		if status == http.StatusInternalServerError && !errors.As(err, new(*echo.HTTPError)) {
+			logger.ErrorContext(c.Request().Context(), "request failed", "error", err)
+		}
+		c.Response().Header().Set(headerContentLanguage, locale)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, errorResponse)
+		}
+		if err != nil {
+			logger.ErrorContext(c.Request().Context(), "failed to write the error response", "error", err)
+		}
+	}
+}
+
+// errorStatus returns the status answering err, which may wrap the error
+// giving it.
+func errorStatus(err error) int {
+	var request errRequest
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, new(services.ErrNotFound)):
+		return http.StatusNotFound
+	case errors.As(err, new(services.ErrUnauthenticated)):
+		return http.StatusUnauthorized
+	case errors.As(err, new(services.ErrForbidden)):
+		return http.StatusForbidden
+	case errors.As(err, new(services.ErrConflict)):
+		return http.StatusConflict
+	case errors.As(err, new(services.ErrValidation)):
+		return http.StatusBadRequest
+	case errors.As(err, new(validator.ValidationError)):
+		return http.StatusBadRequest
+	case errors.As(err, new(services.ErrPreconditionFailed)):
+		return http.StatusPreconditionFailed
+	case errors.As(err, &request):
+		return request.status
+	case errors.As(err, &httpErr):
+		return httpErr.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errRequest is a request a handler can not read: a malformed body, query or
+// path parameter. It answers status with the message of key in the catalog,
+// formatted with args; err, the cause, is kept out of the response.
+type errRequest struct {
+	status int
+	key    string
+	args   []interface{}
+	err    error
+}
+
+func (e errRequest) Error() string {
+	return messages.Translate(messages.Fallback(), e.key, e.args...)
+}
+
+func (e errRequest) Unwrap() error {
+	return e.err
+}
+
+// invalidBody is the error of a request body that failed to bind. An
+// unsupported content type keeps its own status.
+func invalidBody(err error) error {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusUnsupportedMediaType {
+		return httpErr
+	}
+	return errRequest{status: http.StatusBadRequest, key: messageInvalidBody, err: err}
+}
+
+// invalidQuery is the error of query parameters that failed to bind.
+func invalidQuery(err error) error {
+	return errRequest{status: http.StatusBadRequest, key: messageInvalidQuery, err: err}
+}
+
+// invalidParam is the error of the path parameter name that is not a number.
+func invalidParam(name string, err error) error {
+	return errRequest{status: http.StatusBadRequest, key: messageInvalidParam, args: []interface{}{name}, err: err}
+}
+
+func newFieldErrorResponses(fields []validator.FieldError) []response.FieldErrorResponse {
+	responses := make([]response.FieldErrorResponse, 0, len(fields))
+	for _, field := range fields {
+		responses = append(responses, response.FieldErrorResponse{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Param:   field.Param,
+			Message: field.Message,
+		})
+	}
+	return responses
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"alterra-agmc-day-7/internal/transportlayers/http/request"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"alterra-agmc-day-7/pkg/logger"
+	"alterra-agmc-day-7/pkg/validator"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// handle runs handler the way echo does, writing a returned error with
+// the handler of NewHTTPErrorHandler.
+func handle(c echo.Context, handler echo.HandlerFunc) {
+	if err := handler(c); err != nil {
+		NewHTTPErrorHandler(logger.Discard())(err, c)
+	}
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		expectedCode  int
+		expectCode    string
+		expectMessage string
+	}{
+		{
+			name:          "Test not found error should return not found",
+			err:           services.ErrNotFound{Resource: "book"},
+			expectedCode:  http.StatusNotFound,
+			expectCode:    "NOT_FOUND",
+			expectMessage: "book not found",
+		},
+		{
+			name:          "Test unauthenticated error should return unauthorized",
+			err:           services.ErrUnauthenticated{},
+			expectedCode:  http.StatusUnauthorized,
+			expectCode:    "UNAUTHORIZED",
+			expectMessage: "unauthenticated",
+		},
+		{
+			name:          "Test forbidden error should return forbidden",
+			err:           services.ErrForbidden{},
+			expectedCode:  http.StatusForbidden,
+			expectCode:    "FORBIDDEN",
+			expectMessage: "forbidden",
+		},
+		{
+			name:          "Test conflict error should return conflict",
+			err:           services.ErrConflict{Reason: "category still has books"},
+			expectedCode:  http.StatusConflict,
+			expectCode:    "CONFLICT",
+			expectMessage: "category still has books",
+		},
+		{
+			name:          "Test validation error should return bad request",
+			err:           services.ErrValidation{Reason: "category not found"},
+			expectedCode:  http.StatusBadRequest,
+			expectCode:    "BAD_REQUEST",
+			expectMessage: "category not found",
+		},
+		{
+			name:          "Test echo error should keep its status",
+			err:           echo.ErrMethodNotAllowed,
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectCode:    "METHOD_NOT_ALLOWED",
+			expectMessage: "Method Not Allowed",
+		},
+		{
+			name:          "Test wrapped forbidden error should return forbidden",
+			err:           fmt.Errorf("delete book 1: %w", services.ErrForbidden{}),
+			expectedCode:  http.StatusForbidden,
+			expectCode:    "FORBIDDEN",
+			expectMessage: "forbidden",
+		},
+		{
+			name:          "Test unknown error should return internal server error",
+			err:           errors.New("error"),
+			expectedCode:  http.StatusInternalServerError,
+			expectCode:    "INTERNAL_SERVER_ERROR",
+			expectMessage: "Internal Server Error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			NewHTTPErrorHandler(logger.Discard())(testCase.err, c)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			assert.Equal(t, float64(testCase.expectedCode), payload["status"])
+			assert.Equal(t, testCase.expectCode, payload["code"])
+			assert.Equal(t, testCase.expectMessage, payload["message"])
+		})
+	}
+}
+
+func TestHTTPErrorHandlerUnknownError(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "id")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Act
+	NewHTTPErrorHandler(logger.New(&logs, slog.LevelInfo))(errors.New("dial tcp 10.0.0.5:3306: connect: connection refused"), c)
+
+	// Assert
+	var payload response.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "Kesalahan Server Internal", payload.Message)
+	assert.Contains(t, logs.String(), "connection refused")
+}
+
+func TestHTTPErrorHandlerValidationError(t *testing.T) {
+	// Arrange
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	err := validator.NewCustomValidator().Validate(request.CreateBookRequest{
+		Title: "Test Book",
+		Tags:  []string{"go", ""},
+	})
+
+	// Act
+	NewHTTPErrorHandler(logger.Discard())(err, c)
+
+	// Assert
+	var payload response.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "BAD_REQUEST", payload.Code)
+	assert.Equal(t, "validation failed", payload.Message)
+	assert.Equal(t, []response.FieldErrorResponse{
+		{Field: "isbn", Rule: "required", Message: "isbn is a required field"},
+		{Field: "writer", Rule: "required", Message: "writer is a required field"},
+		{Field: "tags[1]", Rule: "required", Message: "tags[1] is a required field"},
+	}, payload.Errors)
+}
+
+func TestHTTPErrorHandlerLocalized(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		err            error
+		expectLanguage string
+		expectMessage  string
+		expectErrors   []response.FieldErrorResponse
+	}{
+		{
+			name:           "Test indonesian request should return indonesian message",
+			acceptLanguage: "id",
+			err:            services.ErrNotFound{Resource: "book"},
+			expectLanguage: "id",
+			expectMessage:  "buku tidak ditemukan",
+		},
+		{
+			name:           "Test region should match its language",
+			acceptLanguage: "fr;q=0.9, id-ID, en;q=0.8",
+			err:            services.ErrConflict{Reason: "category still has books"},
+			expectLanguage: "id",
+			expectMessage:  "kategori masih memiliki buku",
+		},
+		{
+			name:           "Test unsupported language should fall back to english",
+			acceptLanguage: "fr",
+			err:            services.ErrForbidden{},
+			expectLanguage: "en",
+			expectMessage:  "forbidden",
+		},
+		{
+			name:           "Test message missing from indonesian bundle should fall back to english",
+			acceptLanguage: "id",
+			err:            services.ErrValidation{Reason: "tag is too long"},
+			expectLanguage: "id",
+			expectMessage:  "tag is too long",
+		},
+		{
+			name:           "Test indonesian validation error should translate every field",
+			acceptLanguage: "id",
+			err:            validator.NewCustomValidator().Validate(request.LoginUserRequest{Email: "email"}),
+			expectLanguage: "id",
+			expectMessage:  "validasi gagal",
+			expectErrors: []response.FieldErrorResponse{
+				{Field: "email", Rule: "email", Message: "email harus berupa alamat email yang valid"},
+				{Field: "password", Rule: "required", Message: "password wajib diisi"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", testCase.acceptLanguage)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			NewHTTPErrorHandler(logger.Discard())(testCase.err, c)
+
+			// Assert
+			var payload response.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+			assert.Equal(t, testCase.expectLanguage, rec.Header().Get("Content-Language"))
+			assert.Equal(t, testCase.expectMessage, payload.Message)
+			assert.Equal(t, testCase.expectErrors, payload.Errors)
+		})
+	}
+}
+
+func TestHandlerRequestErrorsLocalized(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		contentType    string
+		body           string
+		id             string
+		handler        func(h BookHandler) echo.HandlerFunc
+		acceptLanguage string
+		expectedCode   int
+		expectLanguage string
+		expectMessage  string
+	}{
+		{
+			name:           "Test malformed body should not leak the parser error",
+			method:         http.MethodPost,
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"title": `,
+			handler:        func(h BookHandler) echo.HandlerFunc { return h.Create },
+			expectedCode:   http.StatusBadRequest,
+			expectLanguage: "en",
+			expectMessage:  "request body is malformed",
+		},
+		{
+			name:           "Test malformed body in indonesian should return indonesian message",
+			method:         http.MethodPost,
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"title": 1}`,
+			handler:        func(h BookHandler) echo.HandlerFunc { return h.Create },
+			acceptLanguage: "id",
+			expectedCode:   http.StatusBadRequest,
+			expectLanguage: "id",
+			expectMessage:  "isi permintaan tidak valid",
+		},
+		{
+			name:           "Test id that is not a number in indonesian should return indonesian message",
+			method:         http.MethodGet,
+			id:             "NaN",
+			handler:        func(h BookHandler) echo.HandlerFunc { return h.GetByID },
+			acceptLanguage: "id",
+			expectedCode:   http.StatusBadRequest,
+			expectLanguage: "id",
+			expectMessage:  "id harus berupa angka",
+		},
+		{
+			name:           "Test patch with an unsupported content type should return unsupported media type",
+			method:         http.MethodPatch,
+			contentType:    echo.MIMETextPlain,
+			body:           `{}`,
+			id:             "1",
+			handler:        func(h BookHandler) echo.HandlerFunc { return h.Patch },
+			acceptLanguage: "id",
+			expectedCode:   http.StatusUnsupportedMediaType,
+			expectLanguage: "id",
+			expectMessage:  "tipe konten harus application/merge-patch+json",
+		},
+		{
+			name:           "Test malformed merge patch should not leak the parser error",
+			method:         http.MethodPatch,
+			contentType:    "application/merge-patch+json",
+			body:           `{"title"`,
+			id:             "1",
+			handler:        func(h BookHandler) echo.HandlerFunc { return h.Patch },
+			expectedCode:   http.StatusBadRequest,
+			expectLanguage: "en",
+			expectMessage:  "merge patch is malformed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			bookHandler := NewBookHandler(mocks.NewBookService(t), testBookImportMaxSize, logger.Discard())
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(testCase.method, "/", strings.NewReader(testCase.body))
+			if testCase.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, testCase.contentType)
+			}
+			if testCase.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", testCase.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/books/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(testCase.id)
+			c.Set("user", &jwt.Token{Valid: true, Claims: jwt.MapClaims{"sub": "123"}})
+
+			// Act
+			handle(c, testCase.handler(bookHandler))
+
+			// Assert
+			var payload response.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&payload))
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			assert.Equal(t, testCase.expectLanguage, rec.Header().Get("Content-Language"))
+			assert.Equal(t, testCase.expectMessage, payload.Message)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	healthStatusUp       = "up"
+	healthStatusDown     = "down"
+	healthStatusReady    = "ready"
+	healthStatusNotReady = "not_ready"
+	healthStatusDraining = "draining"
+	healthStatusAlive    = "alive"
+	defaultHealthTimeout = time.Second
+)
+
+type HealthHandler interface {
+	Liveness(c echo.Context) error
+	Readiness(c echo.Context) error
+}
+
+// HealthCheck probes a dependency the app can not serve requests without.
+// Ping is given a context that expires after Timeout.
+type HealthCheck struct {
+	Name    string
+	Timeout time.Duration
+	Ping    func(ctx context.Context) error
+}
+
+type healthHandlerImpl struct {
+	checks   []HealthCheck
+	draining func() bool
+	logger   *slog.Logger
+}
+
+// Liveness implements HealthHandler
+func (h *healthHandlerImpl) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, response.HealthResponse{Status: healthStatusAlive})
+}
+
+// Readiness implements HealthHandler
+func (h *healthHandlerImpl) Readiness(c echo.Context) error {
+	if h.draining() {
+		return c.JSON(http.StatusServiceUnavailable, response.HealthResponse{Status: healthStatusDraining})
+	}
+	results := make([]response.HealthCheckResponse, len(h.checks))
+	var wg sync.WaitGroup
+	for i := range h.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.runHealthCheck(c.Request().Context(), h.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	status, healthResponse := http.StatusOK, response.HealthResponse{
+		Status: healthStatusReady,
+		Checks: make(map[string]response.HealthCheckResponse, len(h.checks)),
+	}
+	for i, check := range h.checks {
+		if results[i].Status != healthStatusUp {
+			status, healthResponse.Status = http.StatusServiceUnavailable, healthStatusNotReady
+		}
+		healthResponse.Checks[check.Name] = results[i]
+	}
+	return c.JSON(status, healthResponse)
+}
+
+// runHealthCheck pings the dependency of check. Why it is down is only logged,
+// the error of a driver may tell more about the infrastructure than callers of
+// a public endpoint should know.
+func (h *healthHandlerImpl) runHealthCheck(ctx context.Context, check HealthCheck) response.HealthCheckResponse {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(pingCtx)
+	result := response.HealthCheckResponse{
+		Status:    healthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = healthStatusDown
+		h.logger.WarnContext(ctx, "health check failed", "check", check.Name, "error", err)
+	}
+	return result
+}
+
+// NewHealthHandler reports the app not ready while draining returns true,
+// i.e. once graceful shutdown has started. Failed checks are logged to logger.
+func NewHealthHandler(draining func() bool, logger *slog.Logger, checks ...HealthCheck) HealthHandler {
+	return &healthHandlerImpl{
+		checks:   checks,
+		draining: draining,
+		logger:   logger,
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"alterra-agmc-day-7/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	testCases := []struct {
+		name         string
+		draining     bool
+		checks       []HealthCheck
+		expectedCode int
+		expectStatus string
+		expectChecks map[string]string
+		expectLogged string
+	}{
+		{
+			name: "Test readiness when every dependency is up should return ok",
+			checks: []HealthCheck{
+				{Name: "mysql", Ping: up},
+				{Name: "mongo", Ping: up},
+			},
+			expectedCode: http.StatusOK,
+			expectStatus: "ready",
+			expectChecks: map[string]string{"mysql": "up", "mongo": "up"},
+		},
+		{
+			name: "Test readiness when a dependency is down should return service unavailable",
+			checks: []HealthCheck{
+				{Name: "mysql", Ping: up},
+				{Name: "mongo", Ping: down},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectStatus: "not_ready",
+			expectChecks: map[string]string{"mysql": "up", "mongo": "down"},
+			expectLogged: "connection refused",
+		},
+		{
+			name: "Test readiness when a dependency does not answer should time out",
+			checks: []HealthCheck{
+				{Name: "mysql", Timeout: 10 * time.Millisecond, Ping: hanging},
+				{Name: "mongo", Ping: up},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectStatus: "not_ready",
+			expectChecks: map[string]string{"mysql": "down", "mongo": "up"},
+			expectLogged: "context deadline exceeded",
+		},
+		{
+			name:     "Test readiness while draining should return service unavailable without probing",
+			draining: true,
+			checks: []HealthCheck{
+				{Name: "mysql", Ping: func(ctx context.Context) error {
+					t.Error("dependency probed while draining")
+					return nil
+				}},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectStatus: "draining",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			var logs bytes.Buffer
+			handler := NewHealthHandler(func() bool { return testCase.draining }, logger.New(&logs, slog.LevelInfo), testCase.checks...)
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			handle(c, handler.Readiness)
+
+			// Assert
+			body := rec.Body.String()
+			var payload response.HealthResponse
+			assert.NoError(t, json.Unmarshal([]byte(body), &payload))
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			assert.Equal(t, testCase.expectStatus, payload.Status)
+			assert.Len(t, payload.Checks, len(testCase.expectChecks))
+			for name, status := range testCase.expectChecks {
+				assert.Equal(t, status, payload.Checks[name].Status)
+			}
+			if testCase.expectLogged != "" {
+				assert.Contains(t, logs.String(), testCase.expectLogged)
+				assert.NotContains(t, body, testCase.expectLogged)
+			}
+		})
+	}
+}
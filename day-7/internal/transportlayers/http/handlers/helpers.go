@@ -1,31 +1,203 @@
 package handlers
 
 import (
-	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"alterra-agmc-day-7/internal/services"
 	"alterra-agmc-day-7/pkg/jwt"
+	"alterra-agmc-day-7/pkg/mergepatch"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	goJWT "github.com/golang-jwt/jwt"
 )
 
+const (
+	headerETag            = "ETag"
+	headerIfMatch         = "If-Match"
+	headerAcceptLanguage  = "Accept-Language"
+	headerContentLanguage = "Content-Language"
+)
+
 func getAuthorizedUserId(c echo.Context) (uint, error) {
 	token, ok := c.Get("user").(*goJWT.Token)
 	if !ok {
-		return 0, c.JSON(http.StatusUnauthorized, response.ErrorResponse{
-			Status:  http.StatusUnauthorized,
-			Code:    "UNAUTHORIZED",
-			Message: "failed get user",
-		})
+		return 0, echo.NewHTTPError(http.StatusUnauthorized, "failed get user")
 	}
 	uid, err := jwt.ExtractID(token)
 	if err != nil {
-		return 0, c.JSON(http.StatusUnauthorized, response.ErrorResponse{
-			Status:  http.StatusUnauthorized,
-			Code:    "UNAUTHORIZED",
-			Message: "invalid token",
-		})
+		return 0, echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 	}
 	return uid, nil
 }
+
+// errorMessage returns the human readable message of err.
+func errorMessage(err error) string {
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		return fmt.Sprint(httpErr.Message)
+	}
+	return err.Error()
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// setETag exposes the version of the returned record as its entity tag.
+func setETag(c echo.Context, version uint) {
+	c.Response().Header().Set(headerETag, strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// errInvalidIfMatch is returned when the If-Match header is not a list of
+// entity tags.
+var errInvalidIfMatch = echo.NewHTTPError(http.StatusBadRequest, "invalid If-Match header")
+
+// ifMatchVersion returns the version of the record the If-Match header makes
+// the change conditional on, or 0 when the header is absent or "*" so the
+// change is applied unconditionally. The header lists entity tags compared
+// strongly (RFC 7232, section 3.1): weak tags and tags that are not one of our
+// versions never match, and a list matching none of them fails with
+// services.ErrPreconditionFailed. When the list names several versions,
+// current returns the version of the record to pick the one to check.
+func ifMatchVersion(c echo.Context, current func() (uint, error)) (uint, error) {
+	values := c.Request().Header.Values(headerIfMatch)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	tags, ok := parseEntityTags(strings.Join(values, ","))
+	if !ok {
+		return 0, errInvalidIfMatch
+	}
+	var versions []uint
+	for _, tag := range tags {
+		if tag == "*" {
+			return 0, nil
+		}
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		version, err := strconv.ParseUint(strings.Trim(tag, `"`), 10, 0)
+		if err == nil && version > 0 && !containsVersion(versions, uint(version)) {
+			versions = append(versions, uint(version))
+		}
+	}
+	switch len(versions) {
+	case 0:
+		return 0, services.ErrPreconditionFailed{}
+	case 1:
+		return versions[0], nil
+	}
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+	if !containsVersion(versions, version) {
+		return 0, services.ErrPreconditionFailed{}
+	}
+	return version, nil
+}
+
+// parseEntityTags splits header, an If-Match or If-None-Match value, into
+// "*" or its entity tags, each with its quotes and W/ prefix if weak. It
+// reports false when header is not a valid list.
+func parseEntityTags(header string) ([]string, bool) {
+	var tags []string
+	for rest := header; ; {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+		if rest[0] == '*' {
+			tags = append(tags, "*")
+			rest = rest[1:]
+		} else {
+			start := 0
+			if strings.HasPrefix(rest, "W/") {
+				start = 2
+			}
+			if len(rest) <= start || rest[start] != '"' {
+				return nil, false
+			}
+			end := strings.IndexByte(rest[start+1:], '"')
+			if end < 0 {
+				return nil, false
+			}
+			end += start + 2
+			tags = append(tags, rest[:end])
+			rest = rest[end:]
+		}
+		if trimmed := strings.TrimLeft(rest, " \t"); trimmed != "" && trimmed[0] != ',' {
+			return nil, false
+		}
+	}
+	return tags, len(tags) > 0
+}
+
+func containsVersion(versions []uint, version uint) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// readMergePatch reads the merge patch sent as request body together with the
+// names of the fields it changes. Every field has to be one of allowed.
+func readMergePatch(c echo.Context, allowed ...string) ([]byte, []string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != mergepatch.ContentType && mediaType != echo.MIMEApplicationJSON {
+		return nil, nil, errRequest{
+			status: http.StatusUnsupportedMediaType,
+			key:    messageUnsupportedPatch,
+			args:   []interface{}{mergepatch.ContentType},
+		}
+	}
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, nil, invalidBody(err)
+	}
+	fields, err := mergepatch.Fields(patch)
+	if err != nil {
+		return nil, nil, errRequest{status: http.StatusBadRequest, key: messageInvalidPatch, err: err}
+	}
+	for _, field := range fields {
+		if !containsField(allowed, field) {
+			return nil, nil, errRequest{status: http.StatusBadRequest, key: messageUnpatchableField, args: []interface{}{field}}
+		}
+	}
+	return patch, fields, nil
+}
+
+// applyMergePatch applies patch to the JSON form of current and decodes the
+// result into patched.
+func applyMergePatch(current interface{}, patch []byte, patched interface{}) error {
+	document, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if document, err = mergepatch.Apply(document, patch); err == nil {
+		err = json.Unmarshal(document, patched)
+	}
+	if err != nil {
+		return errRequest{status: http.StatusBadRequest, key: messageInvalidPatch, err: err}
+	}
+	return nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
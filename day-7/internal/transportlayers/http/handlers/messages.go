@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/pkg/i18n"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// messages holds the translations of every message the API returns. Keys of
+// free-form messages, such as the reason of services.ErrConflict, are their
+// English text so a missing translation still reads well.
+var messages = newMessageCatalog()
+
+const (
+	messageValidationFailed   = "validation_failed"
+	messageNotFound           = "not_found"
+	messageUnauthenticated    = "unauthenticated"
+	messageForbidden          = "forbidden"
+	messagePreconditionFailed = "precondition_failed"
+	messageInvalidBody        = "invalid_body"
+	messageInvalidQuery       = "invalid_query"
+	messageInvalidParam       = "invalid_param"
+	messageInvalidPatch       = "invalid_patch"
+	messageUnpatchableField   = "unpatchable_field"
+	messageUnsupportedPatch   = "unsupported_patch"
+	messageUnsupportedImport  = "unsupported_import"
+	messageInvalidImport      = "invalid_import"
+	messageMissingCSVHeader   = "missing_csv_header"
+	messageMissingCSVColumn   = "missing_csv_column"
+	messagePasswordRemoved    = "password_removed"
+	messageImportTooLarge     = "import_too_large"
+)
+
+func newMessageCatalog() *i18n.Catalog {
+	catalog := i18n.NewCatalog("en")
+	catalog.Add("en", i18n.Bundle{
+		messageValidationFailed:   "validation failed",
+		messageNotFound:           "%s not found",
+		messageUnauthenticated:    "unauthenticated",
+		messageForbidden:          "forbidden",
+		messagePreconditionFailed: "version does not match",
+		messageInvalidBody:        "request body is malformed",
+		messageInvalidQuery:       "query parameters are malformed",
+		messageInvalidParam:       "%s should be a number",
+		messageInvalidPatch:       "merge patch is malformed",
+		messageUnpatchableField:   "field %q can not be patched",
+		messageUnsupportedPatch:   "content type should be %s",
+		messageUnsupportedImport:  "content type should be text/csv or application/x-ndjson",
+		messageInvalidImport:      "import file is malformed",
+		messageMissingCSVHeader:   "csv header is missing",
+		messageMissingCSVColumn:   "column %q not found in csv header",
+		messagePasswordRemoved:    "password can not be removed",
+		messageImportTooLarge:     "import file should not exceed %d bytes",
+	})
+	catalog.Add("id", i18n.Bundle{
+		messageValidationFailed:   "validasi gagal",
+		messageNotFound:           "%s tidak ditemukan",
+		messageUnauthenticated:    "autentikasi diperlukan",
+		messageForbidden:          "akses ditolak",
+		messagePreconditionFailed: "versi tidak sesuai",
+		messageInvalidBody:        "isi permintaan tidak valid",
+		messageInvalidQuery:       "parameter kueri tidak valid",
+		messageInvalidParam:       "%s harus berupa angka",
+		messageInvalidPatch:       "merge patch tidak valid",
+		messageUnpatchableField:   "field %q tidak dapat diubah",
+		messageUnsupportedPatch:   "tipe konten harus %s",
+		messageUnsupportedImport:  "tipe konten harus text/csv atau application/x-ndjson",
+		messageInvalidImport:      "berkas impor tidak valid",
+		messageMissingCSVHeader:   "header csv tidak ada",
+		messageMissingCSVColumn:   "kolom %q tidak ditemukan di header csv",
+		messagePasswordRemoved:    "kata sandi tidak dapat dihapus",
+		messageImportTooLarge:     "berkas impor tidak boleh melebihi %d byte",
+
+		"book":          "buku",
+		"book revision": "revisi buku",
+		"category":      "kategori",
+		"user":          "pengguna",
+
+		"category not found":                                       "kategori tidak ditemukan",
+		"parent category not found":                                "kategori induk tidak ditemukan",
+		"category still has child categories":                      "kategori masih memiliki subkategori",
+		"category still has books":                                 "kategori masih memiliki buku",
+		"category cannot be moved under itself or its descendants": "kategori tidak dapat dipindahkan ke dalam dirinya sendiri atau turunannya",
+
+		"email already registered": "email sudah terdaftar",
+
+		"invalid If-Match header": "header If-Match tidak valid",
+
+		"failed get user":          "gagal mendapatkan pengguna",
+		"invalid token":            "token tidak valid",
+		"missing or malformed jwt": "jwt tidak ada atau tidak valid",
+		"invalid or expired jwt":   "jwt tidak valid atau kedaluwarsa",
+		"Bad Request":              "Permintaan Tidak Valid",
+		"Unauthorized":             "Tidak Terotorisasi",
+		"Not Found":                "Tidak Ditemukan",
+		"Method Not Allowed":       "Metode Tidak Diizinkan",
+		"Request Entity Too Large": "Ukuran Permintaan Terlalu Besar",
+		"Unsupported Media Type":   "Tipe Media Tidak Didukung",
+		"Internal Server Error":    "Kesalahan Server Internal",
+	})
+	return catalog
+}
+
+// requestLocale returns the locale negotiated from the Accept-Language
+// header of the request.
+func requestLocale(c echo.Context) string {
+	return messages.Negotiate(c.Request().Header.Get(headerAcceptLanguage))
+}
+
+// localizedErrorMessage returns the message of err in locale. Errors of no
+// known type get the generic message of an internal server error, as their
+// own may hold internal details.
+func localizedErrorMessage(locale string, err error) string {
+	var notFound services.ErrNotFound
+	var conflict services.ErrConflict
+	var validation services.ErrValidation
+	var request errRequest
+	switch {
+	case errors.As(err, &notFound):
+		return messages.Translate(locale, messageNotFound, messages.Translate(locale, notFound.Resource))
+	case errors.As(err, new(services.ErrUnauthenticated)):
+		return messages.Translate(locale, messageUnauthenticated)
+	case errors.As(err, new(services.ErrForbidden)):
+		return messages.Translate(locale, messageForbidden)
+	case errors.As(err, new(services.ErrPreconditionFailed)):
+		return messages.Translate(locale, messagePreconditionFailed)
+	case errors.As(err, &conflict):
+		return messages.Translate(locale, conflict.Reason)
+	case errors.As(err, &validation):
+		return messages.Translate(locale, validation.Reason)
+	case errors.As(err, &request):
+		return messages.Translate(locale, request.key, request.args...)
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return messages.Translate(locale, errorMessage(httpErr))
+	}
+	return messages.Translate(locale, http.StatusText(http.StatusInternalServerError))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/transportlayers/http/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TrashHandler interface {
+	GetAll(c echo.Context) error
+}
+
+type trashHandlerImpl struct {
+	service services.TrashService
+}
+
+// GetAll implements TrashHandler
+func (h *trashHandlerImpl) GetAll(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	trash, err := h.service.FindAll(c.Request().Context(), uid)
+	if err != nil {
+		return err
+	}
+	trashResponse := response.TrashResponse{
+		Books: []response.BookResponse{},
+		Users: []response.UserResponse{},
+	}
+	for _, b := range trash.Books {
+		trashResponse.Books = append(trashResponse.Books, newBookResponse(b))
+	}
+	for _, u := range trash.Users {
+		trashResponse.Users = append(trashResponse.Users, newUserResponse(u))
+	}
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.TrashResponse]{
+		Status: http.StatusOK,
+		Data:   trashResponse,
+	})
+}
+
+func NewTrashHandler(trashService services.TrashService) TrashHandler {
+	return &trashHandlerImpl{
+		service: trashService,
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"alterra-agmc-day-7/internal/models"
+	"alterra-agmc-day-7/internal/services"
+	"alterra-agmc-day-7/internal/services/mocks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTrash(t *testing.T) {
+	deletedAt := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name         string
+		token        *jwt.Token
+		trashReturn  *services.Trash
+		expectedCode int
+		expectBooks  int
+		expectUsers  int
+	}{
+		{
+			name:         "Test get trash when user is unauthorized should return unauthorized",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "Test get trash should return deleted books and users of the caller",
+			token: &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "123"},
+			},
+			trashReturn: &services.Trash{
+				Books: []*models.Book{{ID: 1, Title: "Test Book", UserID: 123, DeletedAt: &deletedAt}},
+				Users: []*models.User{},
+			},
+			expectedCode: http.StatusOK,
+			expectBooks:  1,
+			expectUsers:  0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			trashService := mocks.NewTrashService(t)
+			trashHandler := NewTrashHandler(trashService)
+			if testCase.trashReturn != nil {
+				trashService.On("FindAll", mock.Anything, uint(123)).Return(testCase.trashReturn, nil)
+			}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/trash")
+			if testCase.token != nil {
+				c.Set("user", testCase.token)
+			}
+
+			// Act
+			handle(c, trashHandler.GetAll)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.trashReturn != nil {
+				data := payload["data"].(map[string]interface{})
+				books := data["books"].([]interface{})
+				assert.Equal(t, testCase.expectBooks, len(books))
+				assert.Equal(t, testCase.expectUsers, len(data["users"].([]interface{})))
+				assert.Equal(t, "2022-10-01T00:00:00Z", books[0].(map[string]interface{})["deleted_at"])
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ type UserHandler interface {
 	GetByID(c echo.Context) error
 	Create(c echo.Context) error
 	Update(c echo.Context) error
+	Patch(c echo.Context) error
 	Delete(c echo.Context) error
+	Restore(c echo.Context) error
 }
 
 type userHandlerImpl struct {
@@ -29,23 +31,10 @@ func (h *userHandlerImpl) Create(c echo.Context) error {
 	var requestBody request.CreateUserRequest
 	err := c.Bind(&requestBody)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidBody(err)
 	}
 	if err := c.Validate(requestBody); err != nil {
-		switch err := err.(type) {
-		case *echo.HTTPError:
-			return c.JSON(err.Code, err.Message)
-		default:
-			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Code:    "BAD_REQUEST",
-				Message: err.Error(),
-			})
-		}
+		return err
 	}
 	userToCreate := &models.User{
 		Name:     requestBody.Name,
@@ -54,19 +43,10 @@ func (h *userHandlerImpl) Create(c echo.Context) error {
 	}
 	createdUser, err := h.userService.Create(c.Request().Context(), userToCreate)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
-	}
-	userResponse := response.UserResponse{
-		Name:      createdUser.Name,
-		Email:     createdUser.Email,
-		ID:        createdUser.ID,
-		CreatedAt: createdUser.CreatedAt,
-		UpdatedAt: createdUser.UpdatedAt,
+		return err
 	}
+	userResponse := newUserResponse(createdUser)
+	setETag(c, createdUser.Version)
 	return c.JSON(http.StatusCreated, response.SuccessResponse[response.UserResponse]{
 		Status: http.StatusCreated,
 		Data:   userResponse,
@@ -82,28 +62,14 @@ func (h *userHandlerImpl) Delete(c echo.Context) error {
 	strId := c.Param("id")
 	id, err := strconv.Atoi(strId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
-	if err := h.userService.DeleteByID(c.Request().Context(), uint(id), uid); err != nil {
-
-		switch err := err.(type) {
-		case services.ErrUnauthorized:
-			return c.JSON(http.StatusUnauthorized, response.ErrorResponse{
-				Status:  http.StatusUnauthorized,
-				Code:    "UNAUTHORIZED",
-				Message: err.Error(),
-			})
-		default:
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: err.Error(),
-			})
-		}
+	version, err := ifMatchVersion(c, h.currentVersion(c, uint(id)))
+	if err != nil {
+		return err
+	}
+	if err := h.userService.DeleteByID(c.Request().Context(), uint(id), uid, version); err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, response.SuccessResponse[any]{
 		Status: http.StatusOK,
@@ -115,21 +81,11 @@ func (h *userHandlerImpl) Delete(c echo.Context) error {
 func (h *userHandlerImpl) GetAll(c echo.Context) error {
 	users, err := h.userService.FindAll(c.Request().Context())
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
+		return err
 	}
 	usersResponse := []response.UserResponse{}
 	for _, u := range users {
-		usersResponse = append(usersResponse, response.UserResponse{
-			Name:      u.Name,
-			Email:     u.Email,
-			ID:        u.ID,
-			CreatedAt: u.CreatedAt,
-			UpdatedAt: u.UpdatedAt,
-		})
+		usersResponse = append(usersResponse, newUserResponse(u))
 	}
 	return c.JSON(http.StatusOK, response.SuccessResponse[[]response.UserResponse]{
 		Status: http.StatusOK,
@@ -142,27 +98,14 @@ func (h *userHandlerImpl) GetByID(c echo.Context) error {
 	strId := c.Param("id")
 	id, err := strconv.Atoi(strId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
 	user, err := h.userService.FindByID(c.Request().Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-			Status:  http.StatusInternalServerError,
-			Code:    "INTERNAL_SERVER_ERROR",
-			Message: err.Error(),
-		})
-	}
-	userResponse := response.UserResponse{
-		Name:      user.Name,
-		Email:     user.Email,
-		ID:        user.ID,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		return err
 	}
+	userResponse := newUserResponse(user)
+	setETag(c, user.Version)
 	return c.JSON(http.StatusOK, response.SuccessResponse[response.UserResponse]{
 		Status: http.StatusOK,
 		Data:   userResponse,
@@ -178,106 +121,132 @@ func (h *userHandlerImpl) Update(c echo.Context) error {
 	strId := c.Param("id")
 	id, err := strconv.Atoi(strId)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidParam("id", err)
 	}
 	var requestBody request.UpdateUserRequest
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidBody(err)
 	}
 	if err := c.Validate(requestBody); err != nil {
-		switch err := err.(type) {
-		case *echo.HTTPError:
-			return c.JSON(err.Code, err.Message)
-		default:
-			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Code:    "BAD_REQUEST",
-				Message: err.Error(),
-			})
-		}
+		return err
+	}
+	version, err := ifMatchVersion(c, h.currentVersion(c, uint(id)))
+	if err != nil {
+		return err
 	}
 	userToUpdate := &models.User{
 		ID:       uint(id),
 		Name:     requestBody.Name,
 		Email:    requestBody.Email,
 		Password: requestBody.Password,
+		Version:  version,
 	}
 	updatedUser, err := h.userService.Update(c.Request().Context(), userToUpdate, uid)
 	if err != nil {
-		switch err := err.(type) {
-		case services.ErrUnauthorized:
-			return c.JSON(http.StatusUnauthorized, response.ErrorResponse{
-				Status:  http.StatusUnauthorized,
-				Code:    "UNAUTHORIZED",
-				Message: err.Error(),
-			})
-		default:
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: err.Error(),
-			})
-		}
-	}
-	userResponse := response.UserResponse{
-		Name:      updatedUser.Name,
-		Email:     updatedUser.Email,
-		ID:        updatedUser.ID,
-		CreatedAt: updatedUser.CreatedAt,
-		UpdatedAt: updatedUser.UpdatedAt,
+		return err
 	}
+	userResponse := newUserResponse(updatedUser)
+	setETag(c, updatedUser.Version)
 	return c.JSON(http.StatusOK, response.SuccessResponse[response.UserResponse]{
 		Status: http.StatusOK,
 		Data:   userResponse,
 	})
 }
 
+// Patch implements UserHandler
+func (h *userHandlerImpl) Patch(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	patch, fields, err := readMergePatch(c, models.UserFieldName, models.UserFieldEmail, models.UserFieldPassword)
+	if err != nil {
+		return err
+	}
+	current, err := h.userService.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		return err
+	}
+	version, err := ifMatchVersion(c, func() (uint, error) { return current.Version, nil })
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		version = current.Version
+	}
+	var requestBody request.PatchUserRequest
+	err = applyMergePatch(request.PatchUserRequest{
+		Name:  current.Name,
+		Email: current.Email,
+	}, patch, &requestBody)
+	if err == nil && containsField(fields, models.UserFieldPassword) && requestBody.Password == "" {
+		err = errRequest{status: http.StatusBadRequest, key: messagePasswordRemoved}
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.Validate(requestBody); err != nil {
+		return err
+	}
+	patchedUser, err := h.userService.Patch(
+		c.Request().Context(),
+		&models.User{
+			ID:       uint(id),
+			Name:     requestBody.Name,
+			Email:    requestBody.Email,
+			Password: requestBody.Password,
+			Version:  version,
+		},
+		fields,
+		uid,
+	)
+	if err != nil {
+		return err
+	}
+	setETag(c, patchedUser.Version)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.UserResponse]{
+		Status: http.StatusOK,
+		Data:   newUserResponse(patchedUser),
+	})
+}
+
+// Restore implements UserHandler
+func (h *userHandlerImpl) Restore(c echo.Context) error {
+	uid, err := getAuthorizedUserId(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return invalidParam("id", err)
+	}
+	restoredUser, err := h.userService.Restore(c.Request().Context(), uint(id), uid)
+	if err != nil {
+		return err
+	}
+	setETag(c, restoredUser.Version)
+	return c.JSON(http.StatusOK, response.SuccessResponse[response.UserResponse]{
+		Status: http.StatusOK,
+		Data:   newUserResponse(restoredUser),
+	})
+}
+
 // Login implements UserHandler
 func (h *userHandlerImpl) Login(c echo.Context) error {
 	var requestBody request.LoginUserRequest
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			Status:  http.StatusBadRequest,
-			Code:    "BAD_REQUEST",
-			Message: err.Error(),
-		})
+		return invalidBody(err)
 	}
 	if err := c.Validate(requestBody); err != nil {
-		switch err := err.(type) {
-		case *echo.HTTPError:
-			return c.JSON(err.Code, err.Message)
-		default:
-			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Status:  http.StatusBadRequest,
-				Code:    "BAD_REQUEST",
-				Message: err.Error(),
-			})
-		}
+		return err
 	}
 	token, err := h.userService.Login(c.Request().Context(), requestBody.Email, requestBody.Password)
 	if err != nil {
-		switch err := err.(type) {
-		case services.ErrUnauthorized:
-			return c.JSON(http.StatusUnauthorized, response.ErrorResponse{
-				Status:  http.StatusUnauthorized,
-				Code:    "UNAUTHORIZED",
-				Message: err.Error(),
-			})
-		default:
-			return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Status:  http.StatusInternalServerError,
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: err.Error(),
-			})
-		}
+		return err
 	}
 	return c.JSON(http.StatusOK, response.SuccessResponse[response.LoginResponse]{
 		Status: http.StatusOK,
@@ -287,8 +256,32 @@ func (h *userHandlerImpl) Login(c echo.Context) error {
 	})
 }
 
+func newUserResponse(user *models.User) response.UserResponse {
+	return response.UserResponse{
+		Name:      user.Name,
+		Email:     user.Email,
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		DeletedAt: user.DeletedAt,
+		Version:   user.Version,
+	}
+}
+
 func NewUserHandler(userService services.UserService) UserHandler {
 	return &userHandlerImpl{
 		userService: userService,
 	}
 }
+
+// currentVersion returns a function reading the version of the user of id,
+// for ifMatchVersion to resolve an If-Match header listing several.
+func (h *userHandlerImpl) currentVersion(c echo.Context, id uint) func() (uint, error) {
+	return func() (uint, error) {
+		user, err := h.userService.FindByID(c.Request().Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return user.Version, nil
+	}
+}
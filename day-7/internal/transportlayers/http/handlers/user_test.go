@@ -31,7 +31,7 @@ func TestLogin(t *testing.T) {
 			name:            "Test Login when request is invalid should return bad request with message",
 			payload:         map[string]interface{}{},
 			expectedCode:    http.StatusBadRequest,
-			expectedMessage: &struct{ value string }{"Key: 'LoginUserRequest.Email' Error:Field validation for 'Email' failed on the 'required' tag\nKey: 'LoginUserRequest.Password' Error:Field validation for 'Password' failed on the 'required' tag"},
+			expectedMessage: &struct{ value string }{"validation failed"},
 		},
 		{
 			name: "Test login when service return unauthorized should return unauthorized",
@@ -40,8 +40,8 @@ func TestLogin(t *testing.T) {
 				"password": "Password",
 			},
 			expectedCode:     http.StatusUnauthorized,
-			errReturnService: services.ErrUnauthorized{},
-			expectedMessage:  &struct{ value string }{"unauthorized"},
+			errReturnService: services.ErrUnauthenticated{},
+			expectedMessage:  &struct{ value string }{"unauthenticated"},
 		},
 		{
 			name: "Test login when service err should return internal server error",
@@ -51,7 +51,7 @@ func TestLogin(t *testing.T) {
 			},
 			errReturnService: errors.New("error"),
 			expectedCode:     http.StatusInternalServerError,
-			expectedMessage:  &struct{ value string }{"error"},
+			expectedMessage:  &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name: "Test login when service return token should return ok",
@@ -83,7 +83,7 @@ func TestLogin(t *testing.T) {
 			c.SetPath("/login")
 
 			// Act
-			handler.Login(c)
+			handle(c, handler.Login)
 
 			// Assert
 			var payload map[string]interface{}
@@ -118,7 +118,7 @@ func TestGetUsers(t *testing.T) {
 			name:             "Test get all users when service return error should return internal server error",
 			expectedCode:     http.StatusInternalServerError,
 			errServiceReturn: errors.New("error"),
-			expectedMessage:  &struct{ value string }{"error"},
+			expectedMessage:  &struct{ value string }{"Internal Server Error"},
 		},
 	}
 
@@ -136,7 +136,7 @@ func TestGetUsers(t *testing.T) {
 		c.SetPath("/users")
 
 		// Act
-		handler.GetAll(c)
+		handle(c, handler.GetAll)
 
 		// Assert
 		var payload map[string]interface{}
@@ -163,7 +163,7 @@ func TestGetuserById(t *testing.T) {
 			name:            "Test get user by id when id is NaN should return bad request with message",
 			userId:          "NaN",
 			expectedCode:    http.StatusBadRequest,
-			expectedMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectedMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
 			name:             "Test get user by id service err should return internal server error",
@@ -171,7 +171,14 @@ func TestGetuserById(t *testing.T) {
 			errServiceReturn: errors.New("error"),
 			serviceReturn:    &models.User{},
 			expectedCode:     http.StatusInternalServerError,
-			expectedMessage:  &struct{ value string }{"error"},
+			expectedMessage:  &struct{ value string }{"Internal Server Error"},
+		},
+		{
+			name:             "Test get user by id when user is not found should return not found",
+			userId:           "1003",
+			errServiceReturn: services.ErrNotFound{Resource: "user"},
+			expectedCode:     http.StatusNotFound,
+			expectedMessage:  &struct{ value string }{"user not found"},
 		},
 		{
 			name:   "Test get user by id when user is found should return ok with user data",
@@ -206,7 +213,7 @@ func TestGetuserById(t *testing.T) {
 		c.SetParamValues(testCase.userId)
 
 		// Act
-		handler.GetByID(c)
+		handle(c, handler.GetByID)
 
 		// Assert
 		var payload map[string]interface{}
@@ -232,13 +239,14 @@ func TestCreateUser(t *testing.T) {
 		errServiceReturn error
 		expectedCode     int
 		expectedMessage  *struct{ value string }
+		expectedErrCode  string
 		expectedData     *models.User
 	}{
 		{
 			name:            "Test create user when user is invalid should return bad request with message",
 			userPayload:     map[string]interface{}{},
 			expectedCode:    http.StatusBadRequest,
-			expectedMessage: &struct{ value string }{"Key: 'CreateUserRequest.Name' Error:Field validation for 'Name' failed on the 'required' tag\nKey: 'CreateUserRequest.Email' Error:Field validation for 'Email' failed on the 'required' tag\nKey: 'CreateUserRequest.Password' Error:Field validation for 'Password' failed on the 'required' tag"},
+			expectedMessage: &struct{ value string }{"validation failed"},
 		}, {
 			name: "Test create user when service error should return internal serverError",
 			userPayload: map[string]interface{}{
@@ -249,7 +257,19 @@ func TestCreateUser(t *testing.T) {
 			serviceReturn:    &models.User{},
 			expectedCode:     http.StatusInternalServerError,
 			errServiceReturn: errors.New("error"),
-			expectedMessage:  &struct{ value string }{"error"},
+			expectedMessage:  &struct{ value string }{"Internal Server Error"},
+		},
+		{
+			name: "Test create user when email is taken should return conflict",
+			userPayload: map[string]interface{}{
+				"email":    "User_1@email.com",
+				"name":     "user_1",
+				"password": "secret_password",
+			},
+			expectedCode:     http.StatusConflict,
+			errServiceReturn: services.ErrEmailTaken,
+			expectedErrCode:  "EMAIL_TAKEN",
+			expectedMessage:  &struct{ value string }{"email already registered"},
 		},
 		{
 			name: "Test create user when user is valid should return bad created with data",
@@ -288,7 +308,7 @@ func TestCreateUser(t *testing.T) {
 			c.SetPath("/users")
 
 			// Act
-			handler.Create(c)
+			handle(c, handler.Create)
 
 			// Assert
 			var payload map[string]interface{}
@@ -298,6 +318,9 @@ func TestCreateUser(t *testing.T) {
 			if testCase.expectedMessage != nil {
 				assert.Equal(t, testCase.expectedMessage.value, payload["message"])
 			}
+			if testCase.expectedErrCode != "" {
+				assert.Equal(t, testCase.expectedErrCode, payload["code"])
+			}
 			if testCase.expectedData != nil {
 				data := payload["data"].(map[string]interface{})
 				assert.Equal(t, testCase.expectedData.Email, data["email"])
@@ -334,19 +357,19 @@ func TestUpdateUser(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "1"},
 			},
 			expectedCode:    http.StatusBadRequest,
-			expectedMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectedMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
-			name: "Test update user when user is authorized service return ErrUnauthorized should return unauthorized",
+			name: "Test update user when user is authorized service return ErrForbidden should return forbidden",
 			token: &jwt.Token{
 				Valid:  true,
 				Claims: jwt.MapClaims{"sub": "100"},
 			},
 			userId:           "1",
 			serviceReturn:    &models.User{},
-			errServiceReturn: services.ErrUnauthorized{},
-			expectedCode:     http.StatusUnauthorized,
-			expectedMessage:  &struct{ value string }{"unauthorized"},
+			errServiceReturn: services.ErrForbidden{},
+			expectedCode:     http.StatusForbidden,
+			expectedMessage:  &struct{ value string }{"forbidden"},
 		},
 		{
 			name: "Test update user when user is authorized service error should return internal server error",
@@ -358,7 +381,7 @@ func TestUpdateUser(t *testing.T) {
 			serviceReturn:    &models.User{},
 			errServiceReturn: errors.New("error"),
 			expectedCode:     http.StatusInternalServerError,
-			expectedMessage:  &struct{ value string }{"error"},
+			expectedMessage:  &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name: "Test update book when user is authorized and has access to edit book",
@@ -406,7 +429,7 @@ func TestUpdateUser(t *testing.T) {
 			}
 
 			// Act
-			handler.Update(c)
+			handle(c, handler.Update)
 
 			// Assert
 			var payload map[string]interface{}
@@ -425,6 +448,134 @@ func TestUpdateUser(t *testing.T) {
 	}
 }
 
+func TestPatchUser(t *testing.T) {
+	current := &models.User{
+		ID:      1,
+		Name:    "User",
+		Email:   "user@email.com",
+		Version: 4,
+	}
+	testCases := []struct {
+		name             string
+		body             string
+		ifMatch          string
+		callFindByID     bool
+		expectedUser     *models.User
+		expectedFields   []string
+		errServiceReturn error
+		expectedCode     int
+		expectedMessage  *struct{ value string }
+	}{
+		{
+			name:            "Test patch user when field is not allowed should return bad request",
+			body:            `{"is_admin":true}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: &struct{ value string }{`field "is_admin" can not be patched`},
+		},
+		{
+			name:         "Test patch user when name is set to null should return bad request",
+			body:         `{"name":null}`,
+			callFindByID: true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "Test patch user when password is set to null should return bad request",
+			body:            `{"password":null}`,
+			callFindByID:    true,
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: &struct{ value string }{"password can not be removed"},
+		},
+		{
+			name:            "Test patch user when no if match tag is the current version should return precondition failed",
+			body:            `{"name":"New User"}`,
+			callFindByID:    true,
+			ifMatch:         `"8", "9"`,
+			expectedCode:    http.StatusPreconditionFailed,
+			expectedMessage: &struct{ value string }{"version does not match"},
+		},
+		{
+			name:         "Test patch user when if match version is stale should return precondition failed",
+			body:         `{"name":"New User"}`,
+			callFindByID: true,
+			ifMatch:      `"3"`,
+			expectedUser: &models.User{
+				ID:      1,
+				Name:    "New User",
+				Email:   "user@email.com",
+				Version: 3,
+			},
+			expectedFields:   []string{"name"},
+			errServiceReturn: services.ErrPreconditionFailed{},
+			expectedCode:     http.StatusPreconditionFailed,
+			expectedMessage:  &struct{ value string }{"version does not match"},
+		},
+		{
+			name:         "Test patch user should only change sent fields",
+			body:         `{"name":"New User"}`,
+			callFindByID: true,
+			ifMatch:      `"4"`,
+			expectedUser: &models.User{
+				ID:      1,
+				Name:    "New User",
+				Email:   "user@email.com",
+				Version: 4,
+			},
+			expectedFields: []string{"name"},
+			expectedCode:   http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			mockService := mocks.NewUserService(t)
+			handler := NewUserHandler(mockService)
+			if testCase.callFindByID {
+				mockService.On("FindByID", mock.Anything, uint(1)).Return(current, nil)
+			}
+			if testCase.expectedUser != nil {
+				patched := *testCase.expectedUser
+				patched.Version++
+				mockService.On("Patch", mock.Anything, testCase.expectedUser, testCase.expectedFields, uint(1)).Return(&patched, testCase.errServiceReturn)
+			}
+			e := echo.New()
+			e.Validator = validator.NewCustomValidator()
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(testCase.body))
+			req.Header.Set(echo.HeaderContentType, "application/merge-patch+json")
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/users/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set("user", &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "1"},
+			})
+
+			// Act
+			handle(c, handler.Patch)
+
+			// Assert
+			var payload map[string]interface{}
+			err := json.NewDecoder(rec.Body).Decode(&payload)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			if testCase.expectedMessage != nil {
+				assert.Equal(t, testCase.expectedMessage.value, payload["message"])
+			}
+			if testCase.expectedCode == http.StatusOK {
+				data := payload["data"].(map[string]interface{})
+				assert.Equal(t, testCase.expectedUser.Name, data["name"])
+				assert.Equal(t, testCase.expectedUser.Email, data["email"])
+				assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -448,7 +599,7 @@ func TestDeleteUser(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "123"},
 			},
 			expectedCode:    http.StatusBadRequest,
-			expectedMessage: &struct{ value string }{`strconv.Atoi: parsing "NaN": invalid syntax`},
+			expectedMessage: &struct{ value string }{"id should be a number"},
 		},
 		{
 			name: "Test delete user when user is authorized and doesn't has access to delete user",
@@ -457,10 +608,10 @@ func TestDeleteUser(t *testing.T) {
 				Claims: jwt.MapClaims{"sub": "1"},
 			},
 			userId:          "123",
-			errReturn:       services.ErrUnauthorized{},
+			errReturn:       services.ErrForbidden{},
 			callService:     true,
-			expectedCode:    http.StatusUnauthorized,
-			expectedMessage: &struct{ value string }{"unauthorized"},
+			expectedCode:    http.StatusForbidden,
+			expectedMessage: &struct{ value string }{"forbidden"},
 		},
 		{
 			name: "Test delete user when service return error should return internal server error",
@@ -472,7 +623,7 @@ func TestDeleteUser(t *testing.T) {
 			errReturn:       errors.New("error"),
 			callService:     true,
 			expectedCode:    http.StatusInternalServerError,
-			expectedMessage: &struct{ value string }{"error"},
+			expectedMessage: &struct{ value string }{"Internal Server Error"},
 		},
 		{
 			name: "Test update book when user is authorized and has access to edit book",
@@ -493,7 +644,7 @@ func TestDeleteUser(t *testing.T) {
 			mockService := mocks.NewUserService(t)
 			handler := NewUserHandler(mockService)
 			if testCase.callService {
-				mockService.On("DeleteByID", mock.Anything, mock.Anything, mock.Anything).Return(testCase.errReturn)
+				mockService.On("DeleteByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(testCase.errReturn)
 			}
 			e := echo.New()
 			e.Validator = validator.NewCustomValidator()
@@ -509,7 +660,7 @@ func TestDeleteUser(t *testing.T) {
 			}
 
 			// Act
-			handler.Delete(c)
+			handle(c, handler.Delete)
 
 			// Assert
 			var payload map[string]interface{}
@@ -522,3 +673,52 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreUser(t *testing.T) {
+	testCases := []struct {
+		name          string
+		serviceReturn *models.User
+		errReturn     error
+		expectedCode  int
+		expectedETag  string
+	}{
+		{
+			name:         "Test restore user when service return ErrForbidden should return forbidden",
+			errReturn:    services.ErrForbidden{},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:          "Test restore user should return the user with its version as etag",
+			serviceReturn: &models.User{ID: 1, Name: "User", Email: "user@email.com", Version: 3},
+			expectedCode:  http.StatusOK,
+			expectedETag:  `"3"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			mockService := mocks.NewUserService(t)
+			handler := NewUserHandler(mockService)
+			mockService.On("Restore", mock.Anything, uint(1), uint(1)).Return(testCase.serviceReturn, testCase.errReturn)
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/users/:id/restore")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set("user", &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"sub": "1"},
+			})
+
+			// Act
+			handle(c, handler.Restore)
+
+			// Assert
+			assert.Equal(t, testCase.expectedCode, rec.Code)
+			assert.Equal(t, testCase.expectedETag, rec.Header().Get("ETag"))
+		})
+	}
+}
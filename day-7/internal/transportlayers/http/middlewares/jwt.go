@@ -1,16 +1,14 @@
 package middlewares
 
 import (
-	"alterra-agmc-day-7/config"
-
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func JWT() echo.MiddlewareFunc {
+func JWT(secretKey string) echo.MiddlewareFunc {
 	return middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey:    []byte(config.GetJWTSecretKey()),
+		SigningKey:    []byte(secretKey),
 		SigningMethod: jwt.SigningMethodHS256.Name,
 	})
 }
@@ -1,13 +1,48 @@
 package middlewares
 
 import (
+	"log/slog"
+	"net/http"
+	"time"
+
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 )
 
-func UseLogMiddleware(e *echo.Echo) {
-	loggerConfig := middleware.LoggerConfig{
-		Format: `[${time_rfc3339}] ${status} ${method} ${host}${uri} ${latency_human}` + "\n",
+// UseLogMiddleware assigns an ID to every request and logs one access line
+// per request to logger.
+func UseLogMiddleware(e *echo.Echo, logger *slog.Logger) {
+	e.Use(RequestID(), AccessLog(logger))
+}
+
+// AccessLog logs every request once it has been handled, at error level for
+// server errors and at info level otherwise.
+func AccessLog(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			req, res := c.Request(), c.Response()
+			level := slog.LevelInfo
+			if res.Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			attrs := []interface{}{
+				"method", req.Method,
+				"route", routeTemplate(c),
+				"path", req.URL.Path,
+				"status", res.Status,
+				"latency_ms", time.Since(start).Milliseconds(),
+				"bytes_out", res.Size,
+				"remote_ip", c.RealIP(),
+			}
+			if err != nil {
+				attrs = append(attrs, "error", err)
+			}
+			logger.Log(req.Context(), level, "request handled", attrs...)
+			return nil
+		}
 	}
-	e.Use(middleware.LoggerWithConfig(loggerConfig))
 }
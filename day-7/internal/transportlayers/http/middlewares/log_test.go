@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"alterra-agmc-day-7/pkg/logger"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseLogMiddleware(t *testing.T) {
+	testCases := []struct {
+		name              string
+		requestID         string
+		expectedRequestID string
+	}{
+		{
+			name:              "Test request ID sent by the client should be kept",
+			requestID:         "client-id",
+			expectedRequestID: "client-id",
+		},
+		{
+			name:      "Test missing request ID should be generated",
+			requestID: "",
+		},
+		{
+			name:      "Test request ID with control characters should be replaced",
+			requestID: "forged\nline",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			log := logger.New(&buf, slog.LevelInfo)
+			e := echo.New()
+			UseLogMiddleware(e, log)
+			e.GET("/books/:id", func(c echo.Context) error {
+				log.InfoContext(c.Request().Context(), "handler called")
+				return c.NoContent(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+			req.Header.Set(echo.HeaderXRequestID, testCase.requestID)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			requestID := rec.Header().Get(echo.HeaderXRequestID)
+			if testCase.expectedRequestID != "" {
+				assert.Equal(t, testCase.expectedRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			assert.Len(t, lines, 2)
+			for _, line := range lines {
+				var record map[string]interface{}
+				assert.NoError(t, json.Unmarshal(line, &record))
+				assert.Equal(t, requestID, record[logger.KeyRequestID])
+			}
+			var access map[string]interface{}
+			assert.NoError(t, json.Unmarshal(lines[1], &access))
+			assert.Equal(t, "/books/:id", access["route"])
+			assert.Equal(t, float64(http.StatusOK), access["status"])
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"alterra-agmc-day-7/internal/metrics"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute names requests that matched no route, keeping arbitrary
+// paths out of metric labels and span names.
+const unmatchedRoute = "unmatched"
+
+// routeTemplate returns the template of the route c matched, such as
+// /v1/books/:id, once the response has been written.
+func routeTemplate(c echo.Context) string {
+	route := c.Path()
+	// Echo reports the requested path as the route when none matched.
+	if route == "" || (c.Response().Status == http.StatusNotFound && route == c.Request().URL.Path) {
+		return unmatchedRoute
+	}
+	return route
+}
+
+// Metrics records the duration of every request by route template, method
+// and status.
+func Metrics(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				// Write the error now so its status is known.
+				c.Error(err)
+			}
+			m.ObserveHTTPRequest(routeTemplate(c), c.Request().Method, c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"alterra-agmc-day-7/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		expectLabels map[string]string
+	}{
+		{
+			name:         "Test request to a route should be labelled with its template",
+			path:         "/books/12",
+			expectLabels: map[string]string{"route": "/books/:id", "method": "GET", "status": "200"},
+		},
+		{
+			name:         "Test request failing with an error should be labelled with the error status",
+			path:         "/books/unknown",
+			expectLabels: map[string]string{"route": "/books/:id", "method": "GET", "status": "404"},
+		},
+		{
+			name:         "Test request to an unknown route should not be labelled with its path",
+			path:         "/unknown/12",
+			expectLabels: map[string]string{"route": "unmatched", "method": "GET", "status": "404"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			m := metrics.New()
+			e := echo.New()
+			e.Use(Metrics(m))
+			e.GET("/books/:id", func(c echo.Context) error {
+				if c.Param("id") == "unknown" {
+					return echo.ErrNotFound
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			rec := httptest.NewRecorder()
+
+			// Act
+			e.ServeHTTP(rec, req)
+
+			// Assert
+			families, err := m.Registry().Gather()
+			assert.NoError(t, err)
+			var labels map[string]string
+			for _, family := range families {
+				if family.GetName() != "bookstore_http_request_duration_seconds" {
+					continue
+				}
+				assert.Len(t, family.GetMetric(), 1)
+				labels = map[string]string{}
+				for _, label := range family.GetMetric()[0].GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				assert.Equal(t, uint64(1), family.GetMetric()[0].GetHistogram().GetSampleCount())
+			}
+			assert.Equal(t, testCase.expectLabels, labels)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"alterra-agmc-day-7/internal/tracing"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing records every request as a server span named after its route
+// template. The span continues the trace of an incoming traceparent header
+// and is returned to the client in the same header.
+func Tracing(t *tracing.Tracing) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := t.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := t.Start(ctx, req.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethodKey.String(req.Method),
+					semconv.HTTPTargetKey.String(req.URL.Path),
+				),
+			)
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+			t.Inject(ctx, propagation.HeaderCarrier(c.Response().Header()))
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			status, route := c.Response().Status, routeTemplate(c)
+			span.SetName(req.Method + " " + route)
+			span.SetAttributes(
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPStatusCodeKey.Int(status),
+			)
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
@@ -1,13 +1,54 @@
 package request
 
 type CreateBookRequest struct {
-	Title  string `json:"title" validate:"required"`
-	Isbn   string `json:"isbn" validate:"required"`
-	Writer string `json:"writer" validate:"required"`
+	Title      string   `json:"title" validate:"required"`
+	Isbn       string   `json:"isbn" validate:"required"`
+	Writer     string   `json:"writer" validate:"required"`
+	CategoryID uint     `json:"category_id,omitempty" validate:"omitempty"`
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
 }
 
 type UpdateBookRequest struct {
-	Title  string `json:"title,omitempty" validate:"omitempty"`
-	Isbn   string `json:"isbn,omitempty" validate:"omitempty"`
-	Writer string `json:"writer,omitempty" validate:"omitempty"`
+	Title      string   `json:"title,omitempty" validate:"omitempty"`
+	Isbn       string   `json:"isbn,omitempty" validate:"omitempty"`
+	Writer     string   `json:"writer,omitempty" validate:"omitempty"`
+	CategoryID uint     `json:"category_id,omitempty" validate:"omitempty"`
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
+}
+
+type ImportBookRequest struct {
+	Format       string `query:"format" validate:"omitempty,oneof=csv ndjson"`
+	DryRun       bool   `query:"dry_run"`
+	TitleColumn  string `query:"title_column"`
+	IsbnColumn   string `query:"isbn_column"`
+	WriterColumn string `query:"writer_column"`
+}
+
+type ListBookRequest struct {
+	UserID     uint     `query:"user_id"`
+	Title      string   `query:"title"`
+	Writer     string   `query:"writer"`
+	Isbn       string   `query:"isbn"`
+	CategoryID uint     `query:"category_id"`
+	Tags       []string `query:"tag"`
+}
+
+type ExportBookRequest struct {
+	ListBookRequest
+	Format string `query:"format" validate:"required,oneof=csv ndjson xlsx"`
+}
+
+type DiffBookRevisionRequest struct {
+	From uint `query:"from" validate:"required"`
+	To   uint `query:"to" validate:"required"`
+}
+
+// PatchBookRequest holds a book after a merge patch was applied to it, so
+// that it is validated as a whole.
+type PatchBookRequest struct {
+	Title      string   `json:"title" validate:"required"`
+	Isbn       string   `json:"isbn" validate:"required"`
+	Writer     string   `json:"writer" validate:"required"`
+	CategoryID uint     `json:"category_id,omitempty" validate:"omitempty"`
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
 }
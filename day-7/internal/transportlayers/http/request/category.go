@@ -0,0 +1,16 @@
+package request
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentID uint   `json:"parent_id,omitempty" validate:"omitempty"`
+}
+
+type UpdateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentID uint   `json:"parent_id,omitempty" validate:"omitempty"`
+}
+
+type ListCategoryBookRequest struct {
+	ListBookRequest
+	IncludeDescendants bool `query:"include_descendants"`
+}
@@ -16,3 +16,11 @@ type UpdateUserRequest struct {
 	Email    string `json:"email,omitempty" validate:"omitempty,email"`
 	Password string `json:"password,omitempty" validate:"omitempty,min=8"`
 }
+
+// PatchUserRequest holds a user after a merge patch was applied to it. The
+// password is only present when the patch sets it.
+type PatchUserRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password,omitempty" validate:"omitempty,min=8"`
+}
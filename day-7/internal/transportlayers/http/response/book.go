@@ -3,10 +3,29 @@ package response
 import "time"
 
 type BookResponse struct {
-	ID        uint      `json:"id"`
-	Title     string    `json:"title"`
-	Isbn      string    `json:"isbn"`
-	Writer    string    `json:"writer"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint       `json:"id"`
+	Title      string     `json:"title"`
+	Isbn       string     `json:"isbn"`
+	Writer     string     `json:"writer"`
+	CategoryID uint       `json:"category_id,omitempty"`
+	Tags       []string   `json:"tags"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	Version    uint       `json:"version"`
+}
+
+type BookImportResponse struct {
+	DryRun  bool                    `json:"dry_run"`
+	Created int                     `json:"created"`
+	Skipped int                     `json:"skipped"`
+	Failed  int                     `json:"failed"`
+	Rows    []BookImportRowResponse `json:"rows"`
+}
+
+type BookImportRowResponse struct {
+	Row    int           `json:"row"`
+	Status string        `json:"status"`
+	Reason string        `json:"reason,omitempty"`
+	Book   *BookResponse `json:"book,omitempty"`
 }
@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type BookRevisionResponse struct {
+	Revision  uint         `json:"revision"`
+	Action    string       `json:"action"`
+	ActorID   uint         `json:"actor_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Snapshot  BookResponse `json:"snapshot"`
+}
+
+type BookRevisionDiffResponse struct {
+	From    uint                      `json:"from"`
+	To      uint                      `json:"to"`
+	Changes []BookFieldChangeResponse `json:"changes"`
+}
+
+type BookFieldChangeResponse struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
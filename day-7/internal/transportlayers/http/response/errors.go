@@ -1,7 +1,15 @@
 package response
 
 type ErrorResponse struct {
-	Status  uint   `json:"status"`
-	Code    string `json:"code"`
+	Status  uint                 `json:"status"`
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Errors  []FieldErrorResponse `json:"errors,omitempty"`
+}
+
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
 	Message string `json:"message"`
 }
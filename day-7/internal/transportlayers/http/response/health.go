@@ -0,0 +1,11 @@
+package response
+
+type HealthResponse struct {
+	Status string                         `json:"status"`
+	Checks map[string]HealthCheckResponse `json:"checks,omitempty"`
+}
+
+type HealthCheckResponse struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+}
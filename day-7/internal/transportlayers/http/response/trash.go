@@ -0,0 +1,6 @@
+package response
+
+type TrashResponse struct {
+	Books []BookResponse `json:"books"`
+	Users []UserResponse `json:"users"`
+}
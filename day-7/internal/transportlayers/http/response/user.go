@@ -3,11 +3,13 @@ package response
 import "time"
 
 type UserResponse struct {
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	ID        uint      `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	ID        uint       `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Version   uint       `json:"version"`
 }
 
 type LoginResponse struct {
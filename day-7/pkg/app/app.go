@@ -1,7 +1,58 @@
 package app
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// App is a long running application. Run initializes it with OnInit, serves
+// until ctx is cancelled and then releases its resources with OnDestroy.
 type App interface {
-	Run() error
-	OnInit() error
-	OnDestroy()
+	Run(ctx context.Context) error
+	OnInit(ctx context.Context) error
+	OnDestroy(ctx context.Context) error
+}
+
+// ShutdownHooks collects the cleanup functions of an App. They run in the
+// reverse order of registration, so resources are released before the ones
+// they depend on.
+type ShutdownHooks struct {
+	mu    sync.Mutex
+	hooks []shutdownHook
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Add registers fn to run on shutdown under name, used in error messages.
+func (h *ShutdownHooks) Add(name string, fn func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run runs and forgets every registered hook, newest first. A failing hook
+// does not stop the others; their errors are returned together.
+func (h *ShutdownHooks) Run(ctx context.Context) error {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+
+	var failures []string
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			slog.ErrorContext(ctx, "shutdown hook failed", "hook", hooks[i].name, "error", err)
+			failures = append(failures, fmt.Sprintf("%s: %v", hooks[i].name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("shutdown failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
 }
@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"alterra-agmc-day-7/pkg/app"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failing     map[string]error
+		expectedErr string
+	}{
+		{
+			name: "Test run should call every hook newest first",
+		},
+		{
+			name:        "Test run when a hook fails should still call the others and return its error",
+			failing:     map[string]error{"mongo": errors.New("disconnect failed")},
+			expectedErr: "shutdown failed: mongo: disconnect failed",
+		},
+		{
+			name: "Test run when several hooks fail should return every error in the order they ran",
+			failing: map[string]error{
+				"mysql": errors.New("close failed"),
+				"http":  errors.New("context deadline exceeded"),
+			},
+			expectedErr: "shutdown failed: http: context deadline exceeded; mysql: close failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			var hooks app.ShutdownHooks
+			var calls []string
+			for _, name := range []string{"mysql", "mongo", "http"} {
+				name := name
+				hooks.Add(name, func(ctx context.Context) error {
+					calls = append(calls, name)
+					return tc.failing[name]
+				})
+			}
+
+			// Act
+			err := hooks.Run(context.TODO())
+
+			// Assert
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []string{"http", "mongo", "mysql"}, calls)
+		})
+	}
+}
+
+func TestShutdownHooksForgottenAfterRun(t *testing.T) {
+	// Setup
+	var hooks app.ShutdownHooks
+	calls := 0
+	hooks.Add("mysql", func(ctx context.Context) error {
+		calls++
+		return errors.New("close failed")
+	})
+
+	// Act
+	firstErr := hooks.Run(context.TODO())
+	secondErr := hooks.Run(context.TODO())
+
+	// Assert
+	assert.Error(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,126 @@
+// Package i18n holds translated message bundles and picks the one matching
+// the Accept-Language header of a request.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bundle maps message keys to the message in one locale. Messages may
+// contain fmt verbs filled in by Catalog.Translate.
+type Bundle map[string]string
+
+// Catalog is a set of bundles with a fallback locale used for keys missing
+// from the requested one.
+type Catalog struct {
+	fallback string
+	bundles  map[string]Bundle
+}
+
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: fallback,
+		bundles:  make(map[string]Bundle),
+	}
+}
+
+// Add registers the messages of locale, merging them with the ones already
+// registered.
+func (c *Catalog) Add(locale string, bundle Bundle) {
+	locale = normalize(locale)
+	if c.bundles[locale] == nil {
+		c.bundles[locale] = make(Bundle, len(bundle))
+	}
+	for key, message := range bundle {
+		c.bundles[locale][key] = message
+	}
+}
+
+// Fallback returns the locale used when no other locale matches.
+func (c *Catalog) Fallback() string {
+	return c.fallback
+}
+
+// Translate returns the message of key in locale, falling back to the
+// fallback locale and then to key itself.
+func (c *Catalog) Translate(locale string, key string, args ...interface{}) string {
+	message, ok := c.bundles[normalize(locale)][key]
+	if !ok {
+		message, ok = c.bundles[c.fallback][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Negotiate returns the registered locale preferred by acceptLanguage, a
+// value of the Accept-Language header. A region such as id-ID matches its
+// language when the region has no bundle of its own.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			return c.fallback
+		}
+		if _, ok := c.bundles[tag]; ok {
+			return tag
+		}
+		if i := strings.Index(tag, "-"); i > 0 {
+			if _, ok := c.bundles[tag[:i]]; ok {
+				return tag[:i]
+			}
+		}
+	}
+	return c.fallback
+}
+
+type weightedTag struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage returns the language tags of header ordered by their
+// quality, leaving out the ones with a quality of 0.
+func parseAcceptLanguage(header string) []string {
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := normalize(params[0])
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+		if quality > 0 {
+			weighted = append(weighted, weightedTag{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+	tags := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		tags = append(tags, w.tag)
+	}
+	return tags
+}
+
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
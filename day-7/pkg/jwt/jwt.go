@@ -1,7 +1,6 @@
 package jwt
 
 import (
-	"alterra-agmc-day-7/config"
 	"fmt"
 	"strconv"
 	"time"
@@ -9,13 +8,26 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-func NewToken(id uint) (string, error) {
+// Issuer signs the tokens of authenticated users.
+type Issuer struct {
+	secretKey      []byte
+	expirationTime time.Duration
+}
+
+func NewIssuer(secretKey string, expirationTime time.Duration) *Issuer {
+	return &Issuer{
+		secretKey:      []byte(secretKey),
+		expirationTime: expirationTime,
+	}
+}
+
+func (i *Issuer) NewToken(id uint) (string, error) {
 	claims := jwt.StandardClaims{
 		Subject:   strconv.Itoa(int(id)),
-		ExpiresAt: time.Now().Add(time.Millisecond * time.Duration(config.GetJWTExpirationTime())).Unix(),
+		ExpiresAt: time.Now().Add(i.expirationTime).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.GetJWTSecretKey()))
+	return token.SignedString(i.secretKey)
 }
 
 func ExtractID(token *jwt.Token) (uint, error) {
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedaction(t *testing.T) {
+	type credentials struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+	}
+	testCases := []struct {
+		name     string
+		log      func(l *slog.Logger)
+		key      string
+		expected interface{}
+	}{
+		{
+			name:     "Test password attribute should be redacted",
+			log:      func(l *slog.Logger) { l.Info("login", "password", "secret123") },
+			key:      "password",
+			expected: redacted,
+		},
+		{
+			name:     "Test token attribute should be redacted whatever its casing",
+			log:      func(l *slog.Logger) { l.Info("login", "Access_Token", "abc") },
+			key:      "Access_Token",
+			expected: redacted,
+		},
+		{
+			name:     "Test email attribute should be redacted",
+			log:      func(l *slog.Logger) { l.Info("login failed", "email", "User@Mail.com") },
+			key:      "email",
+			expected: redacted,
+		},
+		{
+			name:     "Test email inside the message should be redacted",
+			log:      func(l *slog.Logger) { l.Info("user user@mail.com logged in") },
+			key:      slog.MessageKey,
+			expected: "user [REDACTED] logged in",
+		},
+		{
+			name:     "Test bearer token inside an error should be redacted",
+			log:      func(l *slog.Logger) { l.Error("failed", "error", errors.New("header Bearer abc.def.ghi rejected")) },
+			key:      "error",
+			expected: "header [REDACTED] rejected",
+		},
+		{
+			name: "Test fields of a structured value should be redacted",
+			log: func(l *slog.Logger) {
+				l.Info("created", "user", credentials{Email: "user@mail.com", Password: "secret", Name: "user"})
+			},
+			key:      "user",
+			expected: map[string]interface{}{"email": redacted, "password": redacted, "name": "user"},
+		},
+		{
+			name:     "Test other attributes should be kept",
+			log:      func(l *slog.Logger) { l.Info("created", "user_id", 1) },
+			key:      "user_id",
+			expected: float64(1),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			l := New(&buf, slog.LevelInfo)
+
+			// Act
+			testCase.log(l)
+
+			// Assert
+			var record map[string]interface{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, testCase.expected, record[testCase.key])
+			assert.NotContains(t, buf.String(), "secret")
+			assert.NotContains(t, buf.String(), "mail.com")
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	l := New(&buf, slog.LevelInfo).With("component", "test")
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	// Act
+	l.InfoContext(ctx, "handled")
+
+	// Assert
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-1", record[KeyRequestID])
+	assert.Equal(t, "test", record["component"])
+}
@@ -0,0 +1,70 @@
+// Package mergepatch implements JSON Merge Patch as described in RFC 7396.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// ContentType is the media type of a merge patch document.
+const ContentType = "application/merge-patch+json"
+
+var ErrNotObject = errors.New("merge patch should be a json object")
+
+// Apply applies patch to the JSON document target and returns the patched
+// document. Members set to null in patch are removed from the result.
+func Apply(target []byte, patch []byte) ([]byte, error) {
+	var targetValue interface{}
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := decode(target, &targetValue); err != nil {
+			return nil, err
+		}
+	}
+	var patchValue interface{}
+	if err := decode(patch, &patchValue); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(targetValue, patchValue))
+}
+
+// Fields returns the names of the top level members of patch, including the
+// ones set to null. patch has to be a JSON object.
+func Fields(patch []byte) ([]string, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return nil, ErrNotObject
+	}
+	fields := make([]string, 0, len(members))
+	for name := range members {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func merge(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = merge(targetObject[name], value)
+	}
+	return targetObject
+}
+
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
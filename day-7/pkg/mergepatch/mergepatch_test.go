@@ -0,0 +1,99 @@
+package mergepatch_test
+
+import (
+	"alterra-agmc-day-7/pkg/mergepatch"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	// The examples of RFC 7396, appendix A.
+	testCases := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, expected: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, expected: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expected: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, expected: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expected: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, expected: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, expected: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, expected: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, expected: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, expected: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, expected: `{"e":null,"a":1}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, expected: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, expected: `{"a":{"bb":{}}}`},
+		// Numbers keep their precision.
+		{target: `{"id":9007199254740993}`, patch: `{"a":1}`, expected: `{"id":9007199254740993,"a":1}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target+" "+tc.patch, func(t *testing.T) {
+			// Act
+			result, err := mergepatch.Apply([]byte(tc.target), []byte(tc.patch))
+
+			// Assert
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestApplyMalformed(t *testing.T) {
+	// Act
+	_, targetErr := mergepatch.Apply([]byte(`{"a":`), []byte(`{}`))
+	_, patchErr := mergepatch.Apply([]byte(`{}`), []byte(`{"a"`))
+
+	// Assert
+	assert.Error(t, targetErr)
+	assert.Error(t, patchErr)
+}
+
+func TestFields(t *testing.T) {
+	testCases := []struct {
+		name        string
+		patch       string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "Test fields should list every top level member sorted, null ones included",
+			patch:    `{"title":"Title","tags":null,"category":{"id":1}}`,
+			expected: []string{"category", "tags", "title"},
+		},
+		{
+			name:     "Test fields of an empty patch should be empty",
+			patch:    `{}`,
+			expected: []string{},
+		},
+		{
+			name:        "Test fields of an array should return err not object",
+			patch:       `["title"]`,
+			expectedErr: mergepatch.ErrNotObject,
+		},
+		{
+			name:        "Test fields of null should return err not object",
+			patch:       `null`,
+			expectedErr: mergepatch.ErrNotObject,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			fields, err := mergepatch.Fields([]byte(tc.patch))
+
+			// Assert
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.expected, fields)
+			}
+		})
+	}
+}
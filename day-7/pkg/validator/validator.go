@@ -1,28 +1,131 @@
 package validator
 
 import (
-	"net/http"
+	"errors"
+	"reflect"
+	"strings"
 
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
-	"github.com/labstack/echo/v4"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
 )
 
+// FieldError describes a single input that failed validation. Field is the
+// name the client sent, e.g. "tags[1]", not the name of the Go struct field.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+
+	source validator.FieldError
+}
+
+// ValidationError is returned by CustomValidator.Validate with every field
+// that failed validation.
+type ValidationError struct {
+	Fields []FieldError
+
+	translators *ut.UniversalTranslator
+}
+
+// Localize returns the fields with their messages translated to locale.
+// Locales without translations keep the English messages.
+func (e ValidationError) Localize(locale string) []FieldError {
+	if e.translators == nil {
+		return e.Fields
+	}
+	translator, found := e.translators.GetTranslator(locale)
+	if !found {
+		return e.Fields
+	}
+	fields := make([]FieldError, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		field.Message = field.source.Translate(translator)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
 type CustomValidator struct {
-	Validator *validator.Validate
+	Validator   *validator.Validate
+	translators *ut.UniversalTranslator
 }
 
 func NewCustomValidator() *CustomValidator {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+
+	translators := ut.New(en.New(), en.New(), id.New())
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"id": idTranslations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registrations {
+		translator, _ := translators.GetTranslator(locale)
+		if err := register(v, translator); err != nil {
+			panic(err)
+		}
+	}
 	return &CustomValidator{
-		Validator: validator.New(),
+		Validator:   v,
+		translators: translators,
 	}
 }
 
 func (cv *CustomValidator) Validate(i interface{}) error {
-	if err := cv.Validator.Struct(i); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
-			"status":  "BAD_REQUEST",
-			"message": err.Error(),
+	err := cv.Validator.Struct(i)
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	translator, _ := cv.translators.GetTranslator("en")
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(translator),
+			source:  fe,
 		})
 	}
-	return nil
+	return ValidationError{Fields: fields, translators: cv.translators}
+}
+
+// fieldName names struct fields after their json tag, falling back to the
+// query tag used by query string requests.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath drops the name of the validated struct from namespace.
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
 }